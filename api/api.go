@@ -3,8 +3,7 @@ package api
 import (
 	"context"
 	"log"
-	"net/http"
-	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -12,15 +11,22 @@ import (
 	"bilibili/internal/handlers"
 	"bilibili/internal/handlers/middleware"
 	svc "bilibili/internal/services"
+	"bilibili/pkg/bilibili"
 	"bilibili/pkg/storage"
 	"bilibili/pkg/utils"
 )
 
 // Services 所有服务实例
 type Services struct {
-	CommentService  *svc.CommentService
-	ExportService   *svc.ExportService
-	AnalysisService *svc.AnalysisService
+	CommentService   *svc.CommentService
+	ExportService    *svc.ExportService
+	AnalysisService  *svc.AnalysisService
+	ScheduleService  *svc.ScheduleService
+	BatchService     *svc.BatchService
+	DanmakuService   *svc.DanmakuService
+	VideoStatService *svc.VideoStatService
+	LiveService      *svc.LiveService
+	AccountService   *svc.AccountService
 }
 
 // SetupRoutes 设置路由
@@ -43,9 +49,50 @@ func SetupRoutes(ctx context.Context) (*gin.Engine, *Services) {
 	// 初始化存储层
 	taskStorage := storage.NewJSONStorage(cfg.Storage.DataDir)
 
+	// 设置认证信息加密密钥，并清理旧版本明文保存在任务文件中的Cookie（只执行一次）
+	// 密钥只能来自配置文件或环境变量，未设置时拒绝保存认证信息，而不是把密钥写在数据目录旁边
+	if err := taskStorage.SetCredentialKey(cfg.Storage.CredentialKey); err != nil {
+		log.Printf("警告: ==================================================")
+		log.Printf("警告: 未配置认证信息加密密钥（storage.credential_key 或环境变量 %s）", config.CredentialKeyEnv)
+		log.Printf("警告: Cookie/APP认证和登录账号将不可用，旧版本明文保存的认证信息也不会被迁移清理")
+		log.Printf("警告: ==================================================")
+	} else if scrubbed, err := taskStorage.MigrateCredentials(); err != nil {
+		log.Printf("警告: 迁移明文认证信息失败: %v，下次启动时重试", err)
+	} else if scrubbed > 0 {
+		log.Printf("已将 %d 个文件中的明文认证信息迁移到加密存储", scrubbed)
+	}
+
+	// 初始化共享的 Bilibili 客户端（所有任务共用同一个限流器）
+	biliClient := bilibili.NewBilibiliClient(
+		bilibili.WithBaseURL(cfg.Bilibili.BaseURL),
+		bilibili.WithLiveBaseURL(cfg.Bilibili.LiveBaseURL),
+		bilibili.WithPassportBaseURL(cfg.Bilibili.PassportBaseURL),
+		bilibili.WithTimeout(time.Duration(cfg.Bilibili.Timeout)*time.Second),
+		bilibili.WithRateLimiter(bilibili.NewRateLimiter(cfg.Bilibili.RequestsPerSecond, cfg.Bilibili.Burst)),
+		bilibili.WithRetryPolicy(bilibili.RetryPolicy{
+			MaxRetries: cfg.Bilibili.MaxRetries,
+			BaseDelay:  time.Duration(cfg.Bilibili.RetryBaseDelayMs) * time.Millisecond,
+			MaxDelay:   time.Duration(cfg.Bilibili.RetryMaxDelayMs) * time.Millisecond,
+		}),
+	)
+
 	// 初始化服务（传递 context）
-	commentService := svc.NewCommentService(ctx, taskStorage)
-	videoService := svc.NewVideoService()
+	accountService := svc.NewAccountService(ctx, taskStorage, biliClient)
+	videoStatService := svc.NewVideoStatService(ctx, taskStorage, biliClient)
+	commentService := svc.NewCommentService(ctx, taskStorage, biliClient, cfg.Scheduler.MaxConcurrentTasks, cfg.Scheduler.MaxQueuedTasks)
+	commentService.SetAccountService(accountService)
+	commentService.SetVideoStatService(videoStatService)
+	// 依赖设置完成后再恢复任务；定时计划和批量任务加载时会引用这些任务，需在其之前启动
+	commentService.Start()
+	videoService := svc.NewVideoService(biliClient)
+	userService := svc.NewUserService(biliClient)
+	commenterService := svc.NewCommenterService(commentService)
+	scheduleService := svc.NewScheduleService(ctx, taskStorage, commentService)
+	batchService := svc.NewBatchService(ctx, taskStorage, commentService, videoService)
+	danmakuService := svc.NewDanmakuService(ctx, taskStorage, biliClient, cfg.Scheduler.MaxConcurrentTasks)
+	liveService := svc.NewLiveService(ctx, taskStorage, biliClient)
+	liveService.SetAccountService(accountService)
+	liveService.Start()
 	exportService := svc.NewExportService(ctx, "./exports")
 	analysisService := svc.NewAnalysisService(
 		cfg.AI.APIURL,
@@ -54,16 +101,30 @@ func SetupRoutes(ctx context.Context) (*gin.Engine, *Services) {
 	)
 
 	services := &Services{
-		CommentService:  commentService,
-		ExportService:   exportService,
-		AnalysisService: analysisService,
+		CommentService:   commentService,
+		ExportService:    exportService,
+		AnalysisService:  analysisService,
+		ScheduleService:  scheduleService,
+		BatchService:     batchService,
+		DanmakuService:   danmakuService,
+		VideoStatService: videoStatService,
+		LiveService:      liveService,
+		AccountService:   accountService,
 	}
 
 	// 初始化处理器
-	commentHandlers := handlers.NewCommentHandlers(commentService, exportService)
+	commentHandlers := handlers.NewCommentHandlers(commentService, videoService, exportService)
 	videoHandlers := handlers.NewVideoHandlers(videoService)
 	analysisHandlers := handlers.NewAnalysisHandlers(commentService, analysisService)
 	v2Handlers := handlers.NewV2Handlers(commentService, analysisService)
+	scheduleHandlers := handlers.NewScheduleHandlers(scheduleService, commentService, videoService)
+	batchHandlers := handlers.NewBatchHandlers(batchService, commentService, exportService, analysisService)
+	danmakuHandlers := handlers.NewDanmakuHandlers(danmakuService, videoService, exportService, analysisService)
+	userHandlers := handlers.NewUserHandlers(userService)
+	commenterHandlers := handlers.NewCommenterHandlers(commenterService)
+	videoStatHandlers := handlers.NewVideoStatHandlers(videoStatService, videoService)
+	liveHandlers := handlers.NewLiveHandlers(liveService)
+	accountHandlers := handlers.NewAccountHandlers(accountService)
 	healthHandler := handlers.NewHealthHandler()
 
 	// 静态文件服务
@@ -80,31 +141,8 @@ func SetupRoutes(ctx context.Context) (*gin.Engine, *Services) {
 		handlers.GinHelloHandler(c)
 	})
 
-	r.GET("/user/:id", func(c *gin.Context) {
-		// 获取URL中的用户ID
-		userIDStr := c.Param("id")
-		userID, err := strconv.Atoi(userIDStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
-			return
-		}
-
-		// 获取用户信息
-		user, err := svc.GetUserByID(userID)
-		if err != nil {
-			utils.LogError("Failed to get user: " + err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
-			return
-		}
-
-		if user == nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-			return
-		}
-
-		// 返回JSON格式的用户信息
-		c.JSON(http.StatusOK, user)
-	})
+	// 用户信息（兼容旧路径，与 /api/v2/users/:mid 相同）
+	r.GET("/user/:mid", userHandlers.GetUserHandler)
 
 	// API路由组
 	apiGroup := r.Group("/api")
@@ -138,6 +176,70 @@ func SetupRoutes(ctx context.Context) (*gin.Engine, *Services) {
 		// 任务相关
 		v2Group.GET("/tasks", v2Handlers.GetTasksHandler)
 		v2Group.GET("/tasks/:id", v2Handlers.GetTaskHandler)
+		v2Group.POST("/tasks/:id/cancel", v2Handlers.CancelTaskHandler)
+		v2Group.POST("/tasks/:id/pause", v2Handlers.PauseTaskHandler)
+		v2Group.POST("/tasks/:id/resume", v2Handlers.ResumeTaskHandler)
+		v2Group.POST("/tasks/:id/refresh", v2Handlers.RefreshTaskHandler)
+
+		// 批量任务相关
+		v2Group.GET("/batches", batchHandlers.ListBatchesHandler)
+		v2Group.POST("/batches", batchHandlers.StartBatchHandler)
+		v2Group.GET("/batches/:id", batchHandlers.GetBatchHandler)
+		v2Group.DELETE("/batches/:id", batchHandlers.DeleteBatchHandler)
+		v2Group.POST("/batches/:id/cancel", batchHandlers.CancelBatchHandler)
+		v2Group.POST("/batches/:id/export", batchHandlers.ExportBatchHandler)
+		v2Group.POST("/batches/:id/analyze-stream", batchHandlers.AnalyzeBatchStreamHandler)
+
+		// 定时监控相关
+		v2Group.GET("/schedules", scheduleHandlers.ListSchedulesHandler)
+		v2Group.POST("/schedules", scheduleHandlers.CreateScheduleHandler)
+		v2Group.GET("/schedules/:id", scheduleHandlers.GetScheduleHandler)
+		v2Group.PUT("/schedules/:id", scheduleHandlers.UpdateScheduleHandler)
+		v2Group.DELETE("/schedules/:id", scheduleHandlers.DeleteScheduleHandler)
+		v2Group.GET("/schedules/:id/runs", scheduleHandlers.GetScheduleRunsHandler)
+		v2Group.POST("/schedules/:id/run", scheduleHandlers.RunScheduleHandler)
+
+		// 弹幕相关
+		v2Group.GET("/danmaku", danmakuHandlers.ListDanmakuTasksHandler)
+		v2Group.POST("/danmaku", danmakuHandlers.StartDanmakuHandler)
+		v2Group.GET("/danmaku/:id", danmakuHandlers.GetDanmakuTaskHandler)
+		v2Group.GET("/danmaku/:id/result", danmakuHandlers.GetDanmakuResultHandler)
+		v2Group.GET("/danmaku/:id/stats", danmakuHandlers.GetDanmakuStatsHandler)
+		v2Group.POST("/danmaku/:id/cancel", danmakuHandlers.CancelDanmakuTaskHandler)
+		v2Group.POST("/danmaku/:id/export", danmakuHandlers.ExportDanmakuHandler)
+		v2Group.POST("/danmaku/:id/analyze-stream", danmakuHandlers.AnalyzeDanmakuStreamHandler)
+
+		// 用户相关
+		v2Group.GET("/users/:mid", userHandlers.GetUserHandler)
+
+		// 评论者索引（汇总所有已抓取任务）
+		v2Group.GET("/commenters", commenterHandlers.ListCommentersHandler)
+		v2Group.GET("/commenters/:mid", commenterHandlers.GetCommenterHandler)
+		v2Group.GET("/commenters/:mid/comments", commenterHandlers.GetCommenterCommentsHandler)
+
+		// 视频数据快照（播放、点赞、评论数等的变化）
+		v2Group.GET("/video-stats", videoStatHandlers.ListVideoStatsHandler)
+		v2Group.GET("/video-stats/:video_id", videoStatHandlers.GetVideoStatsHandler)
+		v2Group.POST("/video-stats/:video_id/snapshot", videoStatHandlers.SnapshotVideoStatsHandler)
+		v2Group.PUT("/video-stats/:video_id/watch", videoStatHandlers.WatchVideoStatsHandler)
+		v2Group.DELETE("/video-stats/:video_id/watch", videoStatHandlers.UnwatchVideoStatsHandler)
+
+		// 直播弹幕抓取
+		v2Group.GET("/live", liveHandlers.ListLiveTasksHandler)
+		v2Group.POST("/live", liveHandlers.StartLiveCaptureHandler)
+		v2Group.GET("/live/:id", liveHandlers.GetLiveTaskHandler)
+		v2Group.POST("/live/:id/stop", liveHandlers.StopLiveCaptureHandler)
+		v2Group.GET("/live/:id/events", liveHandlers.GetLiveEventsHandler)
+
+		// 登录账号（扫码登录或导入Cookie，任务通过 account_id 引用）
+		v2Group.POST("/login/qrcode", accountHandlers.StartQRLoginHandler)
+		v2Group.GET("/login/qrcode/:key", accountHandlers.PollQRLoginHandler)
+		v2Group.GET("/accounts", accountHandlers.ListAccountsHandler)
+		v2Group.POST("/accounts", accountHandlers.ImportAccountHandler)
+		v2Group.GET("/accounts/:id", accountHandlers.GetAccountHandler)
+		v2Group.PUT("/accounts/:id", accountHandlers.RenameAccountHandler)
+		v2Group.DELETE("/accounts/:id", accountHandlers.DeleteAccountHandler)
+		v2Group.POST("/accounts/:id/check", accountHandlers.CheckAccountHandler)
 
 		// 模板相关
 		v2Group.GET("/templates", v2Handlers.GetTemplatesHandler)
@@ -154,15 +256,45 @@ func SetupRoutes(ctx context.Context) (*gin.Engine, *Services) {
 func ShutdownServices(ctx context.Context, services *Services) {
 	// 按照依赖顺序关闭服务
 
-	// 1. 关闭 ExportService
+	// 1. 关闭 ScheduleService（停止创建新任务）
+	if err := services.ScheduleService.Shutdown(ctx); err != nil {
+		utils.LogError("Failed to shutdown ScheduleService: " + err.Error())
+	}
+
+	// 2. 关闭 BatchService（保存批量任务进度）
+	if err := services.BatchService.Shutdown(ctx); err != nil {
+		utils.LogError("Failed to shutdown BatchService: " + err.Error())
+	}
+
+	// 3. 关闭 DanmakuService
+	if err := services.DanmakuService.Shutdown(ctx); err != nil {
+		utils.LogError("Failed to shutdown DanmakuService: " + err.Error())
+	}
+
+	// 4. 关闭 VideoStatService（停止定时记录）
+	if err := services.VideoStatService.Shutdown(ctx); err != nil {
+		utils.LogError("Failed to shutdown VideoStatService: " + err.Error())
+	}
+
+	// 5. 关闭 LiveService（写入已抓取的事件，运行中的任务重启后继续）
+	if err := services.LiveService.Shutdown(ctx); err != nil {
+		utils.LogError("Failed to shutdown LiveService: " + err.Error())
+	}
+
+	// 6. 关闭 ExportService
 	if err := services.ExportService.Shutdown(ctx); err != nil {
 		utils.LogError("Failed to shutdown ExportService: " + err.Error())
 	}
 
-	// 2. 关闭 CommentService
+	// 7. 关闭 CommentService
 	if err := services.CommentService.Shutdown(ctx); err != nil {
 		utils.LogError("Failed to shutdown CommentService: " + err.Error())
 	}
 
+	// 8. 关闭 AccountService（任务结束后再停止，运行中的任务仍可读取账号）
+	if err := services.AccountService.Shutdown(ctx); err != nil {
+		utils.LogError("Failed to shutdown AccountService: " + err.Error())
+	}
+
 	utils.LogInfo("All services shutdown complete")
 }
@@ -8,9 +8,11 @@ import (
 
 // Config 应用配置
 type Config struct {
-	Server  ServerConfig  `json:"server"`
-	AI      AIConfig      `json:"ai"`
-	Storage StorageConfig `json:"storage"`
+	Server    ServerConfig    `json:"server"`
+	AI        AIConfig        `json:"ai"`
+	Storage   StorageConfig   `json:"storage"`
+	Bilibili  BilibiliConfig  `json:"bilibili"`
+	Scheduler SchedulerConfig `json:"scheduler"`
 }
 
 // ServerConfig 服务器配置
@@ -28,9 +30,32 @@ type AIConfig struct {
 
 // StorageConfig 存储配置
 type StorageConfig struct {
-	DataDir      string `json:"data_dir"`      // 数据目录
-	AutoSave     bool   `json:"auto_save"`     // 自动保存
-	SaveInterval int    `json:"save_interval"` // 保存间隔（秒）
+	DataDir       string `json:"data_dir"`       // 数据目录
+	AutoSave      bool   `json:"auto_save"`      // 自动保存
+	SaveInterval  int    `json:"save_interval"`  // 保存间隔（秒）
+	CredentialKey string `json:"credential_key"` // Cookie 等认证信息的加密密钥，为空时不能使用Cookie认证和登录账号
+}
+
+// CredentialKeyEnv 认证信息加密密钥的环境变量，设置后覆盖配置文件
+const CredentialKeyEnv = "BILIBILI_CREDENTIAL_KEY"
+
+// BilibiliConfig Bilibili API 客户端配置
+type BilibiliConfig struct {
+	BaseURL           string  `json:"base_url"`            // API 基础地址
+	LiveBaseURL       string  `json:"live_base_url"`       // 直播 API 基础地址
+	PassportBaseURL   string  `json:"passport_base_url"`   // 登录 API 基础地址
+	Timeout           int     `json:"timeout"`             // 请求超时（秒）
+	RequestsPerSecond float64 `json:"requests_per_second"` // 全局限流：每秒请求数
+	Burst             int     `json:"burst"`               // 全局限流：突发请求数
+	MaxRetries        int     `json:"max_retries"`         // 最大重试次数
+	RetryBaseDelayMs  int     `json:"retry_base_delay_ms"` // 重试基础延迟（毫秒）
+	RetryMaxDelayMs   int     `json:"retry_max_delay_ms"`  // 重试最大延迟（毫秒）
+}
+
+// SchedulerConfig 任务调度配置
+type SchedulerConfig struct {
+	MaxConcurrentTasks int `json:"max_concurrent_tasks"` // 同时运行的最大爬取任务数
+	MaxQueuedTasks     int `json:"max_queued_tasks"`     // 等待队列最大长度，0 表示不限制
 }
 
 // Load 从文件加载配置
@@ -51,6 +76,21 @@ func Load(path string) (*Config, error) {
 			AutoSave:     true,
 			SaveInterval: 30,
 		},
+		Bilibili: BilibiliConfig{
+			BaseURL:           "https://api.bilibili.com",
+			LiveBaseURL:       "https://api.live.bilibili.com",
+			PassportBaseURL:   "https://passport.bilibili.com",
+			Timeout:           10,
+			RequestsPerSecond: 4,
+			Burst:             4,
+			MaxRetries:        3,
+			RetryBaseDelayMs:  500,
+			RetryMaxDelayMs:   10000,
+		},
+		Scheduler: SchedulerConfig{
+			MaxConcurrentTasks: 2,
+			MaxQueuedTasks:     100,
+		},
 	}
 
 	// 尝试读取配置文件
@@ -58,6 +98,7 @@ func Load(path string) (*Config, error) {
 	if err != nil {
 		if os.IsNotExist(err) {
 			// 配置文件不存在，返回默认配置
+			applyEnv(cfg)
 			return cfg, nil
 		}
 		return nil, fmt.Errorf("读取配置文件失败: %w", err)
@@ -68,9 +109,17 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("解析配置文件失败: %w", err)
 	}
 
+	applyEnv(cfg)
 	return cfg, nil
 }
 
+// applyEnv 使用环境变量覆盖配置（密钥等不适合写入配置文件的项）
+func applyEnv(cfg *Config) {
+	if key := os.Getenv(CredentialKeyEnv); key != "" {
+		cfg.Storage.CredentialKey = key
+	}
+}
+
 // LoadDefault 加载默认配置文件
 func LoadDefault() (*Config, error) {
 	return Load("./configs/config.json")
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"bilibili/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AccountHandlers 登录账号处理器集合
+type AccountHandlers struct {
+	accountService *services.AccountService
+}
+
+// NewAccountHandlers 创建登录账号处理器
+func NewAccountHandlers(accountService *services.AccountService) *AccountHandlers {
+	return &AccountHandlers{accountService: accountService}
+}
+
+// StartQRLoginHandler 申请扫码登录二维码
+// POST /api/v2/login/qrcode
+// Body: {"name": "主账号"}（可选，默认使用B站用户名）
+// Response: 200 {"qrcode_key": "...", "url": "二维码内容", "status": "pending", "expires_at": "..."}
+func (h *AccountHandlers) StartQRLoginHandler(c *gin.Context) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	// 请求体可以为空
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+			return
+		}
+	}
+
+	session, err := h.accountService.StartQRLogin(c.Request.Context(), req.Name)
+	if err != nil {
+		h.respondAccountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.formatSession(session))
+}
+
+// PollQRLoginHandler 查询扫码登录状态，前端每隔1~2秒轮询一次
+// GET /api/v2/login/qrcode/:key
+// Response: 200 {"qrcode_key": "...", "status": "pending|scanned|confirmed|expired", "account": {账号对象，仅 confirmed 时}}
+func (h *AccountHandlers) PollQRLoginHandler(c *gin.Context) {
+	session, err := h.accountService.PollQRLogin(c.Request.Context(), c.Param("key"))
+	if err != nil {
+		h.respondAccountError(c, err)
+		return
+	}
+
+	result := h.formatSession(session)
+	if session.AccountID != "" {
+		if account, err := h.accountService.GetAccount(session.AccountID); err == nil {
+			result["account"] = h.formatAccount(account)
+		}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListAccountsHandler 获取所有已保存的账号（不返回Cookie）
+// GET /api/v2/accounts
+// Response: 200 [{账号对象}, ...]
+func (h *AccountHandlers) ListAccountsHandler(c *gin.Context) {
+	accounts := h.accountService.ListAccounts()
+
+	result := make([]gin.H, 0, len(accounts))
+	for _, account := range accounts {
+		result = append(result, h.formatAccount(account))
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ImportAccountHandler 通过浏览器Cookie添加账号
+// POST /api/v2/accounts
+// Body: {"name": "小号", "cookie": "SESSDATA=...; bili_jct=...; buvid3=..."}
+// Response: 200 {账号对象}
+func (h *AccountHandlers) ImportAccountHandler(c *gin.Context) {
+	var req struct {
+		Name   string `json:"name"`
+		Cookie string `json:"cookie" binding:"required"` // 完整Cookie字符串或单独的SESSDATA值
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	account, err := h.accountService.ImportAccount(c.Request.Context(), req.Name, req.Cookie)
+	if err != nil {
+		h.respondAccountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.formatAccount(account))
+}
+
+// GetAccountHandler 获取账号详情
+// GET /api/v2/accounts/:id
+// Response: 200 {账号对象}
+func (h *AccountHandlers) GetAccountHandler(c *gin.Context) {
+	account, err := h.accountService.GetAccount(c.Param("id"))
+	if err != nil {
+		h.respondAccountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.formatAccount(account))
+}
+
+// RenameAccountHandler 修改账号名称
+// PUT /api/v2/accounts/:id
+// Body: {"name": "新名称"}
+// Response: 200 {账号对象}
+func (h *AccountHandlers) RenameAccountHandler(c *gin.Context) {
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	account, err := h.accountService.RenameAccount(c.Param("id"), req.Name)
+	if err != nil {
+		h.respondAccountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.formatAccount(account))
+}
+
+// DeleteAccountHandler 删除账号
+// DELETE /api/v2/accounts/:id
+// Response: 200 {"message": "..."}
+func (h *AccountHandlers) DeleteAccountHandler(c *gin.Context) {
+	if err := h.accountService.DeleteAccount(c.Param("id")); err != nil {
+		h.respondAccountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "账号已删除"})
+}
+
+// CheckAccountHandler 立即检查账号登录状态
+// POST /api/v2/accounts/:id/check
+// Response: 200 {账号对象}（status 为 valid 或 expired）
+func (h *AccountHandlers) CheckAccountHandler(c *gin.Context) {
+	account, err := h.accountService.CheckAccount(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondAccountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.formatAccount(account))
+}
+
+// respondAccountError 账号操作失败时的响应
+func (h *AccountHandlers) respondAccountError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrAccountNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "账号不存在"})
+	case errors.Is(err, services.ErrLoginSessionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "登录二维码不存在或已过期，请重新获取"})
+	case errors.Is(err, services.ErrInvalidAccount):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+	case errors.Is(err, services.ErrCredentialKeyNotSet):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "服务器未配置认证信息加密密钥（credential_key），无法保存登录账号"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// formatSession 转换扫码登录会话
+func (h *AccountHandlers) formatSession(session *services.LoginSession) gin.H {
+	return gin.H{
+		"qrcode_key": session.QRCodeKey,
+		"url":        session.URL,
+		"name":       session.Name,
+		"status":     session.Status,
+		"account_id": session.AccountID,
+		"created_at": session.CreatedAt.Format("2006-01-02 15:04:05"),
+		"expires_at": session.ExpiresAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// formatAccount 转换为前端友好的格式（不含Cookie）
+func (h *AccountHandlers) formatAccount(account *services.Account) gin.H {
+	return gin.H{
+		"id":           account.ID,
+		"name":         account.Name,
+		"mid":          account.Mid,
+		"uname":        account.Uname,
+		"face":         account.Face,
+		"status":       account.Status,
+		"last_checked": formatOptionalTime(account.LastChecked, "2006-01-02 15:04:05"),
+		"expires_at":   formatOptionalTime(account.ExpiresAt, "2006-01-02 15:04:05"),
+		"created_at":   account.CreatedAt.Format("2006-01-02 15:04:05"),
+		"updated_at":   account.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -57,7 +57,7 @@ func (h *AnalysisHandlers) AnalyzeHandler(c *gin.Context) {
 		return
 	}
 
-	if task.Status != "completed" {
+	if !task.HasResult() {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Task is not completed yet"})
 		return
 	}
@@ -87,6 +87,7 @@ func (h *AnalysisHandlers) AnalyzeHandler(c *gin.Context) {
 		Comments:     task.Comments,
 		Template:     template,
 		CommentLimit: req.CommentLimit,
+		VideoContext: task.VideoContext,
 	}
 
 	// 执行分析
@@ -217,7 +218,7 @@ func (h *AnalysisHandlers) PreviewPromptHandler(c *gin.Context) {
 	}
 
 	commentsText := h.formatCommentsForPreview(sampleComments)
-	prompt := h.renderPrompt(*template, commentsText, task.VideoTitle, len(task.Comments))
+	prompt := h.analysisService.RenderTemplate(template.Prompt, commentsText, task.VideoTitle, len(task.Comments), task.VideoContext)
 
 	c.JSON(http.StatusOK, gin.H{
 		"prompt":        prompt,
@@ -234,15 +235,6 @@ func (h *AnalysisHandlers) formatCommentsForPreview(comments []bilibili.CommentD
 	return builder.String()
 }
 
-// renderPrompt 渲染Prompt
-func (h *AnalysisHandlers) renderPrompt(template services.PromptTemplate, commentsText, videoTitle string, commentCount int) string {
-	result := template.Prompt
-	result = strings.ReplaceAll(result, "{{comments}}", commentsText)
-	result = strings.ReplaceAll(result, "{{video_title}}", videoTitle)
-	result = strings.ReplaceAll(result, "{{comment_count}}", fmt.Sprintf("%d", commentCount))
-	return result
-}
-
 // AnalyzeStreamHandler 执行流式评论分析（SSE）
 func (h *AnalysisHandlers) AnalyzeStreamHandler(c *gin.Context) {
 	var req AnalyzeRequest
@@ -258,7 +250,7 @@ func (h *AnalysisHandlers) AnalyzeStreamHandler(c *gin.Context) {
 		return
 	}
 
-	if task.Status != "completed" {
+	if !task.HasResult() {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Task is not completed yet"})
 		return
 	}
@@ -311,7 +303,7 @@ func (h *AnalysisHandlers) AnalyzeStreamHandler(c *gin.Context) {
 		}
 
 		// 渲染 Prompt
-		prompt := h.analysisService.RenderTemplate(promptTemplate, commentsText, task.VideoTitle, len(task.Comments))
+		prompt := h.analysisService.RenderTemplate(promptTemplate, commentsText, task.VideoTitle, len(task.Comments), task.VideoContext)
 
 		// 调用流式 LLM，传递 context
 		_, err := h.analysisService.CallLLMStream(c.Request.Context(), func(chunk string) {
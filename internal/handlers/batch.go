@@ -0,0 +1,436 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"bilibili/internal/services"
+	"bilibili/pkg/bilibili"
+	"github.com/gin-gonic/gin"
+)
+
+// BatchHandlers 批量任务处理器集合
+type BatchHandlers struct {
+	batchService    *services.BatchService
+	commentService  *services.CommentService
+	exportService   *services.ExportService
+	analysisService *services.AnalysisService
+}
+
+// NewBatchHandlers 创建批量任务处理器
+func NewBatchHandlers(batchService *services.BatchService, commentService *services.CommentService, exportService *services.ExportService, analysisService *services.AnalysisService) *BatchHandlers {
+	return &BatchHandlers{
+		batchService:    batchService,
+		commentService:  commentService,
+		exportService:   exportService,
+		analysisService: analysisService,
+	}
+}
+
+// BatchScrapeRequest 批量爬取请求
+type BatchScrapeRequest struct {
+	Videos         []string               `json:"videos"`      // BV号、AV号或视频URL
+	Uploader       *UploaderSourceRequest `json:"uploader"`    // 抓取UP主的全部投稿（指定时忽略 videos）
+	Search         *SearchSourceRequest   `json:"search"`      // 抓取关键词搜索结果中的视频（指定时忽略 videos）
+	Concurrency    int                    `json:"concurrency"` // 同时抓取的视频数
+	AuthType       string                 `json:"auth_type"`   // none, cookie, app, account
+	Cookie         string                 `json:"cookie"`
+	AppKey         string                 `json:"app_key"`
+	AppSecret      string                 `json:"app_secret"`
+	AccountID      string                 `json:"account_id"` // 已登录账号ID（auth_type 为 account 时使用）
+	PageLimit      int                    `json:"page_limit"`
+	DelayMs        int                    `json:"delay_ms"`
+	SortMode       string                 `json:"sort_mode"`       // time(按时间), hot(按热度)
+	IncludeReplies bool                   `json:"include_replies"` // 是否抓取子评论
+	ScrapeOptionsRequest
+}
+
+// UploaderSourceRequest UP主投稿来源（按发布时间和数量过滤）
+type UploaderSourceRequest struct {
+	Mid       int64  `json:"mid" binding:"required"` // UP主UID
+	Since     string `json:"since"`                  // 只抓取此日期及之后发布的视频
+	Until     string `json:"until"`                  // 只抓取此日期及之前发布的视频
+	MaxVideos int    `json:"max_videos"`             // 最多抓取的视频数（从最新发布的开始）
+}
+
+// toSource 转换为批量任务来源
+func (r *UploaderSourceRequest) toSource() (*services.BatchSource, error) {
+	since, err := parseDateParam(r.Since, false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid uploader.since: %w", err)
+	}
+	until, err := parseDateParam(r.Until, true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid uploader.until: %w", err)
+	}
+
+	return &services.BatchSource{
+		Type:      services.BatchSourceUploader,
+		Mid:       r.Mid,
+		Since:     since,
+		Until:     until,
+		MaxVideos: r.MaxVideos,
+	}, nil
+}
+
+// SearchSourceRequest 关键词搜索来源
+type SearchSourceRequest struct {
+	Keyword   string `json:"keyword" binding:"required"`
+	Order     string `json:"order"`      // relevance(综合), views(播放), date(发布时间), danmaku(弹幕), favorites(收藏)
+	MaxVideos int    `json:"max_videos"` // 取前N个结果，默认20
+	Since     string `json:"since"`      // 只搜索此日期及之后发布的视频
+	Until     string `json:"until"`      // 只搜索此日期及之前发布的视频
+}
+
+// searchOrderAliases 请求中的排序名称与搜索接口排序参数的对应关系
+var searchOrderAliases = map[string]string{
+	"relevance": bilibili.SearchOrderRelevance,
+	"views":     bilibili.SearchOrderClick,
+	"date":      bilibili.SearchOrderPubdate,
+	"danmaku":   bilibili.SearchOrderDanmaku,
+	"favorites": bilibili.SearchOrderFavorite,
+}
+
+// toSource 转换为批量任务来源
+func (r *SearchSourceRequest) toSource() (*services.BatchSource, error) {
+	since, err := parseDateParam(r.Since, false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search.since: %w", err)
+	}
+	until, err := parseDateParam(r.Until, true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search.until: %w", err)
+	}
+
+	order := r.Order
+	if alias, ok := searchOrderAliases[order]; ok {
+		order = alias
+	}
+
+	return &services.BatchSource{
+		Type:      services.BatchSourceSearch,
+		Keyword:   r.Keyword,
+		Order:     order,
+		Since:     since,
+		Until:     until,
+		MaxVideos: r.MaxVideos,
+	}, nil
+}
+
+// StartBatchHandler 启动批量爬取任务
+// POST /api/v2/batches
+// Body: {"videos": ["BV...", "https://www.bilibili.com/video/BV...", "av170001"], "concurrency": 2, "page_limit": 5}
+// 或 {"uploader": {"mid": 123, "since": "2024-01-01", "max_videos": 50}, "page_limit": 5}（先枚举UP主的投稿再依次抓取）
+// 或 {"search": {"keyword": "...", "order": "views", "max_videos": 20}, "page_limit": 5}（抓取搜索结果的前N个视频）
+// Response: 200 {batch对象}
+func (h *BatchHandlers) StartBatchHandler(c *gin.Context) {
+	var req BatchScrapeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	// 设置默认值（与单视频爬取一致）
+	if req.PageLimit == 0 {
+		req.PageLimit = 2
+	}
+	if req.DelayMs == 0 {
+		req.DelayMs = 300
+	}
+	if req.AuthType == "" {
+		req.AuthType = defaultAuthType(req.AccountID)
+	}
+	if req.SortMode == "" {
+		req.SortMode = "time"
+	}
+
+	if req.SortMode != "time" && req.SortMode != "hot" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort_mode: must be 'time' or 'hot'"})
+		return
+	}
+
+	options, err := req.toOptions(req.DelayMs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	var source *services.BatchSource
+	switch {
+	case req.Uploader != nil && req.Search != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "uploader 和 search 不能同时指定"})
+		return
+	case req.Uploader != nil:
+		source, err = req.Uploader.toSource()
+	case req.Search != nil:
+		source, err = req.Search.toSource()
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	batch, err := h.batchService.StartBatch(services.BatchSpec{
+		Inputs:         req.Videos,
+		Source:         source,
+		Concurrency:    req.Concurrency,
+		AuthType:       req.AuthType,
+		Cookie:         req.Cookie,
+		AppKey:         req.AppKey,
+		AppSecret:      req.AppSecret,
+		AccountID:      req.AccountID,
+		PageLimit:      req.PageLimit,
+		DelayMs:        req.DelayMs,
+		SortMode:       req.SortMode,
+		IncludeReplies: req.IncludeReplies,
+		Options:        options,
+	})
+	if err != nil {
+		h.respondBatchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.formatBatch(batch))
+}
+
+// ListBatchesHandler 获取所有批量任务
+// GET /api/v2/batches
+// Response: 200 [{batch对象}, ...]
+func (h *BatchHandlers) ListBatchesHandler(c *gin.Context) {
+	batches := h.batchService.ListBatches()
+
+	result := make([]gin.H, 0, len(batches))
+	for _, batch := range batches {
+		result = append(result, h.formatBatch(batch))
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetBatchHandler 获取批量任务详情（汇总进度和每个视频的进度）
+// GET /api/v2/batches/:id
+// Response: 200 {batch对象}
+func (h *BatchHandlers) GetBatchHandler(c *gin.Context) {
+	batch, err := h.batchService.GetBatch(c.Param("id"))
+	if err != nil {
+		h.respondBatchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.formatBatch(batch))
+}
+
+// CancelBatchHandler 取消批量任务（已抓取的评论保留）
+// POST /api/v2/batches/:id/cancel
+// Response: 200 {"batch_id": "...", "status": "cancelling"}
+func (h *BatchHandlers) CancelBatchHandler(c *gin.Context) {
+	batchID := c.Param("id")
+
+	if err := h.batchService.CancelBatch(batchID); err != nil {
+		h.respondBatchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"batch_id": batchID, "status": "cancelling"})
+}
+
+// DeleteBatchHandler 删除已结束的批量任务（子任务保留，之后按正常规则清理）
+// DELETE /api/v2/batches/:id
+// Response: 200 {"batch_id": "...", "deleted": true}
+func (h *BatchHandlers) DeleteBatchHandler(c *gin.Context) {
+	batchID := c.Param("id")
+
+	if err := h.batchService.DeleteBatch(batchID); err != nil {
+		h.respondBatchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"batch_id": batchID, "deleted": true})
+}
+
+// ExportBatchHandler 将批量任务的所有评论导出为一个文件
+// POST /api/v2/batches/:id/export
+// Body: {"format": "excel|csv", "sort": "like_desc", "filename": "..."}
+// Response: 200 {导出文件信息}
+func (h *BatchHandlers) ExportBatchHandler(c *gin.Context) {
+	var req struct {
+		Format   string `json:"format" binding:"required"`
+		SortBy   string `json:"sort"`
+		Filename string `json:"filename"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	sortBy := req.SortBy
+	if sortBy == "" {
+		sortBy = "time_desc"
+	}
+
+	comments, err := h.batchService.GetBatchComments(c.Param("id"), sortBy, "")
+	if err != nil {
+		h.respondBatchError(c, err)
+		return
+	}
+
+	filename := req.Filename
+	if filename == "" {
+		filename = "batch_comments"
+	}
+
+	exportFile, err := h.exportService.ExportComments(comments, req.Format, filename)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ExportResponse{
+		FileID:      exportFile.FileID,
+		Filename:    exportFile.Filename,
+		DownloadURL: "/api/download/" + exportFile.FileID,
+		CreatedAt:   exportFile.CreatedAt.Format(time.RFC3339),
+	})
+}
+
+// AnalyzeBatchStreamHandler 将批量任务的所有评论作为整体进行流式分析（SSE格式同 /api/v2/analyze-stream）
+// POST /api/v2/batches/:id/analyze-stream
+// Body: {"template_id": "...", "custom_prompt": "...", "comment_limit": 500}
+func (h *BatchHandlers) AnalyzeBatchStreamHandler(c *gin.Context) {
+	var req struct {
+		TemplateID   string `json:"template_id" binding:"required"`
+		CustomPrompt string `json:"custom_prompt"`
+		CommentLimit int    `json:"comment_limit"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	batch, err := h.batchService.GetBatch(c.Param("id"))
+	if err != nil {
+		h.respondBatchError(c, err)
+		return
+	}
+
+	// 按点赞数排序，评论数量受限时优先分析高赞评论
+	comments, err := h.batchService.GetBatchComments(batch.BatchID, "like_desc", "")
+	if err != nil {
+		h.respondBatchError(c, err)
+		return
+	}
+
+	if len(comments) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "没有可分析的评论"})
+		return
+	}
+
+	template, errMsg := resolveTemplate(h.analysisService, req.TemplateID, req.CustomPrompt)
+	if errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+
+	streamAnalysis(c, h.analysisService, template, comments, h.batchService.BatchTitle(batch), nil, req.CommentLimit)
+}
+
+// respondBatchError 批量任务操作失败时的响应
+func (h *BatchHandlers) respondBatchError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrBatchNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "批量任务不存在"})
+	case errors.Is(err, services.ErrBatchInvalidState):
+		c.JSON(http.StatusConflict, gin.H{"error": "当前批量任务状态不允许该操作: " + err.Error()})
+	case errors.Is(err, services.ErrInvalidBatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+	case errors.Is(err, services.ErrBatchResultsMissing):
+		c.JSON(http.StatusGone, gin.H{"error": "部分视频的评论结果已不存在: " + err.Error()})
+	case errors.Is(err, services.ErrAccountNotFound), errors.Is(err, services.ErrAccountExpired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "账号不可用: " + err.Error()})
+	case errors.Is(err, services.ErrCredentialKeyNotSet):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "服务器未配置认证信息加密密钥（credential_key），不能使用Cookie或APP认证"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// formatBatch 转换为前端友好的格式，包含汇总进度和每个视频的进度
+func (h *BatchHandlers) formatBatch(batch *services.BatchJob) gin.H {
+	items := make([]gin.H, 0, len(batch.Items))
+	counts := map[string]int{}
+	totalComments := 0
+
+	for _, item := range batch.Items {
+		status := item.Status
+		entry := gin.H{
+			"input":    item.Input,
+			"video_id": item.VideoID,
+			"task_id":  item.TaskID,
+			"error":    item.Error,
+		}
+
+		// 子任务的实时状态和进度
+		if item.TaskID != "" {
+			if summary, ok := h.commentService.GetTaskSummary(item.TaskID); ok {
+				status = summary.Status
+				entry["video_title"] = summary.VideoTitle
+				entry["progress"] = gin.H{
+					"current_page":   summary.Progress.CurrentPage,
+					"page_limit":     summary.Progress.PageLimit,
+					"total_comments": summary.Progress.TotalComments,
+				}
+				if summary.Error != "" {
+					entry["error"] = summary.Error
+				}
+				totalComments += summary.Progress.TotalComments
+			}
+		}
+
+		entry["status"] = status
+		counts[status]++
+		items = append(items, entry)
+	}
+
+	finished := len(batch.Items) - counts["pending"] - counts["queued"] - counts["running"] - counts["paused"]
+
+	result := gin.H{
+		"batch_id":    batch.BatchID,
+		"status":      batch.Status,
+		"concurrency": batch.Concurrency,
+		"start_time":  batch.StartTime.Format("2006-01-02 15:04:05"),
+		"end_time":    formatOptionalTime(batch.EndTime, "2006-01-02 15:04:05"),
+		"error":       batch.Error,
+		"progress": gin.H{
+			"total_videos":   len(batch.Items),
+			"finished":       finished,
+			"pending":        counts["pending"],
+			"queued":         counts["queued"],
+			"running":        counts["running"] + counts["paused"],
+			"completed":      counts["completed"],
+			"failed":         counts["failed"],
+			"cancelled":      counts["cancelled"],
+			"skipped":        counts["skipped"],
+			"total_comments": totalComments,
+		},
+		"items": items,
+	}
+
+	if source := batch.Source; source != nil {
+		result["source"] = gin.H{
+			"type":         source.Type,
+			"mid":          source.Mid,
+			"name":         source.Name,
+			"keyword":      source.Keyword,
+			"order":        source.Order,
+			"since":        formatOptionalTime(source.Since, "2006-01-02 15:04:05"),
+			"until":        formatOptionalTime(source.Until, "2006-01-02 15:04:05"),
+			"max_videos":   source.MaxVideos,
+			"enumerated":   source.Enumerated,
+			"total_videos": source.TotalVideos,
+		}
+	}
+
+	return result
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -14,36 +16,115 @@ import (
 // CommentHandlers 评论处理器集合
 type CommentHandlers struct {
 	commentService *services.CommentService
+	videoService   *services.VideoService
 	exportService  *services.ExportService
 }
 
 // NewCommentHandlers 创建评论处理器
-func NewCommentHandlers(commentService *services.CommentService, exportService *services.ExportService) *CommentHandlers {
+func NewCommentHandlers(commentService *services.CommentService, videoService *services.VideoService, exportService *services.ExportService) *CommentHandlers {
 	return &CommentHandlers{
 		commentService: commentService,
+		videoService:   videoService,
 		exportService:  exportService,
 	}
 }
 
 // ScrapeRequest 爬取请求
 type ScrapeRequest struct {
-	VideoID        string `json:"video_id" binding:"required"`
-	AuthType       string `json:"auth_type"` // none, cookie, app
+	VideoID        string `json:"video_id" binding:"required"` // 视频、动态、专栏或音频的ID/URL
+	CommentType    int    `json:"comment_type"`                // 评论区类型：1 视频, 11 图片动态, 12 专栏, 14 音频, 17 动态；0 根据 video_id 自动识别
+	AuthType       string `json:"auth_type"`                   // none, cookie, app, account
 	Cookie         string `json:"cookie"`
 	AppKey         string `json:"app_key"`
 	AppSecret      string `json:"app_secret"`
+	AccountID      string `json:"account_id"` // 已登录账号ID（auth_type 为 account 时使用，只指定 account_id 时自动使用）
 	PageLimit      int    `json:"page_limit"`
 	DelayMs        int    `json:"delay_ms"`
 	SortMode       string `json:"sort_mode"`       // time(按时间), hot(按热度)
 	IncludeReplies bool   `json:"include_replies"` // 是否抓取子评论
+	Priority       int    `json:"priority"`        // 排队优先级：1 高，0 普通，-1 低
+	ScrapeOptionsRequest
+}
+
+// ScrapeOptionsRequest 可选的抓取配置（单视频、批量和定时抓取共用）
+type ScrapeOptionsRequest struct {
+	FullReplies    bool `json:"full_replies"`     // 分页抓取每条根评论的全部子评论（需同时开启 include_replies）
+	ReplyPageLimit int  `json:"reply_page_limit"` // 每条根评论最多抓取的子评论页数，0 表示不限制
+	ReplyDelayMs   int  `json:"reply_delay_ms"`   // 子评论翻页间隔（毫秒），默认与 delay_ms 相同
+
+	// 停止条件
+	Since       string `json:"since"`        // 只保留此日期之后的评论（2006-01-02 或 RFC3339），按时间排序时遇到更早的评论即停止
+	Until       string `json:"until"`        // 只保留此日期之前的评论（只写日期时包含当天）
+	MaxComments int    `json:"max_comments"` // 抓到指定数量的评论后停止，0 表示不限制
+	MinLikes    int    `json:"min_likes"`    // 跳过点赞数低于此值的评论
+}
+
+// toOptions 转换为服务层参数，delayMs 为主评论翻页间隔
+func (r ScrapeOptionsRequest) toOptions(delayMs int) (services.ScrapeOptions, error) {
+	if r.ReplyDelayMs == 0 {
+		r.ReplyDelayMs = delayMs
+	}
+	if r.MaxComments < 0 || r.MinLikes < 0 {
+		return services.ScrapeOptions{}, errors.New("max_comments and min_likes must not be negative")
+	}
+
+	since, err := parseDateParam(r.Since, false)
+	if err != nil {
+		return services.ScrapeOptions{}, fmt.Errorf("invalid since: %w", err)
+	}
+	until, err := parseDateParam(r.Until, true)
+	if err != nil {
+		return services.ScrapeOptions{}, fmt.Errorf("invalid until: %w", err)
+	}
+	if !since.IsZero() && !until.IsZero() && since.After(until) {
+		return services.ScrapeOptions{}, errors.New("since must not be later than until")
+	}
+
+	return services.ScrapeOptions{
+		FullReplies:    r.FullReplies,
+		ReplyPageLimit: r.ReplyPageLimit,
+		ReplyDelayMs:   r.ReplyDelayMs,
+		Since:          since,
+		Until:          until,
+		MaxComments:    r.MaxComments,
+		MinLikes:       r.MinLikes,
+	}, nil
+}
+
+// defaultAuthType 未指定 auth_type 时的认证方式：指定了账号时使用账号，否则无认证
+func defaultAuthType(accountID string) string {
+	if accountID != "" {
+		return "account"
+	}
+	return "none"
+}
+
+// parseDateParam 解析日期参数，支持 "2006-01-02"（本地时区）和 RFC3339 格式，空字符串返回零值
+// endOfDay 为 true 时只写日期的参数取当天最后一秒
+func parseDateParam(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected YYYY-MM-DD or RFC3339, got %q", value)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Second)
+	}
+	return t, nil
 }
 
 // ScrapeResponse 爬取响应
 type ScrapeResponse struct {
-	TaskID   string                `json:"task_id"`
-	VideoID  string                `json:"video_id"`
-	Status   string                `json:"status"`
-	Progress services.TaskProgress `json:"progress"`
+	TaskID      string                `json:"task_id"`
+	VideoID     string                `json:"video_id"`
+	CommentType int                   `json:"comment_type"`
+	Status      string                `json:"status"`
+	Progress    services.TaskProgress `json:"progress"`
 }
 
 // ScrapeCommentsHandler 启动爬取任务
@@ -62,7 +143,7 @@ func (h *CommentHandlers) ScrapeCommentsHandler(c *gin.Context) {
 		req.DelayMs = 300
 	}
 	if req.AuthType == "" {
-		req.AuthType = "none"
+		req.AuthType = defaultAuthType(req.AccountID)
 	}
 	if req.SortMode == "" {
 		req.SortMode = "time" // 默认按时间排序
@@ -74,33 +155,67 @@ func (h *CommentHandlers) ScrapeCommentsHandler(c *gin.Context) {
 		return
 	}
 
+	options, err := req.toOptions(req.DelayMs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	// 解析评论区（视频、动态、专栏、音频）
+	targetID, commentType, err := h.videoService.ParseCommentTarget(c.Request.Context(), req.VideoID, req.CommentType)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
 	// 启动爬取任务
-	taskID, err := h.commentService.StartScrapeTask(
-		req.VideoID,
-		req.AuthType,
-		req.Cookie,
-		req.AppKey,
-		req.AppSecret,
-		req.SortMode,
-		req.IncludeReplies,
-		req.PageLimit,
-		req.DelayMs,
-	)
+	taskID, err := h.commentService.StartScrapeTask(services.ScrapeSpec{
+		VideoID:        targetID,
+		CommentType:    commentType,
+		AuthType:       req.AuthType,
+		Cookie:         req.Cookie,
+		AppKey:         req.AppKey,
+		AppSecret:      req.AppSecret,
+		AccountID:      req.AccountID,
+		SortMode:       req.SortMode,
+		IncludeReplies: req.IncludeReplies,
+		PageLimit:      req.PageLimit,
+		DelayMs:        req.DelayMs,
+		Priority:       req.Priority,
+		Options:        options,
+	})
 
+	if errors.Is(err, services.ErrQueueFull) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Task queue is full, please try again later"})
+		return
+	}
+	if errors.Is(err, services.ErrAccountNotFound) || errors.Is(err, services.ErrAccountExpired) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Account unavailable: " + err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrCredentialKeyNotSet) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cookie/app auth is unavailable: the server has no credential_key configured"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start scraping: " + err.Error()})
 		return
 	}
 
+	// 任务可能直接开始执行，也可能在队列中等待
+	status := "queued"
+	progress := services.TaskProgress{PageLimit: req.PageLimit}
+	if summary, ok := h.commentService.GetTaskSummary(taskID); ok {
+		status = summary.Status
+		progress = summary.Progress
+	}
+
 	c.JSON(http.StatusOK, ScrapeResponse{
-		TaskID:  taskID,
-		VideoID: req.VideoID,
-		Status:  "running",
-		Progress: services.TaskProgress{
-			CurrentPage:   0,
-			TotalComments: 0,
-			PageLimit:     req.PageLimit,
-		},
+		TaskID:      taskID,
+		VideoID:     targetID,
+		CommentType: commentType,
+		Status:      status,
+		Progress:    progress,
 	})
 }
 
@@ -115,6 +230,7 @@ type ProgressResponse struct {
 	ElapsedSeconds int64                  `json:"elapsed_seconds"`
 	Error          string                 `json:"error,omitempty"`
 	VideoID        string                 `json:"video_id,omitempty"`
+	CommentType    int                    `json:"comment_type,omitempty"`
 	Comments       []bilibili.CommentData `json:"comments,omitempty"` // 添加评论数据
 }
 
@@ -146,20 +262,28 @@ func (h *CommentHandlers) GetProgressHandler(c *gin.Context) {
 		ElapsedSeconds: int64(elapsed),
 		Error:          task.Error,
 		VideoID:        task.VideoID,
+		CommentType:    task.CommentType,
 		Comments:       task.Comments, // 包含评论数据
 	})
 }
 
 // CommentItem 评论项（简化版）
 type CommentItem struct {
-	RPID    int64         `json:"rpid"`
-	Author  string        `json:"author"`
-	Avatar  string        `json:"avatar"`
-	Content string        `json:"content"`
-	Likes   int           `json:"likes"`
-	Time    string        `json:"time"`
-	Level   int           `json:"level"`
-	Replies []CommentItem `json:"replies,omitempty"` // 子评论
+	RPID      int64         `json:"rpid"`
+	Author    string        `json:"author"`
+	Avatar    string        `json:"avatar"`
+	Content   string        `json:"content"`
+	Likes     int           `json:"likes"`
+	Time      string        `json:"time"`
+	Level     int           `json:"level"`
+	FirstSeen string        `json:"first_seen,omitempty"` // 首次抓取时间
+	Location  string        `json:"location,omitempty"`   // IP属地
+	IsTop     bool          `json:"is_top,omitempty"`     // 置顶评论
+	UpLiked   bool          `json:"up_liked,omitempty"`   // UP主点赞
+	UpReplied bool          `json:"up_replied,omitempty"` // UP主回复
+	VipLabel  string        `json:"vip_label,omitempty"`  // 大会员标签（未开通时为空）
+	Pictures  []string      `json:"pictures,omitempty"`   // 评论图片URL
+	Replies   []CommentItem `json:"replies,omitempty"`    // 子评论
 }
 
 // ResultResponse 结果响应
@@ -178,13 +302,32 @@ func convertToCommentItem(comment bilibili.CommentData) CommentItem {
 	}
 
 	item := CommentItem{
-		RPID:    comment.RPID,
-		Author:  comment.Member.Uname,
-		Avatar:  avatar,
-		Content: comment.Content.Message,
-		Likes:   comment.Like,
-		Time:    time.Unix(int64(comment.Ctime), 0).Format("2006-01-02 15:04:05"),
-		Level:   comment.Member.LevelInfo.CurrentLevel,
+		RPID:      comment.RPID,
+		Author:    comment.Member.Uname,
+		Avatar:    avatar,
+		Content:   comment.Content.Message,
+		Likes:     comment.Like,
+		Time:      time.Unix(int64(comment.Ctime), 0).Format("2006-01-02 15:04:05"),
+		Level:     comment.Member.LevelInfo.CurrentLevel,
+		Location:  comment.IPLocation(),
+		IsTop:     comment.IsTop,
+		UpLiked:   comment.UpAction.Like,
+		UpReplied: comment.UpAction.Reply,
+	}
+
+	if comment.Member.Vip.IsVip() {
+		item.VipLabel = comment.Member.Vip.Label.Text
+		if item.VipLabel == "" {
+			item.VipLabel = "大会员"
+		}
+	}
+
+	for _, pic := range comment.Content.Pictures {
+		item.Pictures = append(item.Pictures, pic.ImgSrc)
+	}
+
+	if comment.FirstSeen > 0 {
+		item.FirstSeen = time.Unix(comment.FirstSeen, 0).Format("2006-01-02 15:04:05")
 	}
 
 	// 递归处理子评论
@@ -303,7 +446,7 @@ func (h *CommentHandlers) GetCommentsStatsHandler(c *gin.Context) {
 		return
 	}
 
-	if task.Status != "completed" {
+	if !task.HasResult() {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Task not completed yet"})
 		return
 	}
@@ -324,6 +467,8 @@ func (h *CommentHandlers) GetCommentsStatsHandler(c *gin.Context) {
 
 	// 统计日期分布
 	dateMap := make(map[string]int)
+	locationMap := make(map[string]int)
+	pinned, upLiked, upReplied, vip, withPictures := 0, 0, 0, 0, 0
 	//wordCount := make(map[string]int)
 
 	for _, comment := range task.Comments {
@@ -343,6 +488,27 @@ func (h *CommentHandlers) GetCommentsStatsHandler(c *gin.Context) {
 			likesMap["100+"]++
 		}
 
+		// IP属地分布（评论早于2022年或接口未返回时为空）
+		if location := comment.IPLocation(); location != "" {
+			locationMap[location]++
+		}
+
+		if comment.IsTop {
+			pinned++
+		}
+		if comment.UpAction.Like {
+			upLiked++
+		}
+		if comment.UpAction.Reply {
+			upReplied++
+		}
+		if comment.Member.Vip.IsVip() {
+			vip++
+		}
+		if len(comment.Content.Pictures) > 0 {
+			withPictures++
+		}
+
 		// 简单的关键词统计（按空格分词）
 		// 注：这里可以使用更复杂的中文分词库
 		// words := strings.Fields(comment.Content.Message)
@@ -354,6 +520,12 @@ func (h *CommentHandlers) GetCommentsStatsHandler(c *gin.Context) {
 	}
 
 	stats["by_date"] = dateMap
+	stats["by_location"] = locationMap
+	stats["pinned"] = pinned
+	stats["up_liked"] = upLiked
+	stats["up_replied"] = upReplied
+	stats["vip"] = vip
+	stats["with_pictures"] = withPictures
 
 	c.JSON(http.StatusOK, stats)
 }
@@ -374,6 +546,7 @@ func (h *CommentHandlers) GetAllTasksHandler(c *gin.Context) {
 			"task_id":       task.TaskID,
 			"video_id":      task.VideoID,
 			"video_title":   task.VideoTitle,
+			"comment_type":  task.CommentType,
 			"status":        task.Status,
 			"comment_count": commentCount,
 			"start_time":    task.StartTime.Format("2006-01-02 15:04:05"),
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"bilibili/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// CommenterHandlers 评论者处理器集合
+type CommenterHandlers struct {
+	commenterService *services.CommenterService
+}
+
+// NewCommenterHandlers 创建评论者处理器
+func NewCommenterHandlers(commenterService *services.CommenterService) *CommenterHandlers {
+	return &CommenterHandlers{
+		commenterService: commenterService,
+	}
+}
+
+// ListCommentersHandler 获取所有已抓取任务中的评论者排行
+// GET /api/v2/commenters?sort=comments|likes|videos|last_seen&keyword=...&offset=0&limit=50
+// Response: 200 {"total": 1234, "indexed_at": "...", "commenters": [{评论者汇总}, ...]}
+func (h *CommenterHandlers) ListCommentersHandler(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+
+	commenters, total := h.commenterService.ListCommenters(c.DefaultQuery("sort", "comments"), c.Query("keyword"), offset, limit)
+	_, builtAt := h.commenterService.IndexInfo()
+
+	c.JSON(http.StatusOK, gin.H{
+		"total":      total,
+		"indexed_at": formatOptionalTime(builtAt, "2006-01-02 15:04:05"),
+		"commenters": commenters,
+	})
+}
+
+// GetCommenterHandler 获取评论者的汇总数据和评论过的视频
+// GET /api/v2/commenters/:mid
+// Response: 200 {评论者详情}
+func (h *CommenterHandlers) GetCommenterHandler(c *gin.Context) {
+	mid, ok := parseCommenterMid(c)
+	if !ok {
+		return
+	}
+
+	profile, err := h.commenterService.GetCommenter(mid)
+	if err != nil {
+		h.respondCommenterError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// GetCommenterCommentsHandler 获取评论者在所有已抓取任务中的评论历史（最新的在前）
+// GET /api/v2/commenters/:mid/comments?offset=0&limit=100
+// Response: 200 {"mid": 123, "total": 42, "comments": [...]}
+func (h *CommenterHandlers) GetCommenterCommentsHandler(c *gin.Context) {
+	mid, ok := parseCommenterMid(c)
+	if !ok {
+		return
+	}
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+
+	comments, total, err := h.commenterService.GetCommenterComments(mid, offset, limit)
+	if err != nil {
+		h.respondCommenterError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"mid":      mid,
+		"total":    total,
+		"comments": comments,
+	})
+}
+
+// parseCommenterMid 解析路径中的用户UID，无效时直接响应 400
+func parseCommenterMid(c *gin.Context) (int64, bool) {
+	mid, err := strconv.ParseInt(c.Param("mid"), 10, 64)
+	if err != nil || mid <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return 0, false
+	}
+	return mid, true
+}
+
+// respondCommenterError 评论者查询失败时的响应
+func (h *CommenterHandlers) respondCommenterError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrCommenterNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "已抓取的评论中没有该用户"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bilibili/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// DanmakuHandlers 弹幕处理器集合
+type DanmakuHandlers struct {
+	danmakuService  *services.DanmakuService
+	videoService    *services.VideoService
+	exportService   *services.ExportService
+	analysisService *services.AnalysisService
+}
+
+// NewDanmakuHandlers 创建弹幕处理器
+func NewDanmakuHandlers(danmakuService *services.DanmakuService, videoService *services.VideoService, exportService *services.ExportService, analysisService *services.AnalysisService) *DanmakuHandlers {
+	return &DanmakuHandlers{
+		danmakuService:  danmakuService,
+		videoService:    videoService,
+		exportService:   exportService,
+		analysisService: analysisService,
+	}
+}
+
+// StartDanmakuHandler 启动弹幕抓取任务
+// POST /api/v2/danmaku
+// Body: {"video_id": "BV...", "source": "protobuf|xml", "page": 1, "all_pages": false}
+// Response: 200 {task对象}
+func (h *DanmakuHandlers) StartDanmakuHandler(c *gin.Context) {
+	var req struct {
+		VideoID  string `json:"video_id" binding:"required"` // BV号、AV号或视频URL
+		Source   string `json:"source"`                      // protobuf(默认，全部弹幕), xml(最新弹幕)
+		Page     int    `json:"page"`                        // 分P序号，默认第1P
+		AllPages bool   `json:"all_pages"`                   // 抓取全部分P（忽略page）
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	page := req.Page
+	if req.AllPages {
+		page = services.AllPages
+	} else if page == 0 {
+		page = 1
+	}
+
+	videoID, _, err := h.videoService.ParseVideoInput(c.Request.Context(), req.VideoID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	taskID, err := h.danmakuService.StartDanmakuTask(videoID, req.Source, page)
+	if err != nil {
+		h.respondDanmakuError(c, err)
+		return
+	}
+
+	task, err := h.danmakuService.GetTask(taskID)
+	if err != nil {
+		h.respondDanmakuError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.formatTask(task))
+}
+
+// ListDanmakuTasksHandler 获取所有弹幕任务
+// GET /api/v2/danmaku
+// Response: 200 [{task对象}, ...]
+func (h *DanmakuHandlers) ListDanmakuTasksHandler(c *gin.Context) {
+	tasks := h.danmakuService.ListTasks()
+
+	result := make([]gin.H, 0, len(tasks))
+	for _, task := range tasks {
+		result = append(result, h.formatTask(task))
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetDanmakuTaskHandler 获取弹幕任务详情
+// GET /api/v2/danmaku/:id
+// Response: 200 {task对象}
+func (h *DanmakuHandlers) GetDanmakuTaskHandler(c *gin.Context) {
+	task, err := h.danmakuService.GetTask(c.Param("id"))
+	if err != nil {
+		h.respondDanmakuError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.formatTask(task))
+}
+
+// GetDanmakuResultHandler 获取弹幕列表
+// GET /api/v2/danmaku/:id/result?sort=progress|time_desc|time_asc&keyword=...&limit=100
+// Response: 200 {"total": 1234, "danmaku": [...]}
+func (h *DanmakuHandlers) GetDanmakuResultHandler(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "0"))
+
+	danmaku, total, err := h.danmakuService.GetDanmaku(c.Param("id"), c.Query("sort"), c.Query("keyword"), limit)
+	if err != nil {
+		h.respondDanmakuError(c, err)
+		return
+	}
+
+	items := make([]gin.H, 0, len(danmaku))
+	for _, d := range danmaku {
+		items = append(items, gin.H{
+			"id":         d.ID,
+			"page":       d.Page,
+			"progress":   d.Progress,
+			"video_time": services.FormatDanmakuProgress(d.Progress),
+			"content":    d.Content,
+			"mode":       services.DanmakuModeName(d.Mode),
+			"font_size":  d.FontSize,
+			"color":      d.Color,
+			"mid_hash":   d.MidHash,
+			"time":       time.Unix(d.Ctime, 0).Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"total": total, "danmaku": items})
+}
+
+// CancelDanmakuTaskHandler 取消弹幕任务（保留已抓取的弹幕）
+// POST /api/v2/danmaku/:id/cancel
+// Response: 200 {"task_id": "...", "status": "cancelling"}
+func (h *DanmakuHandlers) CancelDanmakuTaskHandler(c *gin.Context) {
+	taskID := c.Param("id")
+
+	if err := h.danmakuService.CancelTask(taskID); err != nil {
+		h.respondDanmakuError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"task_id": taskID, "status": "cancelling"})
+}
+
+// GetDanmakuStatsHandler 获取弹幕统计（视频时间轴分布、类型分布、发送日期分布、高频弹幕）
+// GET /api/v2/danmaku/:id/stats
+func (h *DanmakuHandlers) GetDanmakuStatsHandler(c *gin.Context) {
+	stats, err := h.danmakuService.GetStats(c.Param("id"))
+	if err != nil {
+		h.respondDanmakuError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// ExportDanmakuHandler 导出弹幕
+// POST /api/v2/danmaku/:id/export
+// Body: {"format": "excel|csv", "filename": "..."}
+// Response: 200 {导出文件信息}
+func (h *DanmakuHandlers) ExportDanmakuHandler(c *gin.Context) {
+	var req struct {
+		Format   string `json:"format" binding:"required"`
+		Filename string `json:"filename"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	danmaku, _, err := h.danmakuService.GetDanmaku(c.Param("id"), "", "", 0)
+	if err != nil {
+		h.respondDanmakuError(c, err)
+		return
+	}
+
+	exportFile, err := h.exportService.ExportDanmaku(danmaku, req.Format, req.Filename)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ExportResponse{
+		FileID:      exportFile.FileID,
+		Filename:    exportFile.Filename,
+		DownloadURL: "/api/download/" + exportFile.FileID,
+		CreatedAt:   exportFile.CreatedAt.Format(time.RFC3339),
+	})
+}
+
+// AnalyzeDanmakuStreamHandler 流式分析弹幕（SSE格式同 /api/v2/analyze-stream）
+// 每条弹幕带视频时间，comment_limit 限制数量时在整个时间轴上均匀抽样
+// POST /api/v2/danmaku/:id/analyze-stream
+// Body: {"template_id": "...", "custom_prompt": "...", "comment_limit": 1000}
+func (h *DanmakuHandlers) AnalyzeDanmakuStreamHandler(c *gin.Context) {
+	var req struct {
+		TemplateID   string `json:"template_id" binding:"required"`
+		CustomPrompt string `json:"custom_prompt"`
+		CommentLimit int    `json:"comment_limit"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	task, err := h.danmakuService.GetTask(c.Param("id"))
+	if err != nil {
+		h.respondDanmakuError(c, err)
+		return
+	}
+
+	danmaku, _, err := h.danmakuService.GetDanmaku(task.TaskID, "", "", 0)
+	if err != nil {
+		h.respondDanmakuError(c, err)
+		return
+	}
+
+	if len(danmaku) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "没有可分析的弹幕"})
+		return
+	}
+
+	template, errMsg := resolveTemplate(h.analysisService, req.TemplateID, req.CustomPrompt)
+	if errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+
+	danmakuText := h.analysisService.FormatDanmaku(danmaku, req.CommentLimit)
+	prompt := h.analysisService.RenderTemplate(template, danmakuText, task.VideoTitle+"（弹幕）", len(danmaku), nil)
+	streamPrompt(c, h.analysisService, prompt)
+}
+
+// respondDanmakuError 弹幕任务操作失败时的响应
+func (h *DanmakuHandlers) respondDanmakuError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrDanmakuTaskNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "弹幕任务不存在"})
+	case errors.Is(err, services.ErrDanmakuTaskInvalidState):
+		c.JSON(http.StatusConflict, gin.H{"error": "当前弹幕任务状态不允许该操作: " + err.Error()})
+	case errors.Is(err, services.ErrInvalidDanmakuSource):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: source 只能为 protobuf 或 xml"})
+	case errors.Is(err, services.ErrVideoPageNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// formatTask 转换为前端友好的格式
+func (h *DanmakuHandlers) formatTask(task *services.DanmakuTask) gin.H {
+	return gin.H{
+		"task_id":       task.TaskID,
+		"video_id":      task.VideoID,
+		"video_title":   task.VideoTitle,
+		"page":          task.Page,
+		"all_pages":     task.Page == services.AllPages,
+		"cid":           task.CID,
+		"duration":      task.Duration,
+		"source":        task.Source,
+		"status":        task.Status,
+		"danmaku_count": task.Progress.TotalDanmaku,
+		"start_time":    task.StartTime.Format("2006-01-02 15:04:05"),
+		"end_time":      formatOptionalTime(task.EndTime, "2006-01-02 15:04:05"),
+		"error":         task.Error,
+		"progress":      task.Progress,
+	}
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"bilibili/internal/services"
+	"bilibili/pkg/bilibili"
+	"github.com/gin-gonic/gin"
+)
+
+// LiveHandlers 直播弹幕抓取处理器集合
+type LiveHandlers struct {
+	liveService *services.LiveService
+}
+
+// NewLiveHandlers 创建直播弹幕抓取处理器
+func NewLiveHandlers(liveService *services.LiveService) *LiveHandlers {
+	return &LiveHandlers{liveService: liveService}
+}
+
+// StartLiveCaptureHandler 开始抓取直播间弹幕、醒目留言和礼物
+// POST /api/v2/live
+// Body: {"room": "21452505", "duration_minutes": 120, "account_id": "..."}
+// Response: 200 {task对象}
+// 弹幕服务器连接使用zlib压缩（协议版本2），暂不支持brotli压缩（协议版本3）
+func (h *LiveHandlers) StartLiveCaptureHandler(c *gin.Context) {
+	var req struct {
+		Room            string `json:"room" binding:"required"` // 房间号（可以是短号）或直播间链接
+		DurationMinutes int    `json:"duration_minutes"`        // 抓取时长，0 表示直到手动停止
+		AccountID       string `json:"account_id"`              // 已登录账号ID，为空时以游客身份连接（弹幕用户名和UID会被隐藏）
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	roomID, err := services.ParseLiveRoomInput(req.Room)
+	if err != nil {
+		h.respondLiveError(c, err)
+		return
+	}
+
+	taskID, err := h.liveService.StartLiveCapture(roomID, req.DurationMinutes, req.AccountID)
+	if err != nil {
+		h.respondLiveError(c, err)
+		return
+	}
+
+	task, err := h.liveService.GetTask(taskID)
+	if err != nil {
+		h.respondLiveError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.formatTask(task))
+}
+
+// ListLiveTasksHandler 获取所有直播抓取任务
+// GET /api/v2/live
+// Response: 200 [{task对象}, ...]
+func (h *LiveHandlers) ListLiveTasksHandler(c *gin.Context) {
+	tasks := h.liveService.ListTasks()
+
+	result := make([]gin.H, 0, len(tasks))
+	for _, task := range tasks {
+		result = append(result, h.formatTask(task))
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetLiveTaskHandler 获取直播抓取任务详情
+// GET /api/v2/live/:id
+// Response: 200 {task对象}
+func (h *LiveHandlers) GetLiveTaskHandler(c *gin.Context) {
+	task, err := h.liveService.GetTask(c.Param("id"))
+	if err != nil {
+		h.respondLiveError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.formatTask(task))
+}
+
+// StopLiveCaptureHandler 停止直播抓取任务（保留已抓取的事件）
+// POST /api/v2/live/:id/stop
+// Response: 200 {"task_id": "...", "status": "stopping"}
+func (h *LiveHandlers) StopLiveCaptureHandler(c *gin.Context) {
+	taskID := c.Param("id")
+
+	if err := h.liveService.StopLiveCapture(taskID); err != nil {
+		h.respondLiveError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"task_id": taskID, "status": "stopping"})
+}
+
+// GetLiveEventsHandler 获取已抓取的直播间事件（按接收顺序）
+// GET /api/v2/live/:id/events?type=danmaku|super_chat|gift&keyword=...&offset=0&limit=100
+// Response: 200 {"total": 1234, "events": [...]}
+func (h *LiveHandlers) GetLiveEventsHandler(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "0"))
+
+	events, total, err := h.liveService.GetEvents(c.Param("id"), c.Query("type"), c.Query("keyword"), offset, limit)
+	if err != nil {
+		h.respondLiveError(c, err)
+		return
+	}
+
+	items := make([]gin.H, 0, len(events))
+	for _, event := range events {
+		items = append(items, formatLiveEvent(event))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"total": total, "events": items})
+}
+
+// respondLiveError 直播抓取任务操作失败时的响应
+func (h *LiveHandlers) respondLiveError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrLiveTaskNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "直播抓取任务不存在"})
+	case errors.Is(err, services.ErrLiveTaskInvalidState):
+		c.JSON(http.StatusConflict, gin.H{"error": "当前直播抓取任务状态不允许该操作: " + err.Error()})
+	case errors.Is(err, services.ErrInvalidLiveCapture):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+	case errors.Is(err, services.ErrAccountNotFound), errors.Is(err, services.ErrAccountExpired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "账号不可用: " + err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// formatTask 转换为前端友好的格式
+func (h *LiveHandlers) formatTask(task *services.LiveTask) gin.H {
+	return gin.H{
+		"task_id":      task.TaskID,
+		"room_id":      task.RoomID,
+		"real_room_id": task.RealRoomID,
+		"anchor_uid":   task.AnchorUID,
+		"title":        task.Title,
+		"status":       task.Status,
+		"connected":    task.Connected,
+		"popularity":   task.Popularity,
+		"stats":        task.Stats,
+		"reconnects":   task.Reconnects,
+		"start_time":   task.StartTime.Format("2006-01-02 15:04:05"),
+		"end_time":     formatOptionalTime(task.EndTime, "2006-01-02 15:04:05"),
+		"deadline":     formatOptionalTime(task.Deadline, "2006-01-02 15:04:05"),
+		"account_id":   task.AccountID,
+		"error":        task.Error,
+	}
+}
+
+// formatLiveEvent 转换直播间事件，只输出该类型相关的字段
+func formatLiveEvent(event bilibili.LiveEvent) gin.H {
+	item := gin.H{
+		"type":  event.Type,
+		"time":  event.Time.Format("2006-01-02 15:04:05"),
+		"uid":   event.UID,
+		"uname": event.Uname,
+	}
+	if event.MedalName != "" {
+		item["medal_name"] = event.MedalName
+		item["medal_level"] = event.MedalLevel
+	}
+
+	switch event.Type {
+	case bilibili.LiveEventDanmaku:
+		item["content"] = event.Content
+	case bilibili.LiveEventSuperChat:
+		item["content"] = event.Content
+		item["price"] = event.Price
+	case bilibili.LiveEventGift:
+		item["gift_name"] = event.GiftName
+		item["gift_num"] = event.GiftNum
+		item["price"] = event.Price
+	}
+	return item
+}
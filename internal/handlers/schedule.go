@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"bilibili/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ScheduleHandlers 定时监控处理器集合
+type ScheduleHandlers struct {
+	scheduleService *services.ScheduleService
+	commentService  *services.CommentService
+	videoService    *services.VideoService
+}
+
+// NewScheduleHandlers 创建定时监控处理器
+func NewScheduleHandlers(scheduleService *services.ScheduleService, commentService *services.CommentService, videoService *services.VideoService) *ScheduleHandlers {
+	return &ScheduleHandlers{
+		scheduleService: scheduleService,
+		commentService:  commentService,
+		videoService:    videoService,
+	}
+}
+
+// ScheduleRequest 创建/更新定时计划请求
+type ScheduleRequest struct {
+	VideoID         string `json:"video_id" binding:"required"`
+	IntervalMinutes int    `json:"interval_minutes" binding:"required"` // 抓取间隔（分钟）
+	SortMode        string `json:"sort_mode"`                           // time(按时间), hot(按热度)
+	PageLimit       int    `json:"page_limit"`
+	DelayMs         int    `json:"delay_ms"`
+	IncludeReplies  bool   `json:"include_replies"`
+	Mode            string `json:"mode"`      // full(每次完整抓取，默认), refresh(首次完整抓取，之后增量刷新同一个任务)
+	AuthType        string `json:"auth_type"` // none, cookie, app, account
+	Cookie          string `json:"cookie"`    // 更新时认证方式不变且留空则沿用原有认证信息
+	AppKey          string `json:"app_key"`
+	AppSecret       string `json:"app_secret"`
+	AccountID       string `json:"account_id"` // 已登录账号ID（auth_type 为 account 时使用）
+	Enabled         *bool  `json:"enabled"`    // 默认启用
+	ScrapeOptionsRequest
+}
+
+// toSpec 转换为服务层参数
+func (r ScheduleRequest) toSpec() (services.ScheduleSpec, error) {
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+	options, err := r.toOptions(r.DelayMs)
+	if err != nil {
+		return services.ScheduleSpec{}, err
+	}
+	authType := r.AuthType
+	if authType == "" {
+		authType = defaultAuthType(r.AccountID)
+	}
+	return services.ScheduleSpec{
+		VideoID:         r.VideoID,
+		IntervalMinutes: r.IntervalMinutes,
+		SortMode:        r.SortMode,
+		PageLimit:       r.PageLimit,
+		DelayMs:         r.DelayMs,
+		IncludeReplies:  r.IncludeReplies,
+		Options:         options,
+		Mode:            r.Mode,
+		AuthType:        authType,
+		Cookie:          r.Cookie,
+		AppKey:          r.AppKey,
+		AppSecret:       r.AppSecret,
+		AccountID:       r.AccountID,
+		Enabled:         enabled,
+	}, nil
+}
+
+// ListSchedulesHandler 获取所有定时计划
+// GET /api/v2/schedules
+// Response: 200 [{schedule对象}, ...]
+func (h *ScheduleHandlers) ListSchedulesHandler(c *gin.Context) {
+	schedules := h.scheduleService.ListSchedules()
+
+	result := make([]gin.H, 0, len(schedules))
+	for _, schedule := range schedules {
+		result = append(result, h.formatSchedule(schedule))
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateScheduleHandler 创建定时计划
+// POST /api/v2/schedules
+// Body: {"video_id": "BV...", "interval_minutes": 60, "sort_mode": "time", "page_limit": 5, "include_replies": false, "mode": "refresh", "account_id": "..."}
+// Response: 201 {schedule对象}
+func (h *ScheduleHandlers) CreateScheduleHandler(c *gin.Context) {
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	spec, err := req.toSpec()
+	if err == nil {
+		// 链接和短链接解析为视频ID后保存，避免每次运行重复解析
+		spec.VideoID, _, err = h.videoService.ParseVideoInput(c.Request.Context(), spec.VideoID)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	schedule, err := h.scheduleService.CreateSchedule(spec)
+	if err != nil {
+		h.respondScheduleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, h.formatSchedule(schedule))
+}
+
+// GetScheduleHandler 获取单个定时计划
+// GET /api/v2/schedules/:id
+// Response: 200 {schedule对象}
+func (h *ScheduleHandlers) GetScheduleHandler(c *gin.Context) {
+	schedule, err := h.scheduleService.GetSchedule(c.Param("id"))
+	if err != nil {
+		h.respondScheduleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.formatSchedule(schedule))
+}
+
+// UpdateScheduleHandler 更新定时计划
+// PUT /api/v2/schedules/:id
+// Body: 同创建
+// Response: 200 {schedule对象}
+func (h *ScheduleHandlers) UpdateScheduleHandler(c *gin.Context) {
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	spec, err := req.toSpec()
+	if err == nil {
+		// 链接和短链接解析为视频ID后保存，避免每次运行重复解析
+		spec.VideoID, _, err = h.videoService.ParseVideoInput(c.Request.Context(), spec.VideoID)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	schedule, err := h.scheduleService.UpdateSchedule(c.Param("id"), spec)
+	if err != nil {
+		h.respondScheduleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.formatSchedule(schedule))
+}
+
+// DeleteScheduleHandler 删除定时计划（已创建的任务保留）
+// DELETE /api/v2/schedules/:id
+// Response: 200 {"schedule_id": "...", "deleted": true}
+func (h *ScheduleHandlers) DeleteScheduleHandler(c *gin.Context) {
+	scheduleID := c.Param("id")
+
+	if err := h.scheduleService.DeleteSchedule(scheduleID); err != nil {
+		h.respondScheduleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"schedule_id": scheduleID, "deleted": true})
+}
+
+// GetScheduleRunsHandler 获取定时计划的运行历史
+// GET /api/v2/schedules/:id/runs
+// Response: 200 [{"task_id": "...", "task_status": "completed", ...}, ...]
+func (h *ScheduleHandlers) GetScheduleRunsHandler(c *gin.Context) {
+	schedule, err := h.scheduleService.GetSchedule(c.Param("id"))
+	if err != nil {
+		h.respondScheduleError(c, err)
+		return
+	}
+
+	result := make([]gin.H, 0, len(schedule.Runs))
+	for _, run := range schedule.Runs {
+		result = append(result, h.formatRun(run))
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RunScheduleHandler 立即运行一次定时计划
+// POST /api/v2/schedules/:id/run
+// Response: 200 {run对象}
+func (h *ScheduleHandlers) RunScheduleHandler(c *gin.Context) {
+	run, err := h.scheduleService.RunScheduleNow(c.Param("id"))
+	if err != nil {
+		h.respondScheduleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.formatRun(*run))
+}
+
+// respondScheduleError 计划操作失败时的响应：计划不存在返回404，参数错误返回400
+func (h *ScheduleHandlers) respondScheduleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrScheduleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "定时计划不存在"})
+	case errors.Is(err, services.ErrInvalidSchedule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+	case errors.Is(err, services.ErrAccountNotFound), errors.Is(err, services.ErrAccountExpired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "账号不可用: " + err.Error()})
+	case errors.Is(err, services.ErrCredentialKeyNotSet):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "服务器未配置认证信息加密密钥（credential_key），不能使用Cookie或APP认证"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// formatSchedule 转换为前端友好的格式
+func (h *ScheduleHandlers) formatSchedule(schedule *services.Schedule) gin.H {
+	var lastRun gin.H
+	if len(schedule.Runs) > 0 {
+		lastRun = h.formatRun(schedule.Runs[0])
+	}
+
+	return gin.H{
+		"schedule_id":      schedule.ScheduleID,
+		"video_id":         schedule.VideoID,
+		"interval_minutes": schedule.IntervalMinutes,
+		"sort_mode":        schedule.SortMode,
+		"page_limit":       schedule.PageLimit,
+		"delay_ms":         schedule.DelayMs,
+		"include_replies":  schedule.IncludeReplies,
+		"full_replies":     schedule.Options.FullReplies,
+		"reply_page_limit": schedule.Options.ReplyPageLimit,
+		"reply_delay_ms":   schedule.Options.ReplyDelayMs,
+		"since":            formatOptionalTime(schedule.Options.Since, "2006-01-02 15:04:05"),
+		"until":            formatOptionalTime(schedule.Options.Until, "2006-01-02 15:04:05"),
+		"max_comments":     schedule.Options.MaxComments,
+		"min_likes":        schedule.Options.MinLikes,
+		"mode":             schedule.Mode,
+		"auth_type":        schedule.AuthType,
+		"account_id":       schedule.AccountID,
+		"has_credential":   schedule.CredentialID != "",
+		"enabled":          schedule.Enabled,
+		"created_at":       schedule.CreatedAt.Format("2006-01-02 15:04:05"),
+		"updated_at":       schedule.UpdatedAt.Format("2006-01-02 15:04:05"),
+		"last_run_at":      formatOptionalTime(schedule.LastRunAt, "2006-01-02 15:04:05"),
+		"next_run_at":      formatOptionalTime(schedule.NextRunAt, "2006-01-02 15:04:05"),
+		"run_count":        len(schedule.Runs),
+		"last_run":         lastRun,
+	}
+}
+
+// formatRun 转换运行记录，附带关联任务的当前状态（任务已被清理时为 "deleted"）
+func (h *ScheduleHandlers) formatRun(run services.ScheduleRun) gin.H {
+	taskStatus := ""
+	if run.TaskID != "" {
+		status, ok := h.commentService.TaskStatus(run.TaskID)
+		if !ok {
+			status = "deleted"
+		}
+		taskStatus = status
+	}
+
+	return gin.H{
+		"task_id":     run.TaskID,
+		"task_status": taskStatus,
+		"started_at":  run.StartedAt.Format("2006-01-02 15:04:05"),
+		"skipped":     run.Skipped,
+		"error":       run.Error,
+	}
+}
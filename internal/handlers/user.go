@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"bilibili/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// UserHandlers 用户处理器集合
+type UserHandlers struct {
+	userService *services.UserService
+}
+
+// NewUserHandlers 创建用户处理器
+func NewUserHandlers(userService *services.UserService) *UserHandlers {
+	return &UserHandlers{
+		userService: userService,
+	}
+}
+
+// GetUserHandler 获取用户资料（含关注数和粉丝数，结果缓存10分钟）
+// GET /api/v2/users/:mid?refresh=true
+// Response: 200 {用户资料}
+func (h *UserHandlers) GetUserHandler(c *gin.Context) {
+	mid, err := strconv.ParseInt(c.Param("mid"), 10, 64)
+	if err != nil || mid <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	refresh := c.Query("refresh") == "true"
+
+	profile, err := h.userService.GetUser(c.Request.Context(), mid, refresh)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"bilibili/internal/services"
+	"bilibili/pkg/bilibili"
 	"github.com/gin-gonic/gin"
 )
 
@@ -47,16 +49,24 @@ func (h *V2Handlers) GetTasksHandler(c *gin.Context) {
 			"task_id":       task.TaskID,
 			"video_id":      task.VideoID,
 			"video_title":   task.VideoTitle,
+			"comment_type":  task.CommentType,
 			"status":        task.Status,
 			"comment_count": commentCount,
 			"start_time":    task.StartTime.Format("2006-01-02 15:04"),
 			"end_time":      task.EndTime.Format("2006-01-02 15:04"),
 			"error":         task.Error,
+			"priority":      task.Priority,
+			"last_refresh":  formatOptionalTime(task.LastRefresh, "2006-01-02 15:04"),
+			"refresh_error": task.RefreshError,
 			// 进度信息
 			"progress": gin.H{
 				"current_page":   task.Progress.CurrentPage,
 				"page_limit":     task.Progress.PageLimit,
 				"total_comments": task.Progress.TotalComments,
+				"requests":       task.Progress.Requests,
+				"retries":        task.Progress.Retries,
+				"queue_position": task.Progress.QueuePosition,
+				"stop_reason":    task.Progress.StopReason,
 			},
 		})
 	}
@@ -86,13 +96,15 @@ func (h *V2Handlers) GetTaskHandler(c *gin.Context) {
 		for i := 0; i < limit; i++ {
 			comment := task.Comments[i]
 			commentsPreview = append(commentsPreview, gin.H{
-				"rpid":    comment.RPID,
-				"author":  comment.Member.Uname,
-				"avatar":  comment.Member.Avatar,
-				"content": comment.Content.Message,
-				"likes":   comment.Like,
-				"time":    formatTimestamp(comment.Ctime),
-				"level":   comment.Member.LevelInfo.CurrentLevel,
+				"rpid":     comment.RPID,
+				"author":   comment.Member.Uname,
+				"avatar":   comment.Member.Avatar,
+				"content":  comment.Content.Message,
+				"likes":    comment.Like,
+				"time":     formatTimestamp(comment.Ctime),
+				"level":    comment.Member.LevelInfo.CurrentLevel,
+				"location": comment.IPLocation(),
+				"is_top":   comment.IsTop,
 			})
 		}
 	}
@@ -101,20 +113,127 @@ func (h *V2Handlers) GetTaskHandler(c *gin.Context) {
 		"task_id":       task.TaskID,
 		"video_id":      task.VideoID,
 		"video_title":   task.VideoTitle,
+		"comment_type":  task.CommentType,
 		"status":        task.Status,
 		"comment_count": len(task.Comments),
 		"start_time":    task.StartTime.Format("2006-01-02 15:04:05"),
 		"end_time":      task.EndTime.Format("2006-01-02 15:04:05"),
 		"error":         task.Error,
+		"priority":      task.Priority,
+		"last_refresh":  formatOptionalTime(task.LastRefresh, "2006-01-02 15:04:05"),
+		"refresh_error": task.RefreshError,
 		"progress": gin.H{
 			"current_page":   task.Progress.CurrentPage,
 			"page_limit":     task.Progress.PageLimit,
 			"total_comments": task.Progress.TotalComments,
+			"requests":       task.Progress.Requests,
+			"retries":        task.Progress.Retries,
+			"queue_position": task.Progress.QueuePosition,
+			"stop_reason":    task.Progress.StopReason,
 		},
-		"comments": commentsPreview,
+		"comments":      commentsPreview,
+		"video_context": formatVideoContext(task.VideoContext),
 	})
 }
 
+// formatVideoContext 视频简介、标签和字幕概况（字幕只返回句数），没有时返回 nil
+func formatVideoContext(vc *services.VideoContext) gin.H {
+	if vc == nil {
+		return nil
+	}
+	return gin.H{
+		"description":    vc.Description,
+		"tags":           vc.Tags,
+		"subtitle_lang":  vc.SubtitleLang,
+		"subtitle_lines": len(vc.Subtitles),
+		"fetched_at":     formatOptionalTime(vc.FetchedAt, "2006-01-02 15:04:05"),
+	}
+}
+
+// CancelTaskHandler 取消任务（保留已抓取的评论）
+// POST /api/v2/tasks/:id/cancel
+// Response: 200 {"task_id": "...", "status": "cancelling"}
+func (h *V2Handlers) CancelTaskHandler(c *gin.Context) {
+	taskID := c.Param("id")
+
+	if err := h.commentService.CancelTask(taskID); err != nil {
+		h.respondTaskControlError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"task_id": taskID, "status": "cancelling"})
+}
+
+// PauseTaskHandler 暂停任务（当前页抓取完成后生效）
+// POST /api/v2/tasks/:id/pause
+// Response: 200 {"task_id": "...", "status": "paused"}
+func (h *V2Handlers) PauseTaskHandler(c *gin.Context) {
+	taskID := c.Param("id")
+
+	if err := h.commentService.PauseTask(taskID); err != nil {
+		h.respondTaskControlError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"task_id": taskID, "status": "paused"})
+}
+
+// ResumeTaskHandler 恢复已暂停的任务，或从断点继续失败的任务（没有空闲名额时重新排队）
+// POST /api/v2/tasks/:id/resume
+// Response: 200 {"task_id": "...", "status": "running|queued"}
+func (h *V2Handlers) ResumeTaskHandler(c *gin.Context) {
+	taskID := c.Param("id")
+
+	if err := h.commentService.ResumeTask(taskID); err != nil {
+		h.respondTaskControlError(c, err)
+		return
+	}
+
+	status, _ := h.commentService.TaskStatus(taskID)
+	c.JSON(http.StatusOK, gin.H{"task_id": taskID, "status": status})
+}
+
+// RefreshTaskHandler 增量刷新任务（只抓取上次之后的新评论并更新点赞数）
+// POST /api/v2/tasks/:id/refresh
+// Body(可选): {"page_limit": 10}
+// Response: 200 {"task_id": "...", "status": "running|queued"}
+func (h *V2Handlers) RefreshTaskHandler(c *gin.Context) {
+	taskID := c.Param("id")
+
+	var req struct {
+		PageLimit int `json:"page_limit"`
+	}
+	// 请求体可选
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+			return
+		}
+	}
+
+	if err := h.commentService.RefreshTask(taskID, req.PageLimit); err != nil {
+		h.respondTaskControlError(c, err)
+		return
+	}
+
+	status, _ := h.commentService.TaskStatus(taskID)
+	c.JSON(http.StatusOK, gin.H{"task_id": taskID, "status": status})
+}
+
+// respondTaskControlError 任务控制失败时的响应：任务不存在返回404，状态不允许返回409，队列已满返回503
+func (h *V2Handlers) respondTaskControlError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrQueueFull):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "任务队列已满，请稍后重试"})
+	case errors.Is(err, services.ErrTaskNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+	case errors.Is(err, services.ErrTaskInvalidState):
+		c.JSON(http.StatusConflict, gin.H{"error": "当前任务状态不允许该操作: " + err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 // ============================================================================
 // 模板相关 API
 // ============================================================================
@@ -173,7 +292,7 @@ func (h *V2Handlers) AnalyzeStreamHandlerV2(c *gin.Context) {
 		return
 	}
 
-	if task.Status != "completed" {
+	if !task.HasResult() {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "任务尚未完成"})
 		return
 	}
@@ -184,23 +303,44 @@ func (h *V2Handlers) AnalyzeStreamHandlerV2(c *gin.Context) {
 	}
 
 	// 获取模板
+	template, errMsg := resolveTemplate(h.analysisService, req.TemplateID, req.CustomPrompt)
+	if errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+
+	streamAnalysis(c, h.analysisService, template, task.Comments, task.VideoTitle, task.VideoContext, req.CommentLimit)
+}
+
+// resolveTemplate 根据模板ID获取Prompt模板，失败时返回错误提示
+func resolveTemplate(analysisService *services.AnalysisService, templateID, customPrompt string) (string, string) {
 	template := ""
-	if req.TemplateID == "custom" {
-		template = req.CustomPrompt
+	if templateID == "custom" {
+		template = customPrompt
 	} else {
-		t := h.analysisService.GetTemplateByID(req.TemplateID)
+		t := analysisService.GetTemplateByID(templateID)
 		if t == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "模板不存在"})
-			return
+			return "", "模板不存在"
 		}
 		template = t.Prompt
 	}
 
 	if template == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "请选择模板或输入自定义Prompt"})
-		return
+		return "", "请选择模板或输入自定义Prompt"
 	}
+	return template, ""
+}
+
+// streamAnalysis 以简化SSE格式流式输出评论分析结果（单个任务和批量任务共用）
+// videoContext 为视频简介、标签和字幕，批量任务等没有单一视频时传 nil
+func streamAnalysis(c *gin.Context, analysisService *services.AnalysisService, template string, comments []bilibili.CommentData, title string, videoContext *services.VideoContext, commentLimit int) {
+	commentsText := analysisService.FormatComments(comments, commentLimit)
+	prompt := analysisService.RenderTemplate(template, commentsText, title, len(comments), videoContext)
+	streamPrompt(c, analysisService, prompt)
+}
 
+// streamPrompt 以简化SSE格式流式输出已渲染Prompt的分析结果
+func streamPrompt(c *gin.Context, analysisService *services.AnalysisService, prompt string) {
 	// 设置SSE响应头
 	c.Writer.Header().Set("Content-Type", "text/event-stream")
 	c.Writer.Header().Set("Cache-Control", "no-cache")
@@ -220,10 +360,7 @@ func (h *V2Handlers) AnalyzeStreamHandlerV2(c *gin.Context) {
 
 	// 在goroutine中执行分析
 	go func() {
-		commentsText := h.analysisService.FormatComments(task.Comments, req.CommentLimit)
-		prompt := h.analysisService.RenderTemplate(template, commentsText, task.VideoTitle, len(task.Comments))
-
-		_, err := h.analysisService.CallLLMStream(c.Request.Context(), func(chunk string) {
+		_, err := analysisService.CallLLMStream(c.Request.Context(), func(chunk string) {
 			streamChan <- chunk
 		}, prompt)
 
@@ -306,7 +443,7 @@ func (h *V2Handlers) PreviewPromptHandlerV2(c *gin.Context) {
 	}
 
 	commentsText := h.analysisService.FormatComments(sampleComments, 0)
-	prompt := h.analysisService.RenderTemplate(template.Prompt, commentsText, task.VideoTitle, len(task.Comments))
+	prompt := h.analysisService.RenderTemplate(template.Prompt, commentsText, task.VideoTitle, len(task.Comments), task.VideoContext)
 
 	c.JSON(http.StatusOK, gin.H{
 		"prompt": prompt,
@@ -318,6 +455,14 @@ func (h *V2Handlers) PreviewPromptHandlerV2(c *gin.Context) {
 // 辅助函数
 // ============================================================================
 
+// formatOptionalTime 格式化可能为空的时间，零值返回空字符串
+func formatOptionalTime(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(layout)
+}
+
 // formatTimestamp 格式化时间戳
 func formatTimestamp(ts int) string {
 	if ts == 0 {
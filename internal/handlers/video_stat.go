@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"bilibili/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// VideoStatHandlers 视频数据快照处理器集合
+type VideoStatHandlers struct {
+	videoStatService *services.VideoStatService
+	videoService     *services.VideoService
+}
+
+// NewVideoStatHandlers 创建视频数据快照处理器
+func NewVideoStatHandlers(videoStatService *services.VideoStatService, videoService *services.VideoService) *VideoStatHandlers {
+	return &VideoStatHandlers{
+		videoStatService: videoStatService,
+		videoService:     videoService,
+	}
+}
+
+// VideoStatWatchRequest 开启定时记录请求
+type VideoStatWatchRequest struct {
+	IntervalMinutes int `json:"interval_minutes" binding:"required"`
+}
+
+// ListVideoStatsHandler 获取所有有快照记录的视频和定时记录
+// GET /api/v2/video-stats
+// Response: 200 {"videos": [{"bvid": "...", "title": "...", "total_snapshots": 12, "latest": {快照}}], "watches": [...]}
+func (h *VideoStatHandlers) ListVideoStatsHandler(c *gin.Context) {
+	videos, err := h.videoStatService.ListVideos()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	list := make([]gin.H, 0, len(videos))
+	for _, video := range videos {
+		item := gin.H{
+			"bvid":                   video.BVID,
+			"aid":                    video.AID,
+			"title":                  video.Title,
+			"watch_interval_minutes": video.WatchIntervalMinutes,
+			"total_snapshots":        video.TotalSnapshots,
+			"latest":                 nil,
+		}
+		if len(video.Points) > 0 {
+			item["latest"] = video.Points[0].VideoStatSnapshot
+		}
+		list = append(list, item)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"videos":  list,
+		"watches": h.videoStatService.ListWatches(),
+	})
+}
+
+// GetVideoStatsHandler 获取视频的数据时间序列
+// GET /api/v2/video-stats/:video_id?since=2024-01-01&until=2024-01-31
+// Response: 200 {"bvid": "...", "title": "...", "total_snapshots": 12, "points": [{快照, 增量, 每小时增速}]}
+func (h *VideoStatHandlers) GetVideoStatsHandler(c *gin.Context) {
+	videoID, ok := h.parseVideoID(c)
+	if !ok {
+		return
+	}
+
+	since, err := parseDateParam(c.Query("since"), false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "since 格式错误: " + err.Error()})
+		return
+	}
+	until, err := parseDateParam(c.Query("until"), true)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "until 格式错误: " + err.Error()})
+		return
+	}
+
+	series, err := h.videoStatService.GetSeries(videoID, since, until)
+	if err != nil {
+		h.respondVideoStatError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// SnapshotVideoStatsHandler 立即记录一次视频数据快照
+// POST /api/v2/video-stats/:video_id/snapshot
+// Response: 200 {快照}
+func (h *VideoStatHandlers) SnapshotVideoStatsHandler(c *gin.Context) {
+	videoID, ok := h.parseVideoID(c)
+	if !ok {
+		return
+	}
+
+	snapshot, err := h.videoStatService.Snapshot(c.Request.Context(), videoID)
+	if err != nil {
+		h.respondVideoStatError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, snapshot)
+}
+
+// WatchVideoStatsHandler 为视频开启（或修改）定时记录
+// PUT /api/v2/video-stats/:video_id/watch
+// Request: {"interval_minutes": 60}
+// Response: 200 {"bvid": "...", "interval_minutes": 60, "last_at": "...", "next_at": "..."}
+func (h *VideoStatHandlers) WatchVideoStatsHandler(c *gin.Context) {
+	videoID, ok := h.parseVideoID(c)
+	if !ok {
+		return
+	}
+
+	var req VideoStatWatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	watch, err := h.videoStatService.Watch(c.Request.Context(), videoID, req.IntervalMinutes)
+	if err != nil {
+		h.respondVideoStatError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, watch)
+}
+
+// UnwatchVideoStatsHandler 关闭视频的定时记录（保留已有快照）
+// DELETE /api/v2/video-stats/:video_id/watch
+// Response: 200 {"message": "..."}
+func (h *VideoStatHandlers) UnwatchVideoStatsHandler(c *gin.Context) {
+	videoID, ok := h.parseVideoID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.videoStatService.Unwatch(videoID); err != nil {
+		h.respondVideoStatError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已关闭定时记录"})
+}
+
+// parseVideoID 解析路径中的视频ID（BV号、AV号或链接），无效时直接响应 400
+func (h *VideoStatHandlers) parseVideoID(c *gin.Context) (string, bool) {
+	videoID, _, err := h.videoService.ParseVideoInput(c.Request.Context(), c.Param("video_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return "", false
+	}
+	return videoID, true
+}
+
+// respondVideoStatError 视频数据快照操作失败时的响应
+func (h *VideoStatHandlers) respondVideoStatError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrVideoStatsNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidStatWatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,582 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"bilibili/pkg/bilibili"
+	"bilibili/pkg/storage"
+	"bilibili/pkg/utils"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrAccountNotFound 登录账号不存在
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrAccountExpired 登录账号已失效，需要重新登录
+	ErrAccountExpired = errors.New("account login expired")
+	// ErrInvalidAccount 账号参数无效（如Cookie缺少SESSDATA或未登录）
+	ErrInvalidAccount = errors.New("invalid account")
+	// ErrLoginSessionNotFound 扫码登录会话不存在或已过期
+	ErrLoginSessionNotFound = errors.New("login session not found")
+)
+
+// 账号状态
+const (
+	AccountStatusValid   = "valid"
+	AccountStatusExpired = "expired"
+)
+
+// 扫码登录状态
+const (
+	LoginStatusPending   = "pending"   // 等待扫码
+	LoginStatusScanned   = "scanned"   // 已扫码，等待手机端确认
+	LoginStatusConfirmed = "confirmed" // 登录成功，账号已保存
+	LoginStatusExpired   = "expired"   // 二维码已失效
+)
+
+const (
+	// accountCheckInterval 定期通过 nav 接口检查账号是否仍处于登录状态
+	accountCheckInterval = 6 * time.Hour
+	// loginSessionTTL 登录二维码有效期（服务端为180秒），过期的会话会被清理
+	loginSessionTTL = 180 * time.Second
+)
+
+// AccountService 登录账号服务，管理扫码登录和保存的账号Cookie
+type AccountService struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	mu       sync.RWMutex
+	accounts map[string]*Account
+	sessions map[string]*LoginSession
+	storage  storage.AccountStorage
+	client   *bilibili.BilibiliClient
+}
+
+// Account 登录账号
+type Account struct {
+	ID          string
+	Name        string
+	Mid         int64
+	Uname       string
+	Face        string
+	Status      string // valid, expired
+	LastChecked time.Time
+	ExpiresAt   time.Time // SESSDATA 过期时间，未知时为零值
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	cookies      map[string]string // 只保存在内存中，持久化时加密存储
+	credentialID string            // Cookie 在加密存储中的引用ID
+}
+
+// summary 复制账号信息（不含Cookie），避免泄露认证信息
+func (a *Account) summary() *Account {
+	copied := *a
+	copied.cookies = nil
+	return &copied
+}
+
+// LoginSession 扫码登录会话
+type LoginSession struct {
+	QRCodeKey string
+	URL       string // 二维码内容
+	Name      string // 登录成功后保存的账号名称
+	Status    string // LoginStatus* 常量
+	AccountID string // 登录成功后的账号ID
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// NewAccountService 创建登录账号服务
+func NewAccountService(ctx context.Context, accountStorage storage.AccountStorage, client *bilibili.BilibiliClient) *AccountService {
+	if client == nil {
+		client = bilibili.DefaultClient()
+	}
+	serviceCtx, cancel := context.WithCancel(ctx)
+
+	as := &AccountService{
+		ctx:      serviceCtx,
+		cancel:   cancel,
+		accounts: make(map[string]*Account),
+		sessions: make(map[string]*LoginSession),
+		storage:  accountStorage,
+		client:   client,
+	}
+
+	as.loadAccounts()
+
+	as.wg.Add(1)
+	go func() {
+		defer as.wg.Done()
+		as.checkWorker()
+	}()
+
+	return as
+}
+
+// StartQRLogin 申请登录二维码，name 为登录成功后保存的账号名称
+func (as *AccountService) StartQRLogin(ctx context.Context, name string) (*LoginSession, error) {
+	qrResp, err := as.client.GenerateLoginQRCode(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate login qrcode: %w", err)
+	}
+
+	now := time.Now()
+	session := &LoginSession{
+		QRCodeKey: qrResp.Data.QRCodeKey,
+		URL:       qrResp.Data.URL,
+		Name:      strings.TrimSpace(name),
+		Status:    LoginStatusPending,
+		CreatedAt: now,
+		ExpiresAt: now.Add(loginSessionTTL),
+	}
+
+	as.mu.Lock()
+	as.cleanupSessionsLocked(now)
+	as.sessions[session.QRCodeKey] = session
+	as.mu.Unlock()
+
+	copied := *session
+	return &copied, nil
+}
+
+// PollQRLogin 查询扫码登录状态，登录成功时保存账号（同一UID的账号会被更新而不是重复创建）
+func (as *AccountService) PollQRLogin(ctx context.Context, qrcodeKey string) (*LoginSession, error) {
+	as.mu.RLock()
+	session, exists := as.sessions[qrcodeKey]
+	var copied LoginSession
+	if exists {
+		copied = *session
+	}
+	as.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrLoginSessionNotFound, qrcodeKey)
+	}
+	// 已结束的会话直接返回结果，不再请求接口
+	if copied.Status == LoginStatusConfirmed || copied.Status == LoginStatusExpired {
+		return &copied, nil
+	}
+
+	result, err := as.client.PollLoginQRCode(ctx, qrcodeKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to poll login qrcode: %w", err)
+	}
+
+	status := copied.Status
+	accountID := ""
+	switch result.Status {
+	case bilibili.QRLoginNotScanned:
+		status = LoginStatusPending
+	case bilibili.QRLoginScanned:
+		status = LoginStatusScanned
+	case bilibili.QRLoginExpired:
+		status = LoginStatusExpired
+	case bilibili.QRLoginSuccess:
+		account, err := as.saveLogin(ctx, copied.Name, result.Cookies, result.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		status = LoginStatusConfirmed
+		accountID = account.ID
+	default:
+		return nil, fmt.Errorf("unknown qrcode login status %d: %s", result.Status, result.Message)
+	}
+
+	as.mu.Lock()
+	session.Status = status
+	if accountID != "" {
+		session.AccountID = accountID
+	}
+	copied = *session
+	as.mu.Unlock()
+
+	return &copied, nil
+}
+
+// ImportAccount 通过浏览器复制的Cookie添加账号，Cookie 必须处于登录状态
+func (as *AccountService) ImportAccount(ctx context.Context, name, cookie string) (*Account, error) {
+	cookies := bilibili.ParseCookieString(cookie)
+	if cookies["SESSDATA"] == "" {
+		return nil, fmt.Errorf("%w: cookie must contain SESSDATA", ErrInvalidAccount)
+	}
+	return as.saveLogin(ctx, name, cookies, time.Time{})
+}
+
+// saveLogin 校验登录Cookie并保存为账号，缺少 buvid3 时自动获取
+func (as *AccountService) saveLogin(ctx context.Context, name string, cookies map[string]string, expiresAt time.Time) (*Account, error) {
+	jar := make(map[string]string)
+	for _, key := range bilibili.LoginCookieNames {
+		if value := cookies[key]; value != "" {
+			jar[key] = value
+		}
+	}
+
+	if jar["buvid3"] == "" {
+		if spiResp, err := as.client.GetBuvid(ctx); err != nil {
+			utils.LogError(fmt.Sprintf("获取buvid失败: %v", err))
+		} else {
+			jar["buvid3"] = spiResp.Data.Buvid3
+			if spiResp.Data.Buvid4 != "" {
+				jar["buvid4"] = spiResp.Data.Buvid4
+			}
+		}
+	}
+
+	navResp, err := as.client.GetNav(ctx, bilibili.WithCookies(jar))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check login status: %w", err)
+	}
+	if !navResp.Data.IsLogin {
+		return nil, fmt.Errorf("%w: cookie is not logged in", ErrInvalidAccount)
+	}
+	if jar["DedeUserID"] == "" {
+		jar["DedeUserID"] = strconv.FormatInt(navResp.Data.Mid, 10)
+	}
+
+	// 先确定账号ID并加密保存Cookie，保存失败（如未配置密钥）时不修改内存中的账号
+	as.mu.RLock()
+	accountID := ""
+	for _, a := range as.accounts {
+		if a.Mid == navResp.Data.Mid {
+			accountID = a.ID
+			break
+		}
+	}
+	as.mu.RUnlock()
+	if accountID == "" {
+		accountID = uuid.New().String()
+	}
+	// Cookie 加密保存，账号文件中只记录引用ID
+	if err := as.storage.SaveCredential(storage.AccountCredentialID(accountID), &storage.CredentialEntry{Cookies: jar}); err != nil {
+		return nil, fmt.Errorf("failed to save credential: %w", err)
+	}
+
+	now := time.Now()
+	as.mu.Lock()
+	account, exists := as.accounts[accountID]
+	if !exists {
+		account = &Account{ID: accountID, CreatedAt: now}
+		as.accounts[account.ID] = account
+	}
+	if name = strings.TrimSpace(name); name != "" {
+		account.Name = name
+	} else if account.Name == "" {
+		account.Name = navResp.Data.Uname
+	}
+	account.Mid = navResp.Data.Mid
+	account.Uname = navResp.Data.Uname
+	account.Face = navResp.Data.Face
+	account.Status = AccountStatusValid
+	account.LastChecked = now
+	account.ExpiresAt = expiresAt
+	account.UpdatedAt = now
+	account.cookies = jar
+	account.credentialID = storage.AccountCredentialID(account.ID)
+	summary := account.summary()
+	as.mu.Unlock()
+	as.saveAccounts()
+
+	utils.LogInfo(fmt.Sprintf("Account %s saved for user %s (%d)", summary.ID, summary.Uname, summary.Mid))
+	return summary, nil
+}
+
+// CheckAccount 通过 nav 接口检查账号是否仍处于登录状态，并更新用户名和头像
+func (as *AccountService) CheckAccount(ctx context.Context, accountID string) (*Account, error) {
+	as.mu.RLock()
+	account, exists := as.accounts[accountID]
+	var cookies map[string]string
+	if exists {
+		cookies = account.cookies
+	}
+	as.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, accountID)
+	}
+
+	navResp, err := as.client.GetNav(ctx, bilibili.WithCookies(cookies))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check login status: %w", err)
+	}
+
+	as.mu.Lock()
+	account.LastChecked = time.Now()
+	if navResp.Data.IsLogin {
+		account.Status = AccountStatusValid
+		account.Uname = navResp.Data.Uname
+		account.Face = navResp.Data.Face
+	} else {
+		if account.Status != AccountStatusExpired {
+			utils.LogWarn(fmt.Sprintf("Account %s (%s) login expired", account.ID, account.Name))
+		}
+		account.Status = AccountStatusExpired
+	}
+	summary := account.summary()
+	as.mu.Unlock()
+
+	as.saveAccounts()
+
+	return summary, nil
+}
+
+// GetAccount 获取账号信息（不含Cookie）
+func (as *AccountService) GetAccount(accountID string) (*Account, error) {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	account, exists := as.accounts[accountID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, accountID)
+	}
+	return account.summary(), nil
+}
+
+// ListAccounts 获取所有账号信息（按创建时间排序，不含Cookie）
+func (as *AccountService) ListAccounts() []*Account {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	accounts := make([]*Account, 0, len(as.accounts))
+	for _, account := range as.accounts {
+		accounts = append(accounts, account.summary())
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].CreatedAt.Before(accounts[j].CreatedAt)
+	})
+
+	return accounts
+}
+
+// RenameAccount 修改账号名称
+func (as *AccountService) RenameAccount(accountID, name string) (*Account, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidAccount)
+	}
+
+	as.mu.Lock()
+	account, exists := as.accounts[accountID]
+	if !exists {
+		as.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, accountID)
+	}
+	account.Name = name
+	account.UpdatedAt = time.Now()
+	summary := account.summary()
+	as.mu.Unlock()
+
+	as.saveAccounts()
+
+	return summary, nil
+}
+
+// DeleteAccount 删除账号，引用该账号的任务再次运行时会失败
+func (as *AccountService) DeleteAccount(accountID string) error {
+	as.mu.Lock()
+	if _, exists := as.accounts[accountID]; !exists {
+		as.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrAccountNotFound, accountID)
+	}
+	delete(as.accounts, accountID)
+	as.mu.Unlock()
+
+	as.saveAccounts()
+	if err := as.storage.DeleteCredential(storage.AccountCredentialID(accountID)); err != nil {
+		utils.LogError(fmt.Sprintf("删除账号 %s 的认证信息失败: %v", accountID, err))
+	}
+
+	return nil
+}
+
+// AuthOptions 获取账号的请求认证选项（完整Cookie），账号不存在或已失效时返回错误
+func (as *AccountService) AuthOptions(accountID string) ([]bilibili.CommentOption, error) {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	account, exists := as.accounts[accountID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, accountID)
+	}
+	if account.Status == AccountStatusExpired {
+		return nil, fmt.Errorf("%w: %s (%s)", ErrAccountExpired, account.Name, accountID)
+	}
+
+	return []bilibili.CommentOption{bilibili.WithCookies(account.cookies)}, nil
+}
+
+// LiveChatOptions 获取账号的直播弹幕连接认证选项（UID、SESSDATA 和 buvid3），账号不存在或已失效时返回错误
+func (as *AccountService) LiveChatOptions(accountID string) ([]bilibili.LiveChatOption, error) {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	account, exists := as.accounts[accountID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, accountID)
+	}
+	if account.Status == AccountStatusExpired {
+		return nil, fmt.Errorf("%w: %s (%s)", ErrAccountExpired, account.Name, accountID)
+	}
+
+	opts := []bilibili.LiveChatOption{bilibili.WithLiveAuth(account.Mid, account.cookies["SESSDATA"])}
+	if buvid := account.cookies["buvid3"]; buvid != "" {
+		opts = append(opts, bilibili.WithLiveBuvid(buvid))
+	}
+	return opts, nil
+}
+
+// checkWorker 定期检查所有账号的登录状态
+func (as *AccountService) checkWorker() {
+	ticker := time.NewTicker(accountCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-as.ctx.Done():
+			utils.LogInfo("checkWorker stopped in AccountService")
+			return
+		case <-ticker.C:
+			as.checkAllAccounts()
+		}
+	}
+}
+
+// checkAllAccounts 检查所有仍有效的账号，失败时等待下一个间隔
+func (as *AccountService) checkAllAccounts() {
+	as.mu.Lock()
+	var ids []string
+	for id, account := range as.accounts {
+		if account.Status != AccountStatusExpired {
+			ids = append(ids, id)
+		}
+	}
+	as.cleanupSessionsLocked(time.Now())
+	as.mu.Unlock()
+
+	for _, id := range ids {
+		if as.ctx.Err() != nil {
+			return
+		}
+		if _, err := as.CheckAccount(as.ctx, id); err != nil {
+			utils.LogError(fmt.Sprintf("检查账号 %s 登录状态失败: %v", id, err))
+		}
+	}
+}
+
+// cleanupSessionsLocked 清理过期的扫码登录会话（调用方需持有写锁）
+func (as *AccountService) cleanupSessionsLocked(now time.Time) {
+	for key, session := range as.sessions {
+		// 登录结束的会话多保留一个有效期，便于前端读取最终结果
+		if now.After(session.ExpiresAt.Add(loginSessionTTL)) {
+			delete(as.sessions, key)
+		}
+	}
+}
+
+// loadAccounts 从存储加载账号
+func (as *AccountService) loadAccounts() {
+	index, err := as.storage.LoadAccounts()
+	if err != nil {
+		utils.LogError("加载登录账号失败: " + err.Error())
+		return
+	}
+
+	for _, entry := range index.Accounts {
+		account := accountFromStorage(entry)
+		if account.credentialID != "" {
+			credential, err := as.storage.LoadCredential(account.credentialID)
+			if err != nil {
+				// Cookie 无法解密（如更换了密钥）时账号无法使用，需要重新登录
+				utils.LogError(fmt.Sprintf("读取账号 %s 的认证信息失败: %v", account.ID, err))
+				account.Status = AccountStatusExpired
+			} else {
+				account.cookies = credential.Cookies
+			}
+		}
+		as.accounts[account.ID] = account
+	}
+}
+
+// saveAccounts 持久化所有账号
+func (as *AccountService) saveAccounts() {
+	as.mu.RLock()
+	entries := make([]storage.AccountEntry, 0, len(as.accounts))
+	for _, account := range as.accounts {
+		entries = append(entries, accountToStorage(account))
+	}
+	as.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+
+	if err := as.storage.SaveAccounts(&storage.AccountIndex{Accounts: entries}); err != nil {
+		utils.LogError("Failed to save accounts: " + err.Error())
+	}
+}
+
+// accountToStorage 转换为存储层格式（调用方需持有锁）
+func accountToStorage(a *Account) storage.AccountEntry {
+	return storage.AccountEntry{
+		ID:           a.ID,
+		Name:         a.Name,
+		Mid:          a.Mid,
+		Uname:        a.Uname,
+		Face:         a.Face,
+		CredentialID: a.credentialID,
+		Status:       a.Status,
+		LastChecked:  a.LastChecked,
+		ExpiresAt:    a.ExpiresAt,
+		CreatedAt:    a.CreatedAt,
+		UpdatedAt:    a.UpdatedAt,
+	}
+}
+
+// accountFromStorage 从存储层格式转换
+func accountFromStorage(e storage.AccountEntry) *Account {
+	return &Account{
+		ID:          e.ID,
+		Name:        e.Name,
+		Mid:         e.Mid,
+		Uname:       e.Uname,
+		Face:        e.Face,
+		Status:      e.Status,
+		LastChecked: e.LastChecked,
+		ExpiresAt:   e.ExpiresAt,
+		CreatedAt:   e.CreatedAt,
+		UpdatedAt:   e.UpdatedAt,
+
+		credentialID: e.CredentialID,
+	}
+}
+
+// Shutdown 优雅关闭服务
+func (as *AccountService) Shutdown(ctx context.Context) error {
+	utils.LogInfo("Shutting down AccountService...")
+
+	as.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		as.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		utils.LogInfo("AccountService shutdown complete")
+		return nil
+	case <-ctx.Done():
+		utils.LogError("AccountService shutdown timeout")
+		return ctx.Err()
+	}
+}
@@ -70,7 +70,7 @@ type PromptTemplate struct {
 	Name        string   `json:"name"`
 	Description string   `json:"description"`
 	Prompt      string   `json:"prompt"`
-	Fields      []string `json:"fields"` // 可用字段: comments, video_title, comment_count
+	Fields      []string `json:"fields"` // 可用字段: comments, video_title, comment_count, video_desc, video_tags, subtitles
 }
 
 // 预设的Prompt模板
@@ -204,6 +204,36 @@ var presetTemplates = []PromptTemplate{
 请用Markdown格式输出，使用列表格式。`,
 		Fields: []string{"comments", "video_title"},
 	},
+	{
+		ID:          "content_relevance",
+		Name:        "内容对照",
+		Description: "结合视频简介、标签和字幕，判断评论与视频实际内容的关系",
+		Prompt: `请结合视频的实际内容分析以下评论：
+
+## 视频信息
+视频标题：{{video_title}}
+视频标签：{{video_tags}}
+视频简介：
+{{video_desc}}
+
+## 视频字幕
+{{subtitles}}
+
+## 评论数据（共{{comment_count}}条）
+{{comments}}
+
+## 分析要求
+1. **内容相关性**：评论主要在讨论视频中的哪些内容，哪些评论与视频内容无关
+2. **观点核对**：评论中对视频内容的复述或质疑是否与字幕一致，指出误解或断章取义的评论
+3. **关注焦点**：观众最关注视频中的哪些片段或观点（可引用字幕时间）
+4. **补充与纠错**：评论中对视频内容的补充、纠错或有价值的延伸讨论
+5. **总结**：用3-5句话总结观众对视频内容的接受情况
+
+如果没有字幕，请根据标题、标签和简介判断，并说明分析依据有限。
+
+请用Markdown格式输出结果。`,
+		Fields: []string{"comments", "video_title", "comment_count", "video_desc", "video_tags", "subtitles"},
+	},
 	{
 		ID:          "custom",
 		Name:        "自定义分析",
@@ -250,11 +280,11 @@ func (s *AnalysisService) AnalyzeComments(req *AnalysisRequest) (*AnalysisResult
 	// 获取模板
 	template := req.Template
 	if template == "" {
-		template = s.renderTemplate(presetTemplates[0], commentsText, req.VideoTitle, len(req.Comments))
+		template = s.renderTemplate(presetTemplates[0], commentsText, req.VideoTitle, len(req.Comments), req.VideoContext)
 	} else {
 		// 检查是否是预设模板ID
 		if t := s.GetTemplateByID(template); t != nil {
-			template = s.renderTemplate(*t, commentsText, req.VideoTitle, len(req.Comments))
+			template = s.renderTemplate(*t, commentsText, req.VideoTitle, len(req.Comments), req.VideoContext)
 		}
 	}
 
@@ -271,12 +301,30 @@ func (s *AnalysisService) AnalyzeComments(req *AnalysisRequest) (*AnalysisResult
 	}, nil
 }
 
-// renderTemplate 渲染模板
-func (s *AnalysisService) renderTemplate(template PromptTemplate, commentsText, videoTitle string, commentCount int) string {
+// renderTemplate 渲染模板，videoContext 为 nil 时视频内容相关的占位符替换为"无"
+func (s *AnalysisService) renderTemplate(template PromptTemplate, commentsText, videoTitle string, commentCount int, videoContext *VideoContext) string {
 	result := template.Prompt
 	result = strings.ReplaceAll(result, "{{comments}}", commentsText)
 	result = strings.ReplaceAll(result, "{{video_title}}", videoTitle)
 	result = strings.ReplaceAll(result, "{{comment_count}}", fmt.Sprintf("%d", commentCount))
+
+	desc, tags, subtitles := "无", "无", "无"
+	if videoContext != nil {
+		// 未填写简介的视频接口返回"-"
+		if videoContext.Description != "" && videoContext.Description != "-" {
+			desc = videoContext.Description
+		}
+		if len(videoContext.Tags) > 0 {
+			tags = strings.Join(videoContext.Tags, "、")
+		}
+		// 字幕可能很长，只在模板用到时才格式化
+		if len(videoContext.Subtitles) > 0 && strings.Contains(result, "{{subtitles}}") {
+			subtitles = s.FormatSubtitles(videoContext.Subtitles, maxSubtitleRunes)
+		}
+	}
+	result = strings.ReplaceAll(result, "{{video_desc}}", desc)
+	result = strings.ReplaceAll(result, "{{video_tags}}", tags)
+	result = strings.ReplaceAll(result, "{{subtitles}}", subtitles)
 	return result
 }
 
@@ -624,10 +672,77 @@ func (s *AnalysisService) FormatComments(comments []bilibili.CommentData, limit
 	return s.formatComments(comments, limit)
 }
 
-// RenderTemplate 渲染模板
-func (s *AnalysisService) RenderTemplate(template string, commentsText, videoTitle string, commentCount int) string {
+// FormatDanmaku 格式化弹幕数据，每条弹幕带视频时间
+// 弹幕需按分P和视频时间排序；数量超过 limit 时在整个时间轴上均匀抽样，保证覆盖完整视频
+func (s *AnalysisService) FormatDanmaku(danmaku []bilibili.Danmaku, limit int) string {
+	if limit > 0 && limit < len(danmaku) {
+		sampled := make([]bilibili.Danmaku, limit)
+		for i := range sampled {
+			sampled[i] = danmaku[i*len(danmaku)/limit]
+		}
+		danmaku = sampled
+	}
+
+	// 包含多个分P时在时间前标注分P
+	multiPage := false
+	for _, d := range danmaku {
+		if d.Page != danmaku[0].Page {
+			multiPage = true
+			break
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString("```\n")
+	for _, d := range danmaku {
+		if multiPage {
+			builder.WriteString(fmt.Sprintf("[P%d %s] %s\n", d.Page, FormatDanmakuProgress(d.Progress), d.Content))
+		} else {
+			builder.WriteString(fmt.Sprintf("[%s] %s\n", FormatDanmakuProgress(d.Progress), d.Content))
+		}
+	}
+	builder.WriteString("```\n")
+	return builder.String()
+}
+
+// FormatSubtitles 格式化字幕，每句带视频时间，总字数超过 maxRunes 时截断（maxRunes<=0 表示不限制）
+func (s *AnalysisService) FormatSubtitles(lines []SubtitleLine, maxRunes int) string {
+	// 包含多个分P时在时间前标注分P
+	multiPage := false
+	for _, line := range lines {
+		if line.Page != lines[0].Page {
+			multiPage = true
+			break
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString("```\n")
+	runes := 0
+	for _, line := range lines {
+		progress := FormatDanmakuProgress(int(line.From * 1000))
+		var text string
+		if multiPage {
+			text = fmt.Sprintf("[P%d %s] %s\n", line.Page, progress, line.Content)
+		} else {
+			text = fmt.Sprintf("[%s] %s\n", progress, line.Content)
+		}
+
+		runes += len([]rune(text))
+		if maxRunes > 0 && runes > maxRunes {
+			builder.WriteString("……（字幕过长，后续内容已省略）\n")
+			break
+		}
+		builder.WriteString(text)
+	}
+	builder.WriteString("```\n")
+	return builder.String()
+}
+
+// RenderTemplate 渲染模板，videoContext 为视频简介、标签和字幕（没有时传 nil）
+func (s *AnalysisService) RenderTemplate(template string, commentsText, videoTitle string, commentCount int, videoContext *VideoContext) string {
 	promptTemplate := PromptTemplate{Prompt: template}
-	return s.renderTemplate(promptTemplate, commentsText, videoTitle, commentCount)
+	return s.renderTemplate(promptTemplate, commentsText, videoTitle, commentCount, videoContext)
 }
 
 // AnalysisRequest 分析请求
@@ -637,6 +752,7 @@ type AnalysisRequest struct {
 	Comments     []bilibili.CommentData `json:"comments"`
 	Template     string                 `json:"template"`      // 可以是模板ID或自定义Prompt
 	CommentLimit int                    `json:"comment_limit"` // 限制分析评论数量，0表示全部
+	VideoContext *VideoContext          `json:"video_context,omitempty"`
 }
 
 // AnalysisResult 分析结果
@@ -0,0 +1,744 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bilibili/pkg/bilibili"
+	"bilibili/pkg/storage"
+	"bilibili/pkg/utils"
+)
+
+var (
+	// ErrBatchNotFound 批量任务不存在
+	ErrBatchNotFound = errors.New("batch not found")
+	// ErrBatchInvalidState 批量任务当前状态不允许执行该操作
+	ErrBatchInvalidState = errors.New("invalid batch state")
+	// ErrInvalidBatch 批量任务参数不合法
+	ErrInvalidBatch = errors.New("invalid batch")
+	// ErrBatchResultsMissing 批量任务中部分视频的评论结果已不存在
+	ErrBatchResultsMissing = errors.New("batch results missing")
+)
+
+const (
+	// defaultBatchConcurrency 默认同时抓取的视频数
+	defaultBatchConcurrency = 2
+	// maxBatchConcurrency 允许的最大并发视频数
+	maxBatchConcurrency = 5
+	// maxBatchVideos 单个批量任务允许的最大视频数
+	maxBatchVideos = 100
+)
+
+// BatchService 批量任务服务，通过有界的工作池依次为多个视频创建抓取任务
+type BatchService struct {
+	ctx            context.Context
+	cancel         context.CancelFunc
+	wg             sync.WaitGroup
+	mu             sync.RWMutex
+	batches        map[string]*BatchJob
+	storage        storage.BatchStorage
+	commentService *CommentService
+	videoService   *VideoService
+}
+
+// BatchJob 批量任务
+type BatchJob struct {
+	BatchID        string
+	Status         string       // running, completed, cancelled, failed（枚举来源失败）
+	Source         *BatchSource // 视频来源，为空时视频列表由用户直接输入
+	Items          []BatchItem
+	Concurrency    int
+	AuthType       string
+	Cookie         string // 认证信息只保存在内存中，持久化时加密存储
+	AppKey         string
+	AppSecret      string
+	CredentialID   string // 认证信息在加密存储中的引用ID，没有认证信息时为空
+	AccountID      string
+	PageLimit      int
+	DelayMs        int
+	SortMode       string
+	IncludeReplies bool
+	Options        ScrapeOptions
+	StartTime      time.Time
+	EndTime        time.Time
+	Error          string
+
+	cancel context.CancelFunc // 取消批量任务（仅运行期间有效）
+}
+
+// BatchItem 批量任务中的单个视频
+type BatchItem struct {
+	Input   string // 原始输入（BV/AV号或URL）
+	VideoID string // 解析后的视频ID
+	TaskID  string // 对应的子任务
+	Status  string // pending, running, skipped, 以及子任务的最终状态
+	Error   string
+}
+
+// BatchSpec 创建批量任务的参数
+type BatchSpec struct {
+	Inputs         []string
+	Source         *BatchSource // 指定来源时由服务枚举视频，忽略 Inputs
+	Concurrency    int
+	AuthType       string
+	Cookie         string
+	AppKey         string
+	AppSecret      string
+	AccountID      string
+	PageLimit      int
+	DelayMs        int
+	SortMode       string
+	IncludeReplies bool
+	Options        ScrapeOptions
+}
+
+// clone 复制批量任务，避免调用方与工作池并发读写
+func (b *BatchJob) clone() *BatchJob {
+	copied := *b
+	copied.Items = append([]BatchItem(nil), b.Items...)
+	if b.Source != nil {
+		source := *b.Source
+		copied.Source = &source
+	}
+	copied.cancel = nil
+	return &copied
+}
+
+// NewBatchService 创建批量任务服务
+func NewBatchService(ctx context.Context, batchStorage storage.BatchStorage, commentService *CommentService, videoService *VideoService) *BatchService {
+	serviceCtx, cancel := context.WithCancel(ctx)
+
+	bs := &BatchService{
+		ctx:            serviceCtx,
+		cancel:         cancel,
+		batches:        make(map[string]*BatchJob),
+		storage:        batchStorage,
+		commentService: commentService,
+		videoService:   videoService,
+	}
+
+	// 启动时从存储加载，继续运行被中断的批量任务
+	bs.loadBatches()
+
+	return bs
+}
+
+// StartBatch 创建并启动批量任务
+// 输入在工作池中逐个用 ParseVideoInput 解析（短链接需要请求），无法解析或重复的视频单独标记，不影响其他视频
+// 指定 Source 时视频列表在后台枚举（UP主的全部投稿或关键词搜索结果），枚举完成后再依次抓取
+func (bs *BatchService) StartBatch(spec BatchSpec) (*BatchJob, error) {
+	if spec.Source != nil {
+		source := *spec.Source
+		source.Enumerated = false
+		if err := validateBatchSource(&source); err != nil {
+			return nil, err
+		}
+		spec.Source = &source
+		spec.Inputs = nil
+	} else {
+		if len(spec.Inputs) == 0 {
+			return nil, fmt.Errorf("%w: at least one video is required", ErrInvalidBatch)
+		}
+		if len(spec.Inputs) > maxBatchVideos {
+			return nil, fmt.Errorf("%w: at most %d videos per batch", ErrInvalidBatch, maxBatchVideos)
+		}
+	}
+
+	if spec.AuthType == "account" {
+		if _, err := bs.commentService.accountAuthOptions(spec.AccountID); err != nil {
+			return nil, err
+		}
+	}
+
+	if spec.Concurrency <= 0 {
+		spec.Concurrency = defaultBatchConcurrency
+	}
+	if spec.Concurrency > maxBatchConcurrency {
+		spec.Concurrency = maxBatchConcurrency
+	}
+
+	batchID := uuid.New().String()
+
+	// 认证信息加密保存，批量任务文件中只记录引用ID
+	credentialID := ""
+	credential := &storage.CredentialEntry{Cookie: spec.Cookie, AppKey: spec.AppKey, AppSecret: spec.AppSecret}
+	if !credential.IsEmpty() {
+		credentialID = storage.BatchCredentialID(batchID)
+		if err := bs.storage.SaveCredential(credentialID, credential); err != nil {
+			return nil, fmt.Errorf("failed to save credential: %w", err)
+		}
+	}
+
+	batch := &BatchJob{
+		BatchID:        batchID,
+		Status:         "running",
+		Source:         spec.Source,
+		Items:          make([]BatchItem, 0, len(spec.Inputs)),
+		Concurrency:    spec.Concurrency,
+		AuthType:       spec.AuthType,
+		Cookie:         spec.Cookie,
+		AppKey:         spec.AppKey,
+		AppSecret:      spec.AppSecret,
+		CredentialID:   credentialID,
+		AccountID:      spec.AccountID,
+		PageLimit:      spec.PageLimit,
+		DelayMs:        spec.DelayMs,
+		SortMode:       spec.SortMode,
+		IncludeReplies: spec.IncludeReplies,
+		Options:        spec.Options,
+		StartTime:      time.Now(),
+	}
+
+	for _, input := range spec.Inputs {
+		batch.Items = append(batch.Items, BatchItem{Input: input, Status: "pending"})
+	}
+
+	bs.mu.Lock()
+	bs.batches[batch.BatchID] = batch
+	bs.mu.Unlock()
+
+	bs.launchBatch(batch)
+
+	return bs.GetBatch(batch.BatchID)
+}
+
+// GetBatch 获取批量任务
+func (bs *BatchService) GetBatch(batchID string) (*BatchJob, error) {
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+
+	batch, exists := bs.batches[batchID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrBatchNotFound, batchID)
+	}
+	return batch.clone(), nil
+}
+
+// ListBatches 获取所有批量任务（最新的在前）
+func (bs *BatchService) ListBatches() []*BatchJob {
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+
+	batches := make([]*BatchJob, 0, len(bs.batches))
+	for _, batch := range bs.batches {
+		batches = append(batches, batch.clone())
+	}
+
+	sort.Slice(batches, func(i, j int) bool {
+		return batches[i].StartTime.After(batches[j].StartTime)
+	})
+
+	return batches
+}
+
+// CancelBatch 取消批量任务：未开始的视频不再抓取，进行中的子任务一并取消（保留已抓取的评论）
+func (bs *BatchService) CancelBatch(batchID string) error {
+	bs.mu.Lock()
+	batch, exists := bs.batches[batchID]
+	if !exists {
+		bs.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrBatchNotFound, batchID)
+	}
+	if batch.Status != "running" {
+		bs.mu.Unlock()
+		return fmt.Errorf("%w: cannot be cancelled in status %s", ErrBatchInvalidState, batch.Status)
+	}
+	cancel := batch.cancel
+	bs.mu.Unlock()
+
+	// 进行中的子任务由各自的 worker 负责取消
+	if cancel != nil {
+		cancel()
+	}
+	return nil
+}
+
+// DeleteBatch 删除已结束的批量任务，子任务保留，之后按正常规则清理
+func (bs *BatchService) DeleteBatch(batchID string) error {
+	bs.mu.Lock()
+	batch, exists := bs.batches[batchID]
+	if !exists {
+		bs.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrBatchNotFound, batchID)
+	}
+	if batch.Status == "running" {
+		bs.mu.Unlock()
+		return fmt.Errorf("%w: cannot be deleted in status %s", ErrBatchInvalidState, batch.Status)
+	}
+	delete(bs.batches, batchID)
+	items := batch.Items
+	bs.mu.Unlock()
+
+	for _, item := range items {
+		if item.TaskID != "" {
+			bs.commentService.ReleaseTask(item.TaskID)
+		}
+	}
+	if err := bs.storage.DeleteCredential(storage.BatchCredentialID(batchID)); err != nil {
+		utils.LogError(fmt.Sprintf("删除批量任务 %s 的认证信息失败: %v", batchID, err))
+	}
+
+	bs.saveBatches()
+	return nil
+}
+
+// GetBatchComments 获取批量任务所有视频的评论（合并为一个结果集）
+// 没有结果的子任务（如抓取失败）不计入；批量任务仍在运行时返回 ErrBatchInvalidState，
+// 子任务或其评论数据已不存在时返回 ErrBatchResultsMissing，避免导出或分析的结果缺少部分视频
+func (bs *BatchService) GetBatchComments(batchID, sortBy, keyword string) ([]bilibili.CommentData, error) {
+	batch, err := bs.GetBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Status == "running" {
+		return nil, fmt.Errorf("%w: batch is still running", ErrBatchInvalidState)
+	}
+
+	var comments []bilibili.CommentData
+	var missing []string
+	for _, item := range batch.Items {
+		if item.TaskID == "" {
+			continue
+		}
+		summary, ok := bs.commentService.GetTaskSummary(item.TaskID)
+		if !ok {
+			missing = append(missing, item.VideoID)
+			continue
+		}
+		if summary.Status != "completed" && summary.Status != "cancelled" {
+			continue
+		}
+		// 直接读取存储，不把每个子任务的评论缓存到内存
+		taskComments, err := bs.commentService.loadResultComments(item.TaskID)
+		if err != nil {
+			utils.LogWarn(fmt.Sprintf("批量任务 %s 读取子任务 %s 的评论失败: %v", batchID, item.TaskID, err))
+			missing = append(missing, item.VideoID)
+			continue
+		}
+		comments = append(comments, taskComments...)
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrBatchResultsMissing, strings.Join(missing, ", "))
+	}
+
+	if keyword != "" {
+		comments = bs.commentService.FilterComments(comments, keyword)
+	}
+	if sortBy != "" {
+		bs.commentService.SortComments(comments, sortBy)
+	}
+
+	return comments, nil
+}
+
+// BatchTitle 批量任务的展示标题（用于导出文件名和分析Prompt）
+func (bs *BatchService) BatchTitle(batch *BatchJob) string {
+	var titles []string
+	for _, item := range batch.Items {
+		if item.TaskID == "" {
+			continue
+		}
+		if summary, ok := bs.commentService.GetTaskSummary(item.TaskID); ok && summary.VideoTitle != "" {
+			titles = append(titles, summary.VideoTitle)
+		}
+	}
+
+	if batch.Source != nil && batch.Source.Type == BatchSourceUploader && batch.Source.Name != "" {
+		return fmt.Sprintf("UP主「%s」的投稿（%d个视频）", batch.Source.Name, len(titles))
+	}
+	if batch.Source != nil && batch.Source.Type == BatchSourceSearch {
+		return fmt.Sprintf("搜索「%s」的视频（%d个）", batch.Source.Keyword, len(titles))
+	}
+	if len(titles) == 0 {
+		return fmt.Sprintf("批量任务（%d个视频）", len(batch.Items))
+	}
+	if len(titles) > 3 {
+		return fmt.Sprintf("%s 等%d个视频", strings.Join(titles[:3], "、"), len(titles))
+	}
+	return strings.Join(titles, "、")
+}
+
+// launchBatch 在后台运行批量任务
+func (bs *BatchService) launchBatch(batch *BatchJob) {
+	batchCtx, cancel := context.WithCancel(bs.ctx)
+
+	bs.mu.Lock()
+	batch.cancel = cancel
+	bs.mu.Unlock()
+
+	// 立即持久化
+	bs.saveBatches()
+
+	bs.wg.Add(1)
+	go func() {
+		defer bs.wg.Done()
+		defer cancel()
+		bs.runBatch(batchCtx, batch)
+	}()
+}
+
+// runBatch 用固定数量的 worker 处理批量任务中的视频
+func (bs *BatchService) runBatch(ctx context.Context, batch *BatchJob) {
+	// 指定来源的批量任务先枚举视频（重启时若尚未枚举完成则重新枚举）
+	bs.mu.RLock()
+	needEnumerate := batch.Source != nil && !batch.Source.Enumerated
+	bs.mu.RUnlock()
+	if needEnumerate {
+		if err := bs.enumerateSource(ctx, batch); err != nil {
+			if bs.ctx.Err() != nil {
+				utils.LogInfo("Batch interrupted by shutdown: " + batch.BatchID)
+				return
+			}
+			bs.finishBatch(ctx, batch, err)
+			return
+		}
+	}
+
+	bs.mu.RLock()
+	var pending []int
+	for i, item := range batch.Items {
+		// 服务重启后，已创建子任务但尚未结束的视频继续等待
+		if item.Status == "pending" || item.Status == "running" {
+			pending = append(pending, i)
+		}
+	}
+	concurrency := batch.Concurrency
+	bs.mu.RUnlock()
+
+	jobs := make(chan int)
+	var workers sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for idx := range jobs {
+				bs.processItem(ctx, batch, idx)
+			}
+		}()
+	}
+
+feed:
+	for _, idx := range pending {
+		select {
+		case jobs <- idx:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	workers.Wait()
+
+	// 服务关闭：保持 running 状态，重启后继续
+	if bs.ctx.Err() != nil {
+		utils.LogInfo("Batch interrupted by shutdown: " + batch.BatchID)
+		return
+	}
+
+	bs.finishBatch(ctx, batch, nil)
+}
+
+// finishBatch 记录批量任务的最终状态：被取消、枚举来源失败或完成
+func (bs *BatchService) finishBatch(ctx context.Context, batch *BatchJob, err error) {
+	bs.mu.Lock()
+	switch {
+	case ctx.Err() != nil:
+		batch.Status = "cancelled"
+		for i := range batch.Items {
+			if batch.Items[i].Status == "pending" || batch.Items[i].Status == "running" {
+				batch.Items[i].Status = "cancelled"
+			}
+		}
+	case err != nil:
+		batch.Status = "failed"
+		batch.Error = err.Error()
+	default:
+		batch.Status = "completed"
+	}
+	batch.EndTime = time.Now()
+	batch.cancel = nil
+	bs.mu.Unlock()
+
+	utils.LogInfo(fmt.Sprintf("Batch %s finished with status %s", batch.BatchID, batch.Status))
+	bs.saveBatches()
+}
+
+// processItem 为单个视频创建子任务并等待其结束
+func (bs *BatchService) processItem(ctx context.Context, batch *BatchJob, idx int) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	bs.mu.RLock()
+	item := batch.Items[idx]
+	bs.mu.RUnlock()
+
+	// 直接输入的视频在这里解析，避免创建批量任务的请求等待所有短链接解析完成
+	if item.VideoID == "" {
+		videoID, ok := bs.resolveItem(ctx, batch, idx)
+		if !ok {
+			return
+		}
+		item.VideoID = videoID
+	}
+
+	taskID := item.TaskID
+	if taskID == "" {
+		var err error
+		taskID, err = bs.commentService.StartScrapeTask(ScrapeSpec{
+			VideoID:        item.VideoID,
+			CommentType:    bilibili.CommentTypeVideo,
+			AuthType:       batch.AuthType,
+			Cookie:         batch.Cookie,
+			AppKey:         batch.AppKey,
+			AppSecret:      batch.AppSecret,
+			AccountID:      batch.AccountID,
+			SortMode:       batch.SortMode,
+			IncludeReplies: batch.IncludeReplies,
+			PageLimit:      batch.PageLimit,
+			DelayMs:        batch.DelayMs,
+			Priority:       PriorityNormal,
+			Options:        batch.Options,
+		})
+		if err != nil {
+			bs.updateItem(batch, idx, "", "failed", err.Error())
+			return
+		}
+		// 批量任务存在期间保留子任务，导出和分析时需要读取其评论
+		bs.commentService.KeepTask(taskID)
+		bs.updateItem(batch, idx, taskID, "running", "")
+	}
+
+	status, err := bs.commentService.WaitTask(ctx, taskID)
+	if err != nil {
+		if ctx.Err() != nil {
+			// 用户取消批量任务时一并取消子任务；服务关闭时子任务会在重启后继续
+			if bs.ctx.Err() == nil {
+				bs.commentService.CancelTask(taskID)
+			}
+			return
+		}
+		bs.updateItem(batch, idx, taskID, "failed", err.Error())
+		return
+	}
+
+	// 服务关闭导致的中断，子任务会在重启后继续
+	if bs.ctx.Err() != nil {
+		return
+	}
+
+	errMsg := ""
+	if summary, ok := bs.commentService.GetTaskSummary(taskID); ok {
+		errMsg = summary.Error
+	}
+	bs.updateItem(batch, idx, taskID, status, errMsg)
+}
+
+// resolveItem 解析单个输入的视频ID，解析失败标记为 failed，与之前的视频重复时标记为 skipped
+// 返回 false 表示该视频无需抓取
+func (bs *BatchService) resolveItem(ctx context.Context, batch *BatchJob, idx int) (string, bool) {
+	bs.mu.RLock()
+	input := batch.Items[idx].Input
+	bs.mu.RUnlock()
+
+	videoID, _, err := bs.videoService.ParseVideoInput(ctx, input)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", false
+		}
+		bs.updateItem(batch, idx, "", "failed", err.Error())
+		return "", false
+	}
+
+	bs.mu.Lock()
+	duplicate := false
+	for i, other := range batch.Items {
+		if i != idx && other.VideoID == videoID && other.Status != "skipped" {
+			duplicate = true
+			break
+		}
+	}
+	batch.Items[idx].VideoID = videoID
+	if duplicate {
+		batch.Items[idx].Status = "skipped"
+		batch.Items[idx].Error = "duplicate video"
+	}
+	bs.mu.Unlock()
+
+	if duplicate {
+		bs.saveBatches()
+		return "", false
+	}
+	return videoID, true
+}
+
+// updateItem 更新单个视频的状态并持久化
+func (bs *BatchService) updateItem(batch *BatchJob, idx int, taskID, status, errMsg string) {
+	bs.mu.Lock()
+	batch.Items[idx].TaskID = taskID
+	batch.Items[idx].Status = status
+	batch.Items[idx].Error = errMsg
+	bs.mu.Unlock()
+
+	bs.saveBatches()
+}
+
+// loadBatches 从存储加载批量任务，重新启动被中断的批量任务
+func (bs *BatchService) loadBatches() {
+	index, err := bs.storage.LoadBatches()
+	if err != nil {
+		utils.LogError("加载批量任务失败: " + err.Error())
+		return
+	}
+
+	var resumable []*BatchJob
+	for _, entry := range index.Batches {
+		batch := convertBatchFromStorage(entry)
+		bs.batches[batch.BatchID] = batch
+		for _, item := range batch.Items {
+			if item.TaskID != "" {
+				bs.commentService.KeepTask(item.TaskID)
+			}
+		}
+		if batch.Status == "running" {
+			// 只有继续运行的批量任务需要认证信息
+			bs.loadBatchCredential(batch)
+			resumable = append(resumable, batch)
+		}
+	}
+
+	for _, batch := range resumable {
+		utils.LogInfo("Resuming batch " + batch.BatchID)
+		bs.launchBatch(batch)
+	}
+}
+
+// loadBatchCredential 读取批量任务的认证信息，读取失败时记录日志并按无认证信息处理
+func (bs *BatchService) loadBatchCredential(batch *BatchJob) {
+	if batch.CredentialID == "" {
+		return
+	}
+
+	credential, err := bs.storage.LoadCredential(batch.CredentialID)
+	if err != nil {
+		utils.LogWarn(fmt.Sprintf("批量任务 %s 读取认证信息失败，将不带认证继续: %v", batch.BatchID, err))
+		return
+	}
+	batch.Cookie = credential.Cookie
+	batch.AppKey = credential.AppKey
+	batch.AppSecret = credential.AppSecret
+}
+
+// saveBatches 持久化全部批量任务
+func (bs *BatchService) saveBatches() {
+	bs.mu.RLock()
+	entries := make([]storage.BatchEntry, 0, len(bs.batches))
+	for _, batch := range bs.batches {
+		entries = append(entries, convertBatchToStorage(batch))
+	}
+	bs.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].StartTime.Before(entries[j].StartTime)
+	})
+
+	if err := bs.storage.SaveBatches(&storage.BatchIndex{Batches: entries}); err != nil {
+		utils.LogError("Failed to save batches: " + err.Error())
+	}
+}
+
+// convertBatchToStorage 转换为存储层格式
+func convertBatchToStorage(b *BatchJob) storage.BatchEntry {
+	items := make([]storage.BatchItemEntry, len(b.Items))
+	for i, item := range b.Items {
+		items[i] = storage.BatchItemEntry{
+			Input:   item.Input,
+			VideoID: item.VideoID,
+			TaskID:  item.TaskID,
+			Status:  item.Status,
+			Error:   item.Error,
+		}
+	}
+
+	return storage.BatchEntry{
+		BatchID:        b.BatchID,
+		Status:         b.Status,
+		Source:         batchSourceToStorage(b.Source),
+		Items:          items,
+		Concurrency:    b.Concurrency,
+		AuthType:       b.AuthType,
+		CredentialID:   b.CredentialID,
+		AccountID:      b.AccountID,
+		PageLimit:      b.PageLimit,
+		DelayMs:        b.DelayMs,
+		SortMode:       b.SortMode,
+		IncludeReplies: b.IncludeReplies,
+		Options:        scrapeOptionsToStorage(b.Options),
+		StartTime:      b.StartTime,
+		EndTime:        b.EndTime,
+		Error:          b.Error,
+	}
+}
+
+// convertBatchFromStorage 从存储层格式转换
+func convertBatchFromStorage(e storage.BatchEntry) *BatchJob {
+	items := make([]BatchItem, len(e.Items))
+	for i, item := range e.Items {
+		items[i] = BatchItem{
+			Input:   item.Input,
+			VideoID: item.VideoID,
+			TaskID:  item.TaskID,
+			Status:  item.Status,
+			Error:   item.Error,
+		}
+	}
+
+	return &BatchJob{
+		BatchID:        e.BatchID,
+		Status:         e.Status,
+		Source:         batchSourceFromStorage(e.Source),
+		Items:          items,
+		Concurrency:    e.Concurrency,
+		AuthType:       e.AuthType,
+		CredentialID:   e.CredentialID,
+		AccountID:      e.AccountID,
+		PageLimit:      e.PageLimit,
+		DelayMs:        e.DelayMs,
+		SortMode:       e.SortMode,
+		IncludeReplies: e.IncludeReplies,
+		Options:        scrapeOptionsFromStorage(e.Options),
+		StartTime:      e.StartTime,
+		EndTime:        e.EndTime,
+		Error:          e.Error,
+	}
+}
+
+// Shutdown 优雅关闭服务，运行中的批量任务保存进度，重启后继续
+func (bs *BatchService) Shutdown(ctx context.Context) error {
+	utils.LogInfo("Shutting down BatchService...")
+
+	bs.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		bs.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		bs.saveBatches()
+		utils.LogInfo("BatchService shutdown complete")
+		return nil
+	case <-ctx.Done():
+		utils.LogError("BatchService shutdown timeout")
+		return ctx.Err()
+	}
+}
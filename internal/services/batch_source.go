@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"bilibili/pkg/bilibili"
+	"bilibili/pkg/storage"
+	"bilibili/pkg/utils"
+)
+
+const (
+	// BatchSourceUploader UP主的全部投稿
+	BatchSourceUploader = "uploader"
+	// BatchSourceSearch 关键词搜索结果
+	BatchSourceSearch = "search"
+
+	// maxSourceVideos 从来源枚举时单个批量任务允许的最大视频数
+	maxSourceVideos = 1000
+	// defaultSearchVideos 搜索来源未指定数量时抓取的视频数
+	defaultSearchVideos = 20
+	// sourcePageDelay 枚举来源时翻页之间的间隔
+	sourcePageDelay = 500 * time.Millisecond
+)
+
+// BatchSource 需要先枚举出视频列表的批量任务来源
+type BatchSource struct {
+	Type        string    // uploader, search
+	Mid         int64     // UP主UID
+	Name        string    // UP主昵称（枚举时获取）
+	Keyword     string    // 搜索关键词
+	Order       string    // 搜索结果排序方式（bilibili.SearchOrderRelevance 等）
+	Since       time.Time // 只包含此时间及之后发布的视频（零值表示不限制）
+	Until       time.Time // 只包含此时间及之前发布的视频（零值表示不限制）
+	MaxVideos   int       // 最多包含的视频数，从最新发布的开始（0表示不限制）
+	Enumerated  bool      // 视频列表是否已枚举完成
+	TotalVideos int       // 来源中的视频总数（过滤前，搜索来源为结果总数）
+}
+
+// searchOrders 支持的搜索排序方式
+var searchOrders = map[string]bool{
+	bilibili.SearchOrderRelevance: true,
+	bilibili.SearchOrderClick:     true,
+	bilibili.SearchOrderPubdate:   true,
+	bilibili.SearchOrderDanmaku:   true,
+	bilibili.SearchOrderFavorite:  true,
+}
+
+// validateBatchSource 校验批量任务来源并补全默认值
+func validateBatchSource(source *BatchSource) error {
+	switch source.Type {
+	case BatchSourceUploader:
+		if source.Mid <= 0 {
+			return fmt.Errorf("%w: invalid uploader mid %d", ErrInvalidBatch, source.Mid)
+		}
+	case BatchSourceSearch:
+		source.Keyword = strings.TrimSpace(source.Keyword)
+		if source.Keyword == "" {
+			return fmt.Errorf("%w: search keyword is required", ErrInvalidBatch)
+		}
+		if source.Order == "" {
+			source.Order = bilibili.SearchOrderRelevance
+		}
+		if !searchOrders[source.Order] {
+			return fmt.Errorf("%w: unsupported search order %q", ErrInvalidBatch, source.Order)
+		}
+		if source.MaxVideos == 0 {
+			source.MaxVideos = defaultSearchVideos
+		}
+	default:
+		return fmt.Errorf("%w: unsupported source type %q", ErrInvalidBatch, source.Type)
+	}
+
+	if !source.Since.IsZero() && !source.Until.IsZero() && !source.Since.Before(source.Until) {
+		return fmt.Errorf("%w: since must be before until", ErrInvalidBatch)
+	}
+	if source.MaxVideos < 0 {
+		return fmt.Errorf("%w: max_videos must not be negative", ErrInvalidBatch)
+	}
+	if source.MaxVideos == 0 || source.MaxVideos > maxSourceVideos {
+		source.MaxVideos = maxSourceVideos
+	}
+	return nil
+}
+
+// enumerateSource 枚举批量任务来源中的视频，结果写回批量任务
+func (bs *BatchService) enumerateSource(ctx context.Context, batch *BatchJob) error {
+	bs.mu.RLock()
+	source := *batch.Source
+	authType, cookie, appKey, appSecret, accountID := batch.AuthType, batch.Cookie, batch.AppKey, batch.AppSecret, batch.AccountID
+	bs.mu.RUnlock()
+
+	// 与子任务使用相同的认证，UP主投稿列表在未登录时更容易触发风控
+	opts, err := bs.commentService.authOptions(authType, cookie, appKey, appSecret, accountID)
+	if err != nil {
+		return err
+	}
+
+	var items []BatchItem
+	switch source.Type {
+	case BatchSourceUploader:
+		items, err = bs.enumerateUploaderVideos(ctx, &source, opts)
+	case BatchSourceSearch:
+		items, err = bs.enumerateSearchVideos(ctx, &source)
+	default:
+		err = fmt.Errorf("unsupported source type %q", source.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	source.Enumerated = true
+
+	bs.mu.Lock()
+	*batch.Source = source
+	batch.Items = items
+	bs.mu.Unlock()
+
+	utils.LogInfo(fmt.Sprintf("Batch %s enumerated %d videos from %s source", batch.BatchID, len(items), source.Type))
+	bs.saveBatches()
+	return nil
+}
+
+// enumerateUploaderVideos 按发布时间从新到旧翻页获取UP主的投稿，按时间范围和数量过滤
+func (bs *BatchService) enumerateUploaderVideos(ctx context.Context, source *BatchSource, opts []bilibili.CommentOption) ([]BatchItem, error) {
+	var items []BatchItem
+
+	for pn := 1; ; pn++ {
+		resp, err := bs.videoService.client.GetUserVideos(ctx, source.Mid, pn, bilibili.UserVideoMaxPageSize, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get videos of uploader %d (page %d): %w", source.Mid, pn, err)
+		}
+
+		source.TotalVideos = resp.Data.Page.Count
+		for _, video := range resp.Data.List.Vlist {
+			if source.Name == "" {
+				source.Name = video.Author
+			}
+
+			published := time.Unix(video.Created, 0)
+			if !source.Until.IsZero() && published.After(source.Until) {
+				continue
+			}
+			// 列表按发布时间倒序，早于起始时间即可结束
+			if !source.Since.IsZero() && published.Before(source.Since) {
+				return items, nil
+			}
+
+			items = append(items, BatchItem{
+				Input:   video.BVID,
+				VideoID: video.BVID,
+				Status:  "pending",
+			})
+			if len(items) >= source.MaxVideos {
+				return items, nil
+			}
+		}
+
+		if len(resp.Data.List.Vlist) == 0 || pn*bilibili.UserVideoMaxPageSize >= resp.Data.Page.Count {
+			return items, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(sourcePageDelay):
+		}
+	}
+}
+
+// enumerateSearchVideos 按关键词搜索视频，按指定排序方式取前 MaxVideos 个结果
+// 发布时间范围直接交给搜索接口过滤
+func (bs *BatchService) enumerateSearchVideos(ctx context.Context, source *BatchSource) ([]BatchItem, error) {
+	var items []BatchItem
+	seen := make(map[string]bool)
+
+	for page := 1; page <= bilibili.SearchMaxPages; page++ {
+		resp, err := bs.videoService.client.SearchVideos(ctx, source.Keyword, page,
+			bilibili.WithSearchOrder(source.Order),
+			bilibili.WithSearchPubTime(source.Since, source.Until),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search videos for %q (page %d): %w", source.Keyword, page, err)
+		}
+
+		source.TotalVideos = resp.Data.NumResults
+		for _, video := range resp.Data.Result {
+			// 翻页期间结果可能变化，同一视频只抓取一次
+			if video.BVID == "" || seen[video.BVID] {
+				continue
+			}
+			seen[video.BVID] = true
+
+			items = append(items, BatchItem{
+				Input:   video.BVID,
+				VideoID: video.BVID,
+				Status:  "pending",
+			})
+			if len(items) >= source.MaxVideos {
+				return items, nil
+			}
+		}
+
+		if len(resp.Data.Result) == 0 || page >= resp.Data.NumPages {
+			return items, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(sourcePageDelay):
+		}
+	}
+
+	return items, nil
+}
+
+// batchSourceToStorage 转换为存储层格式
+func batchSourceToStorage(s *BatchSource) *storage.BatchSourceEntry {
+	if s == nil {
+		return nil
+	}
+	return &storage.BatchSourceEntry{
+		Type:        s.Type,
+		Mid:         s.Mid,
+		Name:        s.Name,
+		Keyword:     s.Keyword,
+		Order:       s.Order,
+		Since:       s.Since,
+		Until:       s.Until,
+		MaxVideos:   s.MaxVideos,
+		Enumerated:  s.Enumerated,
+		TotalVideos: s.TotalVideos,
+	}
+}
+
+// batchSourceFromStorage 从存储层格式转换
+func batchSourceFromStorage(e *storage.BatchSourceEntry) *BatchSource {
+	if e == nil {
+		return nil
+	}
+	return &BatchSource{
+		Type:        e.Type,
+		Mid:         e.Mid,
+		Name:        e.Name,
+		Keyword:     e.Keyword,
+		Order:       e.Order,
+		Since:       e.Since,
+		Until:       e.Until,
+		MaxVideos:   e.MaxVideos,
+		Enumerated:  e.Enumerated,
+		TotalVideos: e.TotalVideos,
+	}
+}
@@ -2,9 +2,11 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -14,6 +16,24 @@ import (
 	"bilibili/pkg/utils"
 )
 
+var (
+	// ErrTaskNotFound 任务不存在
+	ErrTaskNotFound = errors.New("task not found")
+	// ErrTaskInvalidState 任务当前状态不允许执行该操作
+	ErrTaskInvalidState = errors.New("invalid task state")
+	// ErrCredentialKeyNotSet 未配置认证信息加密密钥，不能使用Cookie或APP认证
+	ErrCredentialKeyNotSet = storage.ErrCredentialKeyNotSet
+)
+
+// checkpointInterval 每抓取多少页保存一次断点
+const checkpointInterval = 5
+
+// 任务因停止条件提前结束的原因
+const (
+	StopReasonSince       = "since"        // 已抓取到早于起始日期的评论
+	StopReasonMaxComments = "max_comments" // 已达到评论数量上限
+)
+
 // CommentService 评论服务，管理爬取任务
 type CommentService struct {
 	ctx     context.Context
@@ -21,53 +41,139 @@ type CommentService struct {
 	wg      sync.WaitGroup
 	tasks   map[string]*ScrapeTask
 	mu      sync.RWMutex
-	storage storage.TaskStorage // 存储层
-	dirty   map[string]bool     // 脏标记：记录需要持久化的任务
+	storage storage.TaskStorage      // 存储层
+	dirty   map[string]bool          // 脏标记：记录需要持久化的任务
+	client  *bilibili.BilibiliClient // 共享的 Bilibili 客户端
+	kept    map[string]bool          // 被定时计划等引用的任务，不参与旧任务清理
+
+	queue         []*ScrapeTask // 等待执行的任务（按优先级排序）
+	running       int           // 占用运行名额的任务数
+	maxConcurrent int           // 同时运行的最大任务数
+	maxQueued     int           // 等待队列最大长度，0 表示不限制
+
+	videoStats *VideoStatService // 视频数据快照服务，为 nil 时不记录
+	accounts   *AccountService   // 登录账号服务，为 nil 时不支持 account 认证
 }
 
 // ScrapeTask 爬取任务
 type ScrapeTask struct {
 	TaskID         string
-	VideoID        string
+	VideoID        string // 评论区目标ID：视频为BV/av号，专栏为cv号，音频为au号，动态为动态ID
 	VideoTitle     string
-	Status         string // running, completed, failed
+	CommentType    int    // 评论区类型（bilibili.CommentType*）
+	Status         string // queued, running, paused, completed, failed, cancelled
 	Comments       []bilibili.CommentData
 	Progress       TaskProgress
 	StartTime      time.Time
 	EndTime        time.Time
 	Error          string
 	AuthType       string
-	Cookie         string
+	Cookie         string // 认证信息只保存在内存中，持久化时加密存储，任务文件只保存 CredentialID
 	AppKey         string
 	AppSecret      string
+	CredentialID   string // 认证信息在加密存储中的引用ID，没有认证信息时为空
+	AccountID      string // 登录账号ID（AuthType 为 account 时使用）
 	PageLimit      int
 	DelayMs        int
-	SortMode       string // "time" 按时间, "hot" 按热度
-	IncludeReplies bool   // 是否包含子评论
+	SortMode       string    // "time" 按时间, "hot" 按热度
+	IncludeReplies bool      // 是否包含子评论
+	Mode           string    // 当前运行模式：空为完整抓取，"refresh" 为增量刷新
+	LastRefresh    time.Time // 最近一次增量刷新完成时间
+	RefreshError   string    // 最近一次增量刷新失败的原因（任务保持已完成，原有结果不受影响）
+	Priority       int       // 排队优先级，数值越大越先执行
+	Options        ScrapeOptions
+	VideoContext   *VideoContext // 视频简介、标签和字幕（仅视频评论区），供AI分析对照
+
+	holdsSlot  bool                    // 是否占用运行名额（暂停时释放）
+	cancel     context.CancelFunc      // 取消任务（仅运行期间有效）
+	done       chan struct{}           // 本次运行结束时关闭
+	resumeCh   chan struct{}           // 暂停时非空，恢复时关闭
+	checkpoint *storage.TaskCheckpoint // 分页断点（仅运行期间有效）
+
+	stats        *bilibili.RequestStats // 本次运行的请求统计
+	baseRequests int64                  // 断点恢复前累计的请求数
+	baseRetries  int64                  // 断点恢复前累计的重试数
+}
+
+// ScrapeOptions 爬取任务的可选配置
+type ScrapeOptions struct {
+	FullReplies    bool // 分页抓取每条根评论的全部子评论（需同时开启 IncludeReplies）
+	ReplyPageLimit int  // 每条根评论最多抓取的子评论页数，0 表示不限制
+	ReplyDelayMs   int  // 子评论翻页间隔（毫秒）
+
+	// 停止条件（在分页循环中逐条评估）
+	Since       time.Time // 只保留此时间之后发布的评论；按时间排序时遇到更早的评论即停止
+	Until       time.Time // 只保留此时间之前发布的评论
+	MaxComments int       // 评论总数达到上限后停止，0 表示不限制
+	MinLikes    int       // 跳过点赞数低于此值的评论
+}
+
+// inDateRange 评论发布时间是否在 Since/Until 范围内
+func (o ScrapeOptions) inDateRange(ctime int) bool {
+	if !o.Since.IsZero() && int64(ctime) < o.Since.Unix() {
+		return false
+	}
+	if !o.Until.IsZero() && int64(ctime) > o.Until.Unix() {
+		return false
+	}
+	return true
+}
+
+// syncRequestStats 将请求统计同步到进度（调用方需持有锁）
+func (t *ScrapeTask) syncRequestStats() {
+	t.Progress.Requests = t.baseRequests + t.stats.Requests()
+	t.Progress.Retries = t.baseRetries + t.stats.Retries()
+}
+
+// HasResult 任务是否有可查看的评论结果（已完成或被取消时保留的部分结果）
+func (t *ScrapeTask) HasResult() bool {
+	return t.Status == "completed" || t.Status == "cancelled"
 }
 
 // TaskProgress 任务进度
 type TaskProgress struct {
-	CurrentPage   int `json:"current_page"`
-	TotalComments int `json:"total_comments"`
-	PageLimit     int `json:"page_limit"`
+	CurrentPage   int    `json:"current_page"`
+	TotalComments int    `json:"total_comments"`
+	PageLimit     int    `json:"page_limit"`
+	Requests      int64  `json:"requests"`              // 已发出的请求数（含重试）
+	Retries       int64  `json:"retries"`               // 重试次数
+	QueuePosition int    `json:"queue_position"`        // 排队位置（从1开始），未排队时为0
+	StopReason    string `json:"stop_reason,omitempty"` // 因停止条件提前结束时的原因
 }
 
-// NewCommentService 创建评论服务
-func NewCommentService(ctx context.Context, storage storage.TaskStorage) *CommentService {
+// NewCommentService 创建评论服务，调用 Start 后开始加载和执行任务
+// maxConcurrent 为同时运行的最大任务数（<=0 时使用默认值），maxQueued 为等待队列最大长度（0 表示不限制）
+func NewCommentService(ctx context.Context, storage storage.TaskStorage, client *bilibili.BilibiliClient, maxConcurrent, maxQueued int) *CommentService {
 	serviceCtx, cancel := context.WithCancel(ctx)
 
+	if client == nil {
+		client = bilibili.DefaultClient()
+	}
+	if maxConcurrent <= 0 {
+		maxConcurrent = DefaultMaxConcurrentTasks
+	}
+
 	cs := &CommentService{
-		ctx:     serviceCtx,
-		cancel:  cancel,
-		tasks:   make(map[string]*ScrapeTask),
-		storage: storage,
-		dirty:   make(map[string]bool),
+		ctx:           serviceCtx,
+		cancel:        cancel,
+		tasks:         make(map[string]*ScrapeTask),
+		storage:       storage,
+		dirty:         make(map[string]bool),
+		client:        client,
+		kept:          make(map[string]bool),
+		maxConcurrent: maxConcurrent,
+		maxQueued:     maxQueued,
 	}
 
 	// 初始化存储
 	storage.Initialize()
 
+	return cs
+}
+
+// Start 从存储加载任务并启动后台工作器，重启前未结束的任务从断点继续
+// 需在 SetAccountService、SetVideoStatService 之后调用，恢复的任务才能使用账号认证和记录快照
+func (cs *CommentService) Start() {
 	// 启动时从存储加载任务
 	cs.loadTasksFromStorage()
 
@@ -84,51 +190,196 @@ func NewCommentService(ctx context.Context, storage storage.TaskStorage) *Commen
 		defer cs.wg.Done()
 		cs.cleanupWorker()
 	}()
+}
 
-	return cs
+// SetVideoStatService 设置视频数据快照服务，设置后视频评论任务每次运行时记录一次快照
+func (cs *CommentService) SetVideoStatService(videoStats *VideoStatService) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.videoStats = videoStats
 }
 
-// StartScrapeTask 启动爬取任务
-func (cs *CommentService) StartScrapeTask(videoID, authType, cookie, appKey, appSecret, sortMode string, includeReplies bool, pageLimit, delayMs int) (string, error) {
+// SetAccountService 设置登录账号服务，设置后任务可通过 account 认证引用已保存的账号
+func (cs *CommentService) SetAccountService(accounts *AccountService) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.accounts = accounts
+}
+
+// accountAuthOptions 获取账号的认证选项，未设置账号服务时按账号不存在处理
+func (cs *CommentService) accountAuthOptions(accountID string) ([]bilibili.CommentOption, error) {
+	cs.mu.RLock()
+	accounts := cs.accounts
+	cs.mu.RUnlock()
+
+	if accounts == nil || accountID == "" {
+		return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, accountID)
+	}
+	return accounts.AuthOptions(accountID)
+}
+
+// authOptions 按认证方式构造请求认证选项，account 认证的账号不可用时返回错误
+func (cs *CommentService) authOptions(authType, cookie, appKey, appSecret, accountID string) ([]bilibili.CommentOption, error) {
+	switch authType {
+	case "cookie":
+		if cookie != "" {
+			return []bilibili.CommentOption{bilibili.WithCookie(cookie)}, nil
+		}
+	case "app":
+		if appKey != "" && appSecret != "" {
+			return []bilibili.CommentOption{bilibili.WithAppAuth(appKey, appSecret)}, nil
+		}
+	case "account":
+		return cs.accountAuthOptions(accountID)
+	}
+	return nil, nil
+}
+
+// saveTaskCredential 加密保存任务的认证信息，返回引用ID，没有认证信息时返回空
+func (cs *CommentService) saveTaskCredential(taskID, cookie, appKey, appSecret string) (string, error) {
+	credential := &storage.CredentialEntry{Cookie: cookie, AppKey: appKey, AppSecret: appSecret}
+	if credential.IsEmpty() {
+		return "", nil
+	}
+
+	credentialID := storage.TaskCredentialID(taskID)
+	if err := cs.storage.SaveCredential(credentialID, credential); err != nil {
+		return "", fmt.Errorf("failed to save credential: %w", err)
+	}
+	return credentialID, nil
+}
+
+// loadTaskCredential 读取任务的认证信息，读取失败时记录日志并按无认证信息处理
+func (cs *CommentService) loadTaskCredential(taskData *storage.TaskData) *storage.CredentialEntry {
+	if taskData.CredentialID == "" {
+		return &storage.CredentialEntry{}
+	}
+
+	credential, err := cs.storage.LoadCredential(taskData.CredentialID)
+	if err != nil {
+		utils.LogWarn(fmt.Sprintf("任务 %s 读取认证信息失败，将不带认证继续: %v", taskData.TaskID, err))
+		return &storage.CredentialEntry{}
+	}
+	return credential
+}
+
+// deleteTaskCredential 删除任务的认证信息
+func (cs *CommentService) deleteTaskCredential(taskID string) {
+	if err := cs.storage.DeleteCredential(storage.TaskCredentialID(taskID)); err != nil {
+		utils.LogError(fmt.Sprintf("删除任务 %s 的认证信息失败: %v", taskID, err))
+	}
+}
+
+// ScrapeSpec 创建爬取任务的参数
+type ScrapeSpec struct {
+	VideoID        string // 评论区目标ID（见 VideoService.ParseCommentTarget）
+	CommentType    int    // 评论区类型（bilibili.CommentType*），为0时按视频处理
+	AuthType       string // none, cookie, app, account
+	Cookie         string
+	AppKey         string
+	AppSecret      string
+	AccountID      string // AuthType 为 account 时使用该账号的Cookie，任务只保存账号ID
+	SortMode       string // 为空时按时间排序
+	IncludeReplies bool
+	PageLimit      int
+	DelayMs        int
+	Priority       int // 越大越先执行
+	Options        ScrapeOptions
+}
+
+// StartScrapeTask 创建爬取任务并加入等待队列，有空闲名额时立即开始执行
+// 队列已满时返回 ErrQueueFull
+func (cs *CommentService) StartScrapeTask(spec ScrapeSpec) (string, error) {
+	if spec.AuthType == "account" {
+		if _, err := cs.accountAuthOptions(spec.AccountID); err != nil {
+			return "", err
+		}
+	}
+
 	taskID := uuid.New().String()
 
+	// 认证信息加密保存，任务文件中只记录引用ID
+	credentialID, err := cs.saveTaskCredential(taskID, spec.Cookie, spec.AppKey, spec.AppSecret)
+	if err != nil {
+		return "", err
+	}
+
 	// 设置默认排序模式
-	if sortMode == "" {
-		sortMode = "time"
+	if spec.SortMode == "" {
+		spec.SortMode = "time"
+	}
+	if spec.CommentType == 0 {
+		spec.CommentType = bilibili.CommentTypeVideo
 	}
 
 	task := &ScrapeTask{
 		TaskID:         taskID,
-		VideoID:        videoID,
-		Status:         "running",
+		VideoID:        spec.VideoID,
+		CommentType:    spec.CommentType,
+		Status:         "queued",
 		Comments:       []bilibili.CommentData{},
-		Progress:       TaskProgress{CurrentPage: 0, TotalComments: 0, PageLimit: pageLimit},
+		Progress:       TaskProgress{CurrentPage: 0, TotalComments: 0, PageLimit: spec.PageLimit},
 		StartTime:      time.Now(),
-		AuthType:       authType,
-		Cookie:         cookie,
-		AppKey:         appKey,
-		AppSecret:      appSecret,
-		PageLimit:      pageLimit,
-		DelayMs:        delayMs,
-		SortMode:       sortMode,
-		IncludeReplies: includeReplies,
+		AuthType:       spec.AuthType,
+		Cookie:         spec.Cookie,
+		AppKey:         spec.AppKey,
+		AppSecret:      spec.AppSecret,
+		CredentialID:   credentialID,
+		AccountID:      spec.AccountID,
+		PageLimit:      spec.PageLimit,
+		DelayMs:        spec.DelayMs,
+		SortMode:       spec.SortMode,
+		IncludeReplies: spec.IncludeReplies,
+		Priority:       spec.Priority,
+		Options:        spec.Options,
+		done:           make(chan struct{}),
 	}
 
 	cs.mu.Lock()
+	if cs.queueFullLocked() {
+		cs.mu.Unlock()
+		cs.deleteTaskCredential(taskID)
+		return "", ErrQueueFull
+	}
 	cs.tasks[taskID] = task
+	cs.enqueueLocked(task)
 	cs.mu.Unlock()
 
 	// 立即持久化新任务
 	go cs.saveTask(task)
 
-	// 在后台执行爬取
+	// 有空闲名额时开始执行
+	cs.dispatch()
+
+	return taskID, nil
+}
+
+// launchTask 在后台执行爬取任务，结束时释放运行名额并启动下一个排队任务
+func (cs *CommentService) launchTask(task *ScrapeTask) {
+	// 任务级 context：用户取消或服务关闭都会终止任务
+	taskCtx, cancel := context.WithCancel(cs.ctx)
+
+	cs.mu.Lock()
+	task.cancel = cancel
+	done := task.done
+	if done == nil {
+		done = make(chan struct{})
+		task.done = done
+	}
+	cs.mu.Unlock()
+
 	cs.wg.Add(1)
 	go func() {
 		defer cs.wg.Done()
-		cs.executeScrapingTask(taskID)
-	}()
+		defer close(done)
+		defer cancel()
+		cs.executeScrapingTask(taskCtx, task.TaskID)
 
-	return taskID, nil
+		cs.mu.Lock()
+		cs.releaseSlotLocked(task)
+		cs.mu.Unlock()
+		cs.dispatch()
+	}()
 }
 
 // GetTaskProgress 获取任务进度
@@ -169,9 +420,11 @@ func (cs *CommentService) GetTaskProgress(taskID string) (*ScrapeTask, error) {
 				TotalComments: foundMeta.CommentCount,
 				PageLimit:     2, // 默认值
 			},
-			StartTime: foundMeta.StartTime,
-			EndTime:   foundMeta.EndTime,
-			Error:     foundMeta.Error,
+			StartTime:    foundMeta.StartTime,
+			EndTime:      foundMeta.EndTime,
+			Error:        foundMeta.Error,
+			LastRefresh:  foundMeta.LastRefresh,
+			RefreshError: foundMeta.RefreshError,
 		}
 
 		// 将任务添加到内存中
@@ -183,7 +436,7 @@ func (cs *CommentService) GetTaskProgress(taskID string) (*ScrapeTask, error) {
 	}
 
 	// 对于 completed 状态的任务，检查评论数据
-	if task.Status == "completed" && (task.Comments == nil || len(task.Comments) == 0) {
+	if task.HasResult() && (task.Comments == nil || len(task.Comments) == 0) {
 		// 需要加载评论数据，直接从存储加载，不持有锁
 		taskData, err := cs.storage.LoadTask(taskID)
 		if err != nil {
@@ -198,6 +451,7 @@ func (cs *CommentService) GetTaskProgress(taskID string) (*ScrapeTask, error) {
 		if task != nil && (task.Comments == nil || len(task.Comments) == 0) {
 			task.Comments = comments
 			task.Progress.TotalComments = len(comments)
+			task.VideoContext = videoContextFromStorage(taskData.VideoContext)
 		}
 		cs.mu.Unlock()
 
@@ -207,6 +461,76 @@ func (cs *CommentService) GetTaskProgress(taskID string) (*ScrapeTask, error) {
 	return task, nil
 }
 
+// TaskStatus 获取任务当前状态（不加载评论数据），任务不存在时返回 false
+func (cs *CommentService) TaskStatus(taskID string) (string, bool) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	task, exists := cs.tasks[taskID]
+	if !exists {
+		return "", false
+	}
+	return task.Status, true
+}
+
+// TaskSummary 任务概要（不含评论数据）
+type TaskSummary struct {
+	TaskID      string
+	VideoID     string
+	VideoTitle  string
+	CommentType int
+	Status      string
+	Error       string
+	Progress    TaskProgress
+}
+
+// GetTaskSummary 获取任务概要（不触发评论懒加载），任务不存在时返回 false
+func (cs *CommentService) GetTaskSummary(taskID string) (*TaskSummary, bool) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	task, exists := cs.tasks[taskID]
+	if !exists {
+		return nil, false
+	}
+	return &TaskSummary{
+		TaskID:      task.TaskID,
+		VideoID:     task.VideoID,
+		VideoTitle:  task.VideoTitle,
+		CommentType: task.CommentType,
+		Status:      task.Status,
+		Error:       task.Error,
+		Progress:    task.Progress,
+	}, true
+}
+
+// WaitTask 等待任务本次运行结束，返回任务最终状态
+// 服务关闭导致的中断同样会返回（此时状态仍为 running）
+func (cs *CommentService) WaitTask(ctx context.Context, taskID string) (string, error) {
+	cs.mu.RLock()
+	task, exists := cs.tasks[taskID]
+	var done chan struct{}
+	if exists {
+		done = task.done
+	}
+	cs.mu.RUnlock()
+
+	if !exists {
+		return "", fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+	}
+
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
+	status, _ := cs.TaskStatus(taskID)
+	return status, nil
+}
+
 // GetAllTasks 获取所有任务（按开始时间降序排序，最新的在前）
 func (cs *CommentService) GetAllTasks() []*ScrapeTask {
 	cs.mu.RLock()
@@ -235,7 +559,7 @@ func (cs *CommentService) GetTaskResult(taskID, sortBy, keyword string, limit in
 		return nil, 0, fmt.Errorf("task not found: %s", taskID)
 	}
 
-	if task.Status != "completed" {
+	if !task.HasResult() {
 		return nil, 0, fmt.Errorf("task not completed yet")
 	}
 
@@ -270,8 +594,61 @@ func (cs *CommentService) GetTaskResult(taskID, sortBy, keyword string, limit in
 	return comments, totalCount, nil
 }
 
+// resultTask 有评论结果的任务及其结果版本
+type resultTask struct {
+	TaskSummary
+	Version string // 结果变化（完成、增量刷新）时随之变化
+}
+
+// resultTasks 获取所有有评论结果的任务（最新的在前）
+func (cs *CommentService) resultTasks() []resultTask {
+	var tasks []resultTask
+	for _, task := range cs.GetAllTasks() {
+		cs.mu.RLock()
+		if task.HasResult() {
+			tasks = append(tasks, resultTask{
+				TaskSummary: TaskSummary{
+					TaskID:      task.TaskID,
+					VideoID:     task.VideoID,
+					VideoTitle:  task.VideoTitle,
+					CommentType: task.CommentType,
+					Status:      task.Status,
+					Error:       task.Error,
+					Progress:    task.Progress,
+				},
+				Version: fmt.Sprintf("%d/%d", task.EndTime.UnixNano(), task.LastRefresh.UnixNano()),
+			})
+		}
+		cs.mu.RUnlock()
+	}
+	return tasks
+}
+
+// loadResultComments 读取任务的评论结果；未加载到内存时直接读取存储，且不缓存到任务中（用于遍历大量任务）
+func (cs *CommentService) loadResultComments(taskID string) ([]bilibili.CommentData, error) {
+	cs.mu.RLock()
+	task, exists := cs.tasks[taskID]
+	if !exists {
+		cs.mu.RUnlock()
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+	}
+	if len(task.Comments) > 0 {
+		comments := make([]bilibili.CommentData, len(task.Comments))
+		copy(comments, task.Comments)
+		cs.mu.RUnlock()
+		return comments, nil
+	}
+	cs.mu.RUnlock()
+
+	taskData, err := cs.storage.LoadTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	return cs.convertFromStorageFormat(taskData.Comments), nil
+}
+
 // executeScrapingTask 执行爬取任务（后台goroutine）
-func (cs *CommentService) executeScrapingTask(taskID string) {
+func (cs *CommentService) executeScrapingTask(taskCtx context.Context, taskID string) {
 	cs.mu.RLock()
 	task := cs.tasks[taskID]
 	cs.mu.RUnlock()
@@ -280,58 +657,117 @@ func (cs *CommentService) executeScrapingTask(taskID string) {
 		return
 	}
 
-	// 首先获取视频信息
-	videoResp, err := bilibili.GetVideoByBVID(task.VideoID)
-	if err != nil {
-		cs.updateTaskError(taskID, fmt.Sprintf("failed to get video info: %v", err))
-		return
+	// 任务级请求统计，记录重试情况
+	stats := &bilibili.RequestStats{}
+	ctx := bilibili.ContextWithStats(taskCtx, stats)
+
+	// 从断点恢复（服务重启后继续抓取）
+	startPage := 1
+	nextCursor := 0
+	nextOffset := ""
+	commentMap := make(map[int64]bilibili.CommentData) // 用于去重
+
+	cs.mu.Lock()
+	if cp := task.checkpoint; cp != nil {
+		startPage = cp.Page + 1
+		nextCursor = cp.NextCursor
+		nextOffset = cp.NextOffset
+		utils.LogInfo(fmt.Sprintf("Resuming scraping task %s from page %d", taskID, startPage))
 	}
+	// 断点恢复或增量刷新时，已有评论作为去重和合并的基础
+	for _, comment := range task.Comments {
+		commentMap[comment.RPID] = comment
+	}
+	task.Comments = nil
+
+	// 增量刷新：记录已有评论的最新发布时间，遇到已有评论即停止
+	// 从断点重试刷新时沿用断点中的基准，commentMap 此时已包含上次合并的新评论
+	refresh := task.Mode == "refresh"
+	latestCtime := 0
+	baseCount := 0 // 不计入 MaxComments 的已有评论数
+	switch {
+	case refresh && task.checkpoint != nil:
+		latestCtime = task.checkpoint.RefreshSince
+		baseCount = task.checkpoint.RefreshBase
+	case refresh:
+		for _, comment := range commentMap {
+			if comment.Ctime > latestCtime {
+				latestCtime = comment.Ctime
+			}
+		}
+		baseCount = len(commentMap)
+	}
+	task.stats = stats
+	task.baseRequests, task.baseRetries = task.Progress.Requests, task.Progress.Retries
+	cs.mu.Unlock()
 
-	if videoResp.Code != 0 {
-		cs.updateTaskError(taskID, fmt.Sprintf("video API error: %s", videoResp.Message))
+	// 首先获取评论区信息（视频、动态、专栏等）
+	target, err := resolveCommentTarget(ctx, cs.client, task.VideoID, task.CommentType)
+	if err != nil {
+		if ctx.Err() != nil {
+			cs.finishCancelledTask(task, commentMap)
+			return
+		}
+		cs.finishTask(task, "failed", err.Error(), commentMap)
 		return
 	}
 
-	// 更新视频标题
+	// 更新标题
 	cs.mu.Lock()
-	task.VideoTitle = videoResp.Data.Title
+	task.VideoTitle = target.Title
+	videoStats := cs.videoStats
 	cs.mu.Unlock()
 
-	// 准备认证选项
-	var opts []bilibili.CommentOption
-	switch task.AuthType {
-	case "cookie":
-		if task.Cookie != "" {
-			opts = append(opts, bilibili.WithCookie(task.Cookie))
-		}
-	case "app":
-		if task.AppKey != "" && task.AppSecret != "" {
-			opts = append(opts, bilibili.WithAppAuth(task.AppKey, task.AppSecret))
+	// 顺带记录视频数据快照（失败不影响抓取）
+	if videoStats != nil && target.Video != nil {
+		if _, err := videoStats.Record(target.Video, StatSourceTask); err != nil {
+			utils.LogError(fmt.Sprintf("任务 %s 记录视频数据快照失败: %v", taskID, err))
 		}
 	}
 
-	// 添加排序模式选项
-	if task.SortMode != "" {
+	// 准备认证选项
+	opts, err := cs.authOptions(task.AuthType, task.Cookie, task.AppKey, task.AppSecret, task.AccountID)
+	if err != nil {
+		cs.finishTask(task, "failed", err.Error(), commentMap)
+		return
+	}
+
+	opts = append(opts, bilibili.WithCommentType(target.Type))
+
+	// 获取视频简介、标签和字幕（增量刷新时沿用已有内容）
+	cs.mu.RLock()
+	hasContext := task.VideoContext != nil
+	cs.mu.RUnlock()
+	if target.Video != nil && !(refresh && hasContext) {
+		videoContext := fetchVideoContext(ctx, cs.client, target.Video, opts)
+		cs.mu.Lock()
+		task.VideoContext = videoContext
+		cs.mu.Unlock()
+	}
+
+	// 添加排序模式选项（增量刷新始终按时间排序，从最新评论往前抓）
+	if refresh {
+		opts = append(opts, bilibili.WithSortMode("time"))
+	} else if task.SortMode != "" {
 		opts = append(opts, bilibili.WithSortMode(task.SortMode))
 	}
 
 	// 爬取评论
-	oid := videoResp.Data.AID
+	oid := target.OID
 	pageSize := 20
-	nextCursor := 0
-	nextOffset := ""
-	commentMap := make(map[int64]bilibili.CommentData) // 用于去重
+	timeOrdered := refresh || task.SortMode != "hot" // 评论是否按发布时间从新到旧返回
+
+	for page := startPage; page <= task.PageLimit; page++ {
+		// 暂停时在页边界等待恢复；等待期间被取消则结束任务
+		if !cs.waitIfPaused(ctx, task) {
+			cs.finishCancelledTask(task, commentMap)
+			return
+		}
 
-	for page := 1; page <= task.PageLimit; page++ {
 		// 检查是否被取消
 		select {
-		case <-cs.ctx.Done():
-			utils.LogInfo("Scraping task cancelled: " + taskID)
-			cs.mu.Lock()
-			task.Status = "cancelled"
-			task.Error = "Task cancelled by shutdown"
-			task.EndTime = time.Now()
-			cs.mu.Unlock()
+		case <-ctx.Done():
+			cs.finishCancelledTask(task, commentMap)
 			return
 		default:
 		}
@@ -341,79 +777,261 @@ func (cs *CommentService) executeScrapingTask(taskID string) {
 		var err error
 
 		if nextOffset != "" {
-			commentsResp, err = bilibili.GetCommentsWithOffset(oid, page, pageSize, nextCursor, nextOffset, opts...)
+			commentsResp, err = cs.client.GetCommentsWithOffset(ctx, oid, page, pageSize, nextCursor, nextOffset, opts...)
 		} else {
-			commentsResp, err = bilibili.GetComments(oid, page, pageSize, nextCursor, opts...)
+			commentsResp, err = cs.client.GetComments(ctx, oid, page, pageSize, nextCursor, opts...)
 		}
 
 		if err != nil {
-			cs.updateTaskError(taskID, fmt.Sprintf("failed to get comments on page %d: %v", page, err))
+			if ctx.Err() != nil {
+				cs.finishCancelledTask(task, commentMap)
+				return
+			}
+			cs.finishTask(task, "failed", fmt.Sprintf("failed to get comments on page %d: %v", page, err), commentMap)
 			return
 		}
 
 		if commentsResp.Code != 0 {
-			cs.updateTaskError(taskID, fmt.Sprintf("comment API error on page %d: %s", page, commentsResp.Message))
+			cs.finishTask(task, "failed", fmt.Sprintf("comment API error on page %d: %s", page, commentsResp.Message), commentMap)
 			return
 		}
 
-		// 添加评论（去重）
-		if commentsResp.Data.Replies != nil {
-			for _, comment := range commentsResp.Data.Replies {
-				// 如果需要获取子评论
-				if task.IncludeReplies && comment.RCount > 0 {
-					// 添加延迟避免请求过快
-					time.Sleep(200 * time.Millisecond)
-
-					// 获取前3条子评论
-					subComments, err := bilibili.GetSubComments(oid, comment.RPID, opts...)
-					if err == nil && len(subComments) > 0 {
-						// 只取前3条
-						if len(subComments) > 3 {
-							subComments = subComments[:3]
-						}
-						comment.Replies = subComments
-					}
+		// 添加评论（去重），不满足停止条件的评论在抓取子评论前跳过
+		reachedKnown := false
+		stopReason := ""
+		for _, comment := range commentsResp.Data.Replies {
+			_, existed := commentMap[comment.RPID]
+			if existed || (refresh && comment.Ctime < latestCtime) {
+				reachedKnown = true
+			}
+
+			if !task.Options.inDateRange(comment.Ctime) {
+				// 按时间排序时后面的评论只会更早，不再继续抓取
+				if timeOrdered && !task.Options.Since.IsZero() && int64(comment.Ctime) < task.Options.Since.Unix() {
+					stopReason = StopReasonSince
+					break
+				}
+				continue
+			}
+			if comment.Like < task.Options.MinLikes {
+				continue
+			}
+			if !existed && task.Options.MaxComments > 0 && len(commentMap)-baseCount >= task.Options.MaxComments {
+				stopReason = StopReasonMaxComments
+				break
+			}
+
+			// 如果需要获取子评论
+			if task.IncludeReplies && comment.RCount > 0 {
+				if replies, ok := cs.fetchReplies(ctx, task, oid, comment.RPID, opts); ok {
+					comment.Replies = replies
+				}
+			}
+			mergeComment(commentMap, comment)
+		}
+
+		// 置顶评论不在普通列表中（只随第一页返回），单独计入且不参与停止条件判断
+		// 放在普通评论之后合并，确保同时出现在两个列表中时保留置顶标记
+		for _, comment := range commentsResp.PinnedReplies() {
+			_, existed := commentMap[comment.RPID]
+			if !task.Options.inDateRange(comment.Ctime) || comment.Like < task.Options.MinLikes {
+				continue
+			}
+			if !existed && task.Options.MaxComments > 0 && len(commentMap)-baseCount >= task.Options.MaxComments {
+				continue
+			}
+			if task.IncludeReplies && comment.RCount > 0 {
+				if replies, ok := cs.fetchReplies(ctx, task, oid, comment.RPID, opts); ok {
+					comment.Replies = replies
 				}
-				commentMap[comment.RPID] = comment
 			}
+			mergeComment(commentMap, comment)
 		}
 
 		// 更新进度
 		cs.mu.Lock()
 		task.Progress.CurrentPage = page
 		task.Progress.TotalComments = len(commentMap)
+		task.Progress.StopReason = stopReason
+		task.syncRequestStats()
 		cs.mu.Unlock()
 
+		// 满足停止条件
+		if stopReason != "" {
+			utils.LogInfo(fmt.Sprintf("Scraping task %s stopped on page %d: %s", taskID, page, stopReason))
+			break
+		}
+
 		// 检查是否有更多评论
 		if commentsResp.Data.Cursor.Next == 0 && commentsResp.Data.Cursor.PaginationReply.NextOffset == "" {
 			break
 		}
 
+		// 增量刷新已追上上次抓取的位置
+		if refresh && reachedKnown {
+			break
+		}
+
 		// 更新游标
 		nextCursor = commentsResp.Data.Cursor.Next
 		nextOffset = commentsResp.Data.Cursor.PaginationReply.NextOffset
 
-		// 延迟下次请求
+		// 记录断点，并定期持久化
+		cs.mu.Lock()
+		task.checkpoint = &storage.TaskCheckpoint{
+			Page:       page,
+			NextCursor: nextCursor,
+			NextOffset: nextOffset,
+			UpdatedAt:  time.Now(),
+		}
+		if refresh {
+			task.checkpoint.RefreshSince = latestCtime
+			task.checkpoint.RefreshBase = baseCount
+		}
+		cs.mu.Unlock()
+		if page%checkpointInterval == 0 {
+			cs.saveCheckpoint(task, commentMap)
+		}
+
+		// 延迟下次请求（关闭时立即中断等待，由下一轮循环处理取消）
 		if task.DelayMs > 0 && page < task.PageLimit {
-			time.Sleep(time.Duration(task.DelayMs) * time.Millisecond)
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Duration(task.DelayMs) * time.Millisecond):
+			}
 		}
 	}
 
+	// 标记任务完成
+	cs.finishTask(task, "completed", "", commentMap)
+}
+
+// fetchReplies 获取根评论的子评论（请求速率由客户端的全局限流器控制）
+// 默认只取前3条；开启 FullReplies 时按页抓取全部子评论，中途失败时保留已获取的部分
+func (cs *CommentService) fetchReplies(ctx context.Context, task *ScrapeTask, oid, root int64, opts []bilibili.CommentOption) ([]bilibili.CommentData, bool) {
+	if !task.Options.FullReplies {
+		subComments, err := cs.client.GetSubComments(ctx, oid, root, opts...)
+		if err != nil || len(subComments) == 0 {
+			return nil, false
+		}
+		// 只取前3条
+		if len(subComments) > 3 {
+			subComments = subComments[:3]
+		}
+		return subComments, true
+	}
+
+	delay := time.Duration(task.Options.ReplyDelayMs) * time.Millisecond
+	subComments, err := cs.client.GetAllSubComments(ctx, oid, root, task.Options.ReplyPageLimit, delay, opts...)
+	if err != nil && ctx.Err() == nil {
+		utils.LogInfo(fmt.Sprintf("Task %s: failed to fetch all replies of comment %d: %v", task.TaskID, root, err))
+	}
+	return subComments, len(subComments) > 0
+}
+
+// getVideo 获取视频信息，支持BV号和带 "av" 前缀的AV号
+func getVideo(ctx context.Context, client *bilibili.BilibiliClient, videoID string) (*bilibili.VideoResponse, error) {
+	if strings.HasPrefix(strings.ToLower(videoID), "av") {
+		if aid, err := strconv.ParseInt(videoID[2:], 10, 64); err == nil {
+			return client.GetVideoByAID(ctx, aid)
+		}
+	}
+	return client.GetVideoByBVID(ctx, videoID)
+}
+
+// mergeComment 将评论合并到结果集
+// 新评论记录首次抓取时间；已有评论更新点赞数等可变字段，保留首次抓取时间
+// 返回评论是否已存在
+func mergeComment(commentMap map[int64]bilibili.CommentData, comment bilibili.CommentData) bool {
+	old, exists := commentMap[comment.RPID]
+	if exists {
+		comment.FirstSeen = old.FirstSeen
+		if len(comment.Replies) == 0 {
+			comment.Replies = old.Replies
+		}
+	} else if comment.FirstSeen == 0 {
+		comment.FirstSeen = time.Now().Unix()
+	}
+	commentMap[comment.RPID] = comment
+	return exists
+}
+
+// finishCancelledTask 结束被取消的任务，保留已抓取的评论
+// 服务关闭导致的中断不会结束任务，而是保存断点，待重启后继续
+func (cs *CommentService) finishCancelledTask(task *ScrapeTask, commentMap map[int64]bilibili.CommentData) {
+	if cs.ctx.Err() != nil {
+		utils.LogInfo("Scraping task interrupted by shutdown, checkpoint saved: " + task.TaskID)
+		cs.saveCheckpoint(task, commentMap)
+		return
+	}
+	utils.LogInfo("Scraping task cancelled: " + task.TaskID)
+	cs.finishTask(task, "cancelled", "Task cancelled by user", commentMap)
+}
+
+// saveCheckpoint 持久化断点和已抓取的评论，任务保持当前状态
+func (cs *CommentService) saveCheckpoint(task *ScrapeTask, commentMap map[int64]bilibili.CommentData) {
+	comments := make([]bilibili.CommentData, 0, len(commentMap))
+	for _, comment := range commentMap {
+		comments = append(comments, comment)
+	}
+
+	cs.mu.Lock()
+	task.Comments = comments // 临时保存，用于持久化
+	cs.mu.Unlock()
+
+	if err := cs.saveTask(task); err != nil {
+		utils.LogError(fmt.Sprintf("保存任务断点失败 %s: %v", task.TaskID, err))
+	}
+
+	cs.mu.Lock()
+	task.Comments = nil
+	cs.mu.Unlock()
+}
+
+// finishTask 将任务置为终止状态并持久化已抓取的评论
+// 失败时同样保存 commentMap 中的评论并保留断点，之后可以通过 ResumeTask 从失败处继续
+// 增量刷新失败时任务保持已完成，原有结果仍可查看，失败原因记录在 RefreshError，断点保留供 RefreshTask 重试
+func (cs *CommentService) finishTask(task *ScrapeTask, status, errMsg string, commentMap map[int64]bilibili.CommentData) {
+	refreshFailed := task.Mode == "refresh" && status == "failed"
+
 	// 将map转为slice
 	comments := make([]bilibili.CommentData, 0, len(commentMap))
 	for _, comment := range commentMap {
 		comments = append(comments, comment)
 	}
 
-	// 标记任务完成
 	cs.mu.Lock()
-	task.Status = "completed"
+	// 暂停后恢复的任务可能仍在队列中，结束时一并移出并释放名额，避免 dispatch 再次启动
+	cs.removeFromQueueLocked(task)
+	cs.releaseSlotLocked(task)
+	if refreshFailed {
+		task.Status = "completed"
+		task.Error = ""
+		task.RefreshError = errMsg
+	} else {
+		task.Status = status
+		task.Error = errMsg
+		task.RefreshError = ""
+	}
 	task.Comments = comments // 临时保存，用于持久化
 	task.Progress.TotalComments = len(comments)
+	task.syncRequestStats()
 	task.EndTime = time.Now()
+	task.cancel = nil
+	task.resumeCh = nil
+	switch {
+	case refreshFailed:
+		task.Mode = ""
+	case status != "failed":
+		task.checkpoint = nil
+		if task.Mode == "refresh" {
+			task.LastRefresh = task.EndTime
+		}
+		task.Mode = ""
+	}
 	cs.mu.Unlock()
 
-	// 立即持久化完成的任务
+	// 立即持久化结束的任务
 	cs.saveTask(task)
 
 	// 持久化后释放内存（懒加载）
@@ -422,21 +1040,212 @@ func (cs *CommentService) executeScrapingTask(taskID string) {
 	cs.mu.Unlock()
 }
 
-// updateTaskError 更新任务错误状态
-func (cs *CommentService) updateTaskError(taskID, errMsg string) {
+// waitIfPaused 任务暂停时阻塞直到恢复
+// 返回 false 表示等待期间任务被取消
+func (cs *CommentService) waitIfPaused(ctx context.Context, task *ScrapeTask) bool {
+	cs.mu.RLock()
+	resumeCh := task.resumeCh
+	cs.mu.RUnlock()
+
+	if resumeCh == nil {
+		return true
+	}
+
+	select {
+	case <-resumeCh:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// RefreshTask 增量刷新已有任务
+// 按时间顺序从最新评论开始抓取，遇到已有评论即停止，新评论和点赞数变化合并到原任务
+// 上一次刷新失败时从其断点继续，补齐失败前未抓取的部分
+// pageLimit <= 0 时使用任务原有的页数限制
+func (cs *CommentService) RefreshTask(taskID string, pageLimit int) error {
+	task, err := cs.GetTaskProgress(taskID)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+	}
+
+	// 懒加载的任务只有元数据，需要从存储读取完整的抓取配置和评论
+	taskData, err := cs.storage.LoadTask(taskID)
+	if err != nil {
+		return fmt.Errorf("failed to load task data: %w", err)
+	}
+
+	if pageLimit <= 0 {
+		pageLimit = taskData.PageLimit
+	}
+	credential := cs.loadTaskCredential(taskData)
+
 	cs.mu.Lock()
-	defer cs.mu.Unlock()
+	if !task.HasResult() {
+		cs.mu.Unlock()
+		return fmt.Errorf("%w: cannot be refreshed in status %s", ErrTaskInvalidState, task.Status)
+	}
+	if cs.queueFullLocked() {
+		cs.mu.Unlock()
+		return ErrQueueFull
+	}
+	task.Mode = "refresh"
+	task.Error = ""
+	task.EndTime = time.Time{}
+	task.Comments = cs.convertFromStorageFormat(taskData.Comments)
+	task.AuthType = taskData.AuthType
+	task.Cookie = credential.Cookie
+	task.AppKey = credential.AppKey
+	task.AppSecret = credential.AppSecret
+	task.CredentialID = taskData.CredentialID
+	task.AccountID = taskData.AccountID
+	task.PageLimit = pageLimit
+	task.DelayMs = taskData.DelayMs
+	task.SortMode = taskData.SortMode
+	task.IncludeReplies = taskData.IncludeReplies
+	task.Options = scrapeOptionsFromStorage(taskData.Options)
+	task.VideoContext = videoContextFromStorage(taskData.VideoContext)
+	task.Progress = TaskProgress{
+		TotalComments: len(taskData.Comments),
+		PageLimit:     pageLimit,
+		Requests:      taskData.Progress.Requests,
+		Retries:       taskData.Progress.Retries,
+	}
+	task.checkpoint = nil
+	if taskData.RefreshError != "" && taskData.Checkpoint != nil {
+		task.checkpoint = taskData.Checkpoint
+		task.Progress.CurrentPage = taskData.Checkpoint.Page
+	}
+	task.done = make(chan struct{})
+	cs.enqueueLocked(task)
+	cs.mu.Unlock()
 
-	if task, exists := cs.tasks[taskID]; exists {
-		task.Status = "failed"
-		task.Error = errMsg
-		task.EndTime = time.Now()
+	// 只更新索引中的状态，评论数据在刷新结束时整体写回
+	go cs.updateIndex()
+
+	cs.dispatch()
+	return nil
+}
+
+// CancelTask 取消排队中、运行中或已暂停的任务，已抓取的评论会被保留
+func (cs *CommentService) CancelTask(taskID string) error {
+	cs.mu.Lock()
+	task, exists := cs.tasks[taskID]
+	if !exists {
+		cs.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+	}
+	if task.Status != "running" && task.Status != "paused" && task.Status != "queued" {
+		cs.mu.Unlock()
+		return fmt.Errorf("%w: cannot be cancelled in status %s", ErrTaskInvalidState, task.Status)
+	}
+	cs.removeFromQueueLocked(task)
+	cancel := task.cancel
+	done := task.done
+	// 持有锁时复制已有评论，避免与正在运行的任务并发读写
+	var commentMap map[int64]bilibili.CommentData
+	if cancel == nil {
+		commentMap = make(map[int64]bilibili.CommentData, len(task.Comments))
+		for _, comment := range task.Comments {
+			commentMap[comment.RPID] = comment
+		}
 	}
+	cs.mu.Unlock()
 
-	// 立即持久化失败的任务
-	if task, exists := cs.tasks[taskID]; exists {
-		go cs.saveTask(task)
+	if cancel != nil {
+		cancel()
+		return nil
+	}
+
+	// 尚未开始执行的任务直接结束（增量刷新保留原有评论）
+	utils.LogInfo("Queued task cancelled: " + taskID)
+	cs.finishTask(task, "cancelled", "Task cancelled by user", commentMap)
+	if done != nil {
+		close(done)
+	}
+	return nil
+}
+
+// PauseTask 暂停运行中的任务，任务在当前页抓取完成后暂停
+func (cs *CommentService) PauseTask(taskID string) error {
+	cs.mu.Lock()
+	task, exists := cs.tasks[taskID]
+	if !exists {
+		cs.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+	}
+	if task.Status != "running" {
+		cs.mu.Unlock()
+		return fmt.Errorf("%w: cannot be paused in status %s", ErrTaskInvalidState, task.Status)
 	}
+	task.Status = "paused"
+	task.resumeCh = make(chan struct{})
+	// 暂停的任务释放运行名额，让排队中的任务先执行
+	cs.releaseSlotLocked(task)
+	cs.mu.Unlock()
+
+	// 只更新索引中的状态，避免用空评论覆盖已保存的断点数据
+	go cs.updateIndex()
+
+	cs.dispatch()
+	return nil
+}
+
+// ResumeTask 恢复已暂停的任务：任务重新排队，获得运行名额后从暂停处继续
+// 失败的任务如果保存了断点（或是增量刷新失败），同样可以恢复，从失败前的位置继续抓取
+func (cs *CommentService) ResumeTask(taskID string) error {
+	cs.mu.Lock()
+	task, exists := cs.tasks[taskID]
+	if !exists {
+		cs.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+	}
+	if task.Status == "failed" {
+		cs.mu.Unlock()
+		return cs.resumeFailedTask(taskID)
+	}
+	if task.Status != "paused" {
+		cs.mu.Unlock()
+		return fmt.Errorf("%w: cannot be resumed in status %s", ErrTaskInvalidState, task.Status)
+	}
+	cs.enqueueLocked(task)
+	cs.mu.Unlock()
+
+	// 只更新索引中的状态，避免用空评论覆盖已保存的断点数据
+	go cs.updateIndex()
+
+	cs.dispatch()
+	return nil
+}
+
+// resumeFailedTask 从存储重新加载失败任务的断点和已抓取的评论，重新排队执行
+func (cs *CommentService) resumeFailedTask(taskID string) error {
+	restored, err := cs.restoreInterruptedTask(storage.TaskMeta{TaskID: taskID, Status: "queued"})
+	if err != nil {
+		return fmt.Errorf("failed to load task data: %w", err)
+	}
+	if restored.checkpoint == nil && restored.Mode != "refresh" {
+		return fmt.Errorf("%w: failed task has no checkpoint to resume from", ErrTaskInvalidState)
+	}
+
+	cs.mu.Lock()
+	if current, exists := cs.tasks[taskID]; !exists || current.Status != "failed" {
+		cs.mu.Unlock()
+		return fmt.Errorf("%w: task %s is no longer failed", ErrTaskInvalidState, taskID)
+	}
+	if cs.queueFullLocked() {
+		cs.mu.Unlock()
+		return ErrQueueFull
+	}
+	cs.tasks[taskID] = restored
+	cs.enqueueLocked(restored)
+	cs.mu.Unlock()
+
+	utils.LogInfo(fmt.Sprintf("Resuming failed task %s from checkpoint", taskID))
+	go cs.updateIndex()
+
+	cs.dispatch()
+	return nil
 }
 
 // SortComments 排序评论
@@ -502,55 +1311,94 @@ func (cs *CommentService) cleanupWorker() {
 	}
 }
 
-// CleanOldTasks 清理旧任务
-func (cs *CommentService) CleanOldTasks() {
+// KeepTask 标记任务被引用（如定时计划的运行历史），清理旧任务时保留
+func (cs *CommentService) KeepTask(taskID string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.kept[taskID] = true
+}
+
+// ReleaseTask 取消 KeepTask 的标记，任务之后按正常规则清理
+func (cs *CommentService) ReleaseTask(taskID string) {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
+	delete(cs.kept, taskID)
+}
+
+// CleanOldTasks 清理旧任务（KeepTask 标记的任务除外）
+func (cs *CommentService) CleanOldTasks() {
+	cs.mu.Lock()
 
 	cutoff := time.Now().Add(-1 * time.Hour)
+	var expired []string
 	for taskID, task := range cs.tasks {
+		if cs.kept[taskID] {
+			continue
+		}
 		if task.EndTime.Before(cutoff) && !task.EndTime.IsZero() {
 			delete(cs.tasks, taskID)
-			// 同时删除存储中的任务
-			cs.storage.DeleteTask(taskID)
+			expired = append(expired, taskID)
 		}
 	}
+	cs.mu.Unlock()
+
+	if len(expired) == 0 {
+		return
+	}
+
+	// 释放锁后再删除存储中的任务和认证信息（updateIndex 需要获取读锁）
+	for _, taskID := range expired {
+		cs.storage.DeleteTask(taskID)
+		cs.deleteTaskCredential(taskID)
+	}
 
 	// 更新索引
 	cs.updateIndex()
 }
 
 // loadTasksFromStorage 从存储加载任务
+// 重启前仍在排队、运行（或暂停）的任务会从断点自动恢复
 func (cs *CommentService) loadTasksFromStorage() {
 	tasks, err := cs.storage.ListTasks()
 	if err != nil {
-		fmt.Printf("加载任务失败: %v\n", err)
+		utils.LogError(fmt.Sprintf("加载任务失败: %v", err))
 		return
 	}
 
-	fmt.Printf("从存储加载 %d 个任务\n", len(tasks))
+	utils.LogInfo(fmt.Sprintf("从存储加载 %d 个任务", len(tasks)))
 
+	var resumable []*ScrapeTask
 	for _, meta := range tasks {
-		// 将 running 状态的任务标记为 failed（重启中断）
-		if meta.Status == "running" {
+		// 尝试从断点恢复被中断的任务，无法恢复的标记为 failed
+		if meta.Status == "running" || meta.Status == "paused" || meta.Status == "queued" {
+			task, err := cs.restoreInterruptedTask(meta)
+			if err == nil {
+				cs.tasks[meta.TaskID] = task
+				resumable = append(resumable, task)
+				continue
+			}
+			utils.LogError(fmt.Sprintf("恢复任务失败 %s: %v", meta.TaskID, err))
 			meta.Status = "failed"
 			meta.Error = "任务被中断（服务器重启）"
 		}
 
 		// 只加载元数据到内存，评论数据懒加载
 		task := &ScrapeTask{
-			TaskID:     meta.TaskID,
-			VideoID:    meta.VideoID,
-			VideoTitle: meta.VideoTitle,
-			Status:     meta.Status,
-			Comments:   nil, // 懒加载
+			TaskID:      meta.TaskID,
+			VideoID:     meta.VideoID,
+			VideoTitle:  meta.VideoTitle,
+			CommentType: commentTypeOrVideo(meta.CommentType),
+			Status:      meta.Status,
+			Comments:    nil, // 懒加载
 			Progress: TaskProgress{
 				TotalComments: meta.CommentCount, // 使用索引中的评论数
 				PageLimit:     2,                 // 默认值
 			},
-			StartTime: meta.StartTime,
-			EndTime:   meta.EndTime,
-			Error:     meta.Error,
+			StartTime:    meta.StartTime,
+			EndTime:      meta.EndTime,
+			Error:        meta.Error,
+			LastRefresh:  meta.LastRefresh,
+			RefreshError: meta.RefreshError,
 		}
 
 		cs.tasks[meta.TaskID] = task
@@ -560,6 +1408,82 @@ func (cs *CommentService) loadTasksFromStorage() {
 	if len(tasks) > 0 {
 		cs.updateIndex()
 	}
+
+	// 重新启动被中断的任务：暂停的任务直接启动并等待恢复，其余任务按创建顺序重新排队
+	sort.Slice(resumable, func(i, j int) bool {
+		return resumable[i].StartTime.Before(resumable[j].StartTime)
+	})
+	for _, task := range resumable {
+		utils.LogInfo(fmt.Sprintf("从断点恢复任务 %s（状态: %s）", task.TaskID, task.Status))
+		if task.Status == "paused" {
+			cs.launchTask(task)
+			continue
+		}
+		cs.mu.Lock()
+		cs.enqueueLocked(task)
+		cs.mu.Unlock()
+	}
+	cs.dispatch()
+}
+
+// restoreInterruptedTask 从存储加载被中断任务的完整数据和断点
+func (cs *CommentService) restoreInterruptedTask(meta storage.TaskMeta) (*ScrapeTask, error) {
+	taskData, err := cs.storage.LoadTask(meta.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	credential := cs.loadTaskCredential(taskData)
+
+	task := &ScrapeTask{
+		TaskID:      taskData.TaskID,
+		VideoID:     taskData.VideoID,
+		VideoTitle:  taskData.VideoTitle,
+		CommentType: commentTypeOrVideo(taskData.CommentType),
+		Status:      meta.Status,
+		Comments:    cs.convertFromStorageFormat(taskData.Comments),
+		Progress: TaskProgress{
+			CurrentPage:   taskData.Progress.CurrentPage,
+			TotalComments: len(taskData.Comments),
+			PageLimit:     taskData.Progress.PageLimit,
+			Requests:      taskData.Progress.Requests,
+			Retries:       taskData.Progress.Retries,
+			StopReason:    taskData.Progress.StopReason,
+		},
+		StartTime:      taskData.StartTime,
+		AuthType:       taskData.AuthType,
+		Cookie:         credential.Cookie,
+		AppKey:         credential.AppKey,
+		AppSecret:      credential.AppSecret,
+		CredentialID:   taskData.CredentialID,
+		AccountID:      taskData.AccountID,
+		PageLimit:      taskData.PageLimit,
+		DelayMs:        taskData.DelayMs,
+		SortMode:       taskData.SortMode,
+		IncludeReplies: taskData.IncludeReplies,
+		Mode:           taskData.Mode,
+		LastRefresh:    taskData.LastRefresh,
+		RefreshError:   taskData.RefreshError,
+		Priority:       taskData.Priority,
+		Options:        scrapeOptionsFromStorage(taskData.Options),
+		VideoContext:   videoContextFromStorage(taskData.VideoContext),
+		checkpoint:     taskData.Checkpoint,
+		done:           make(chan struct{}),
+	}
+
+	// 没有断点说明任务尚未完成第一页，从头开始抓取（增量刷新保留原有评论）
+	if task.checkpoint == nil && task.Mode != "refresh" {
+		task.Comments = nil
+		task.Progress.CurrentPage = 0
+		task.Progress.TotalComments = 0
+	}
+
+	// 暂停的任务恢复后仍保持暂停，等待用户手动继续
+	if task.Status == "paused" {
+		task.resumeCh = make(chan struct{})
+	}
+
+	return task, nil
 }
 
 // persistWorker 后台持久化工作器
@@ -593,15 +1517,17 @@ func (cs *CommentService) persistDirtyTasks() {
 	// 持久化脏任务
 	for _, task := range dirtyTasks {
 		if err := cs.saveTask(task); err != nil {
-			fmt.Printf("持久化任务失败 %s: %v\n", task.TaskID, err)
+			utils.LogError(fmt.Sprintf("持久化任务失败 %s: %v", task.TaskID, err))
 		}
 	}
 }
 
 // saveTask 保存单个任务
 func (cs *CommentService) saveTask(task *ScrapeTask) error {
-	// 转换为存储层格式
+	// 转换为存储层格式（持有读锁，避免与运行中的任务并发读写）
+	cs.mu.RLock()
 	taskData := cs.convertToStorageFormat(task)
+	cs.mu.RUnlock()
 
 	// 保存任务数据
 	if err := cs.storage.SaveTask(taskData); err != nil {
@@ -631,12 +1557,15 @@ func (cs *CommentService) updateIndex() error {
 			TaskID:       task.TaskID,
 			VideoID:      task.VideoID,
 			VideoTitle:   task.VideoTitle,
+			CommentType:  task.CommentType,
 			Status:       task.Status,
 			CommentCount: commentCount,
 			StartTime:    task.StartTime,
 			EndTime:      task.EndTime,
 			DataFile:     task.TaskID + ".json",
 			Error:        task.Error,
+			LastRefresh:  task.LastRefresh,
+			RefreshError: task.RefreshError,
 		}
 		metas = append(metas, meta)
 	}
@@ -657,6 +1586,7 @@ func (cs *CommentService) loadTaskComments(task *ScrapeTask) error {
 
 	// 转换评论数据
 	task.Comments = cs.convertFromStorageFormat(taskData.Comments)
+	task.VideoContext = videoContextFromStorage(taskData.VideoContext)
 	// 更新进度中的评论总数
 	task.Progress.TotalComments = len(task.Comments)
 	return nil
@@ -670,27 +1600,71 @@ func (cs *CommentService) convertToStorageFormat(task *ScrapeTask) *storage.Task
 	}
 
 	return &storage.TaskData{
-		TaskID:     task.TaskID,
-		VideoID:    task.VideoID,
-		VideoTitle: task.VideoTitle,
-		Status:     task.Status,
-		Comments:   comments,
+		TaskID:      task.TaskID,
+		VideoID:     task.VideoID,
+		VideoTitle:  task.VideoTitle,
+		CommentType: task.CommentType,
+		Status:      task.Status,
+		Comments:    comments,
 		Progress: storage.TaskProgressEntry{
 			CurrentPage:   task.Progress.CurrentPage,
 			TotalComments: task.Progress.TotalComments,
 			PageLimit:     task.Progress.PageLimit,
+			Requests:      task.Progress.Requests,
+			Retries:       task.Progress.Retries,
+			StopReason:    task.Progress.StopReason,
 		},
 		StartTime:      task.StartTime,
 		EndTime:        task.EndTime,
 		Error:          task.Error,
 		AuthType:       task.AuthType,
-		Cookie:         task.Cookie,
-		AppKey:         task.AppKey,
-		AppSecret:      task.AppSecret,
+		CredentialID:   task.CredentialID,
+		AccountID:      task.AccountID,
 		PageLimit:      task.PageLimit,
 		DelayMs:        task.DelayMs,
 		SortMode:       task.SortMode,
 		IncludeReplies: task.IncludeReplies,
+		Mode:           task.Mode,
+		LastRefresh:    task.LastRefresh,
+		RefreshError:   task.RefreshError,
+		Priority:       task.Priority,
+		Options:        scrapeOptionsToStorage(task.Options),
+		Checkpoint:     task.checkpoint,
+		VideoContext:   videoContextToStorage(task.VideoContext),
+	}
+}
+
+// scrapeOptionsToStorage 转换爬取选项到存储格式
+func scrapeOptionsToStorage(o ScrapeOptions) storage.ScrapeOptionsEntry {
+	return storage.ScrapeOptionsEntry{
+		FullReplies:    o.FullReplies,
+		ReplyPageLimit: o.ReplyPageLimit,
+		ReplyDelayMs:   o.ReplyDelayMs,
+		Since:          o.Since,
+		Until:          o.Until,
+		MaxComments:    o.MaxComments,
+		MinLikes:       o.MinLikes,
+	}
+}
+
+// commentTypeOrVideo 旧数据未记录评论区类型时按视频处理
+func commentTypeOrVideo(commentType int) int {
+	if commentType == 0 {
+		return bilibili.CommentTypeVideo
+	}
+	return commentType
+}
+
+// scrapeOptionsFromStorage 从存储格式转换爬取选项
+func scrapeOptionsFromStorage(e storage.ScrapeOptionsEntry) ScrapeOptions {
+	return ScrapeOptions{
+		FullReplies:    e.FullReplies,
+		ReplyPageLimit: e.ReplyPageLimit,
+		ReplyDelayMs:   e.ReplyDelayMs,
+		Since:          e.Since,
+		Until:          e.Until,
+		MaxComments:    e.MaxComments,
+		MinLikes:       e.MinLikes,
 	}
 }
 
@@ -725,19 +1699,25 @@ func (cs *CommentService) convertCommentToStorage(c bilibili.CommentData) storag
 		Attr:      c.Attr,
 		Ctime:     c.Ctime,
 		Like:      c.Like,
-		Content: storage.CommentContent{
-			Message: c.Content.Message,
-		},
+		FirstSeen: c.FirstSeen,
+		Content:   commentContentToStorage(c.Content),
 		Member: storage.CommentMember{
-			Mid:    c.Member.Mid,
-			Name:   c.Member.Uname,
-			Sex:    c.Member.Sex,
-			Avatar: c.Member.Avatar,
-			Sign:   c.Member.Sign,
-			Rank:   c.Member.Rank,
-			Level:  c.Member.LevelInfo.CurrentLevel,
+			Mid:       c.Member.Mid,
+			Name:      c.Member.Uname,
+			Sex:       c.Member.Sex,
+			Avatar:    c.Member.Avatar,
+			Sign:      c.Member.Sign,
+			Rank:      c.Member.Rank,
+			Level:     c.Member.LevelInfo.CurrentLevel,
+			VipType:   c.Member.Vip.VipType,
+			VipStatus: c.Member.Vip.VipStatus,
+			VipLabel:  c.Member.Vip.Label.Text,
 		},
-		Replies: replies,
+		Replies:  replies,
+		Location: c.ReplyControl.Location,
+		UpLike:   c.UpAction.Like,
+		UpReply:  c.UpAction.Reply,
+		IsTop:    c.IsTop,
 	}
 }
 
@@ -748,6 +1728,21 @@ func (cs *CommentService) convertCommentFromStorage(e storage.CommentEntry) bili
 		replies[i] = cs.convertCommentFromStorage(r)
 	}
 
+	member := bilibili.CommentMember{
+		Mid:    e.Member.Mid,
+		Uname:  e.Member.Name,
+		Sex:    e.Member.Sex,
+		Avatar: e.Member.Avatar,
+		Sign:   e.Member.Sign,
+		Rank:   e.Member.Rank,
+		Vip: bilibili.CommentVip{
+			VipType:   e.Member.VipType,
+			VipStatus: e.Member.VipStatus,
+		},
+	}
+	member.LevelInfo.CurrentLevel = e.Member.Level
+	member.Vip.Label.Text = e.Member.VipLabel
+
 	return bilibili.CommentData{
 		RPID:      e.RPID,
 		OID:       e.OID,
@@ -763,19 +1758,91 @@ func (cs *CommentService) convertCommentFromStorage(e storage.CommentEntry) bili
 		Attr:      e.Attr,
 		Ctime:     e.Ctime,
 		Like:      e.Like,
-		Content: bilibili.CommentContent{
-			Message: e.Content.Message,
+		FirstSeen: e.FirstSeen,
+		Content:   commentContentFromStorage(e.Content),
+		Member:    member,
+		Replies:   replies,
+		ReplyControl: bilibili.ReplyControl{
+			Location: e.Location,
 		},
-		Member: bilibili.CommentMember{
-			Mid:    e.Member.Mid,
-			Uname:  e.Member.Name,
-			Sex:    e.Member.Sex,
-			Avatar: e.Member.Avatar,
-			Sign:   e.Member.Sign,
-			Rank:   e.Member.Rank,
+		UpAction: bilibili.UpAction{
+			Like:  e.UpLike,
+			Reply: e.UpReply,
 		},
-		Replies: replies,
+		IsTop: e.IsTop,
+	}
+}
+
+// commentContentToStorage 转换评论内容（含表情、跳转链接和图片）到存储格式
+func commentContentToStorage(c bilibili.CommentContent) storage.CommentContent {
+	content := storage.CommentContent{Message: c.Message}
+
+	if len(c.Emote) > 0 {
+		content.Emote = make(map[string]storage.CommentEmote, len(c.Emote))
+		for key, emote := range c.Emote {
+			content.Emote[key] = storage.CommentEmote{
+				ID:        emote.ID,
+				PackageID: emote.PackageID,
+				Type:      emote.Type,
+				Text:      emote.Text,
+				URL:       emote.URL,
+			}
+		}
+	}
+
+	if len(c.JumpUrl) > 0 {
+		content.JumpURL = make(map[string]storage.CommentJumpURL, len(c.JumpUrl))
+		for key, jump := range c.JumpUrl {
+			content.JumpURL[key] = storage.CommentJumpURL{Title: jump.Title, State: jump.State}
+		}
+	}
+
+	for _, pic := range c.Pictures {
+		content.Pictures = append(content.Pictures, storage.CommentPicture{
+			Src:    pic.ImgSrc,
+			Width:  pic.ImgWidth,
+			Height: pic.ImgHeight,
+			SizeKB: pic.ImgSize,
+		})
 	}
+
+	return content
+}
+
+// commentContentFromStorage 从存储格式转换评论内容
+func commentContentFromStorage(e storage.CommentContent) bilibili.CommentContent {
+	content := bilibili.CommentContent{Message: e.Message}
+
+	if len(e.Emote) > 0 {
+		content.Emote = make(map[string]bilibili.Emote, len(e.Emote))
+		for key, emote := range e.Emote {
+			content.Emote[key] = bilibili.Emote{
+				ID:        emote.ID,
+				PackageID: emote.PackageID,
+				Type:      emote.Type,
+				Text:      emote.Text,
+				URL:       emote.URL,
+			}
+		}
+	}
+
+	if len(e.JumpURL) > 0 {
+		content.JumpUrl = make(map[string]bilibili.JumpUrl, len(e.JumpURL))
+		for key, jump := range e.JumpURL {
+			content.JumpUrl[key] = bilibili.JumpUrl{Title: jump.Title, State: jump.State}
+		}
+	}
+
+	for _, pic := range e.Pictures {
+		content.Pictures = append(content.Pictures, bilibili.CommentPicture{
+			ImgSrc:    pic.Src,
+			ImgWidth:  pic.Width,
+			ImgHeight: pic.Height,
+			ImgSize:   pic.SizeKB,
+		})
+	}
+
+	return content
 }
 
 // markDirty 标记任务为脏数据
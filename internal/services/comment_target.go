@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"bilibili/pkg/bilibili"
+)
+
+// commentTarget 评论区信息（每次运行任务时解析）
+type commentTarget struct {
+	OID   int64  // 评论接口的 oid
+	Type  int    // 评论接口的 type（动态可能解析为图片动态或视频评论区）
+	Title string // 视频标题、专栏标题或动态摘要
+
+	Video *bilibili.VideoInfo // 视频评论区的视频信息，其他类型为 nil
+}
+
+// dynamicTitleRunes 动态正文作为标题时保留的字数
+const dynamicTitleRunes = 30
+
+// resolveCommentTarget 根据目标ID和评论区类型获取评论区的 oid 和标题
+func resolveCommentTarget(ctx context.Context, client *bilibili.BilibiliClient, targetID string, commentType int) (*commentTarget, error) {
+	switch commentType {
+	case 0, bilibili.CommentTypeVideo:
+		videoResp, err := getVideo(ctx, client, targetID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get video info: %w", err)
+		}
+		return &commentTarget{OID: videoResp.Data.AID, Type: bilibili.CommentTypeVideo, Title: videoResp.Data.Title, Video: &videoResp.Data}, nil
+
+	case bilibili.CommentTypeDynamic:
+		// 不同类型动态的评论区不同，需通过动态详情获取
+		dynamicResp, err := client.GetDynamicDetail(ctx, targetID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get dynamic detail: %w", err)
+		}
+		item := dynamicResp.Data.Item
+		oid, err := strconv.ParseInt(item.Basic.CommentIDStr, 10, 64)
+		if err != nil || item.Basic.CommentType == 0 {
+			return nil, fmt.Errorf("dynamic %s has no comment area", targetID)
+		}
+		return &commentTarget{OID: oid, Type: item.Basic.CommentType, Title: dynamicTitle(&item, targetID)}, nil
+
+	case bilibili.CommentTypeArticle:
+		cvid, err := parseTargetNumber(targetID, "cv")
+		if err != nil {
+			return nil, err
+		}
+		articleResp, err := client.GetArticleInfo(ctx, cvid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get article info: %w", err)
+		}
+		return &commentTarget{OID: cvid, Type: commentType, Title: articleResp.Data.Title}, nil
+
+	default:
+		oid, err := parseTargetNumber(targetID, "au")
+		if err != nil {
+			return nil, err
+		}
+		return &commentTarget{OID: oid, Type: commentType, Title: bilibili.CommentTypeName(commentType) + " " + targetID}, nil
+	}
+}
+
+// parseTargetNumber 解析目标ID中的数字部分（去掉 cv、au 等前缀）
+func parseTargetNumber(targetID, prefix string) (int64, error) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(strings.ToLower(targetID), prefix), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid target ID: %s", targetID)
+	}
+	return id, nil
+}
+
+// dynamicTitle 用作者和正文开头作为动态标题
+func dynamicTitle(item *bilibili.DynamicItem, dynamicID string) string {
+	text := []rune(strings.Join(strings.Fields(item.Text()), " "))
+	if len(text) > dynamicTitleRunes {
+		text = append(text[:dynamicTitleRunes], []rune("...")...)
+	}
+
+	author := item.Modules.ModuleAuthor.Name
+	switch {
+	case author != "" && len(text) > 0:
+		return author + "的动态: " + string(text)
+	case author != "":
+		return author + "的动态 " + dynamicID
+	default:
+		return "动态 " + dynamicID
+	}
+}
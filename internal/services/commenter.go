@@ -0,0 +1,335 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"bilibili/pkg/bilibili"
+	"bilibili/pkg/utils"
+)
+
+// ErrCommenterNotFound 已抓取的评论中没有该用户
+var ErrCommenterNotFound = errors.New("commenter not found")
+
+// CommenterService 评论者索引服务，汇总所有已抓取任务中每个用户（mid）的评论情况
+// 索引在查询时按需构建，任务结果变化（新任务完成、增量刷新、清理）后自动重建
+type CommenterService struct {
+	commentService *CommentService
+
+	mu         sync.Mutex
+	version    string // 构建索引时各任务结果版本的签名
+	commenters map[int64]*commenterEntry
+	builtAt    time.Time
+}
+
+// CommenterStats 评论者汇总数据
+type CommenterStats struct {
+	Mid          int64     `json:"mid"`
+	Name         string    `json:"name"`  // 最近一条评论使用的昵称
+	Level        int       `json:"level"` // 最近一条评论时的等级
+	Avatar       string    `json:"avatar"`
+	CommentCount int       `json:"comment_count"` // 评论总数（含回复）
+	ReplyCount   int       `json:"reply_count"`   // 其中的回复数
+	TotalLikes   int       `json:"total_likes"`   // 获得的点赞总数
+	VideoCount   int       `json:"video_count"`   // 评论过的视频（评论区）数
+	FirstSeen    time.Time `json:"first_seen"`    // 最早一条评论的发布时间
+	LastSeen     time.Time `json:"last_seen"`     // 最近一条评论的发布时间
+}
+
+// CommenterVideo 评论者评论过的视频（评论区）
+type CommenterVideo struct {
+	VideoID      string `json:"video_id"`
+	VideoTitle   string `json:"video_title"`
+	CommentType  int    `json:"comment_type"`
+	CommentCount int    `json:"comment_count"`
+	TotalLikes   int    `json:"total_likes"`
+}
+
+// CommenterProfile 评论者详情
+type CommenterProfile struct {
+	CommenterStats
+	Videos []CommenterVideo `json:"videos"` // 按评论数降序
+}
+
+// CommenterComment 评论者的一条评论
+type CommenterComment struct {
+	TaskID      string    `json:"task_id"`
+	VideoID     string    `json:"video_id"`
+	VideoTitle  string    `json:"video_title"`
+	CommentType int       `json:"comment_type"`
+	RPID        int64     `json:"rpid"`
+	Root        int64     `json:"root,omitempty"` // 回复所属的根评论
+	IsReply     bool      `json:"is_reply"`
+	Message     string    `json:"message"`
+	Like        int       `json:"like"`
+	Ctime       time.Time `json:"ctime"`
+}
+
+// commenterEntry 索引中的单个评论者
+type commenterEntry struct {
+	stats  CommenterStats
+	videos map[string]*CommenterVideo // key 为评论区类型和 oid
+	tasks  []string                   // 包含该用户评论的任务
+}
+
+// NewCommenterService 创建评论者索引服务
+func NewCommenterService(commentService *CommentService) *CommenterService {
+	return &CommenterService{
+		commentService: commentService,
+	}
+}
+
+// ListCommenters 获取评论者列表
+// sortBy: comments(评论数，默认), likes(点赞数), videos(视频数), last_seen(最近评论)；keyword 按昵称或UID筛选
+func (s *CommenterService) ListCommenters(sortBy, keyword string, offset, limit int) ([]CommenterStats, int) {
+	s.mu.Lock()
+	s.ensureIndex()
+	list := make([]CommenterStats, 0, len(s.commenters))
+	for _, entry := range s.commenters {
+		if keyword != "" && !strings.Contains(entry.stats.Name, keyword) && fmt.Sprintf("%d", entry.stats.Mid) != keyword {
+			continue
+		}
+		list = append(list, entry.stats)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		switch sortBy {
+		case "likes":
+			if a.TotalLikes != b.TotalLikes {
+				return a.TotalLikes > b.TotalLikes
+			}
+		case "videos":
+			if a.VideoCount != b.VideoCount {
+				return a.VideoCount > b.VideoCount
+			}
+		case "last_seen":
+			if !a.LastSeen.Equal(b.LastSeen) {
+				return a.LastSeen.After(b.LastSeen)
+			}
+		}
+		if a.CommentCount != b.CommentCount {
+			return a.CommentCount > b.CommentCount
+		}
+		return a.Mid < b.Mid
+	})
+
+	start, end := pageRange(len(list), offset, limit)
+	return list[start:end], len(list)
+}
+
+// GetCommenter 获取评论者详情
+func (s *CommenterService) GetCommenter(mid int64) (*CommenterProfile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ensureIndex()
+	entry, exists := s.commenters[mid]
+	if !exists {
+		return nil, fmt.Errorf("%w: %d", ErrCommenterNotFound, mid)
+	}
+
+	profile := &CommenterProfile{
+		CommenterStats: entry.stats,
+		Videos:         make([]CommenterVideo, 0, len(entry.videos)),
+	}
+	for _, video := range entry.videos {
+		profile.Videos = append(profile.Videos, *video)
+	}
+	sort.Slice(profile.Videos, func(i, j int) bool {
+		if profile.Videos[i].CommentCount != profile.Videos[j].CommentCount {
+			return profile.Videos[i].CommentCount > profile.Videos[j].CommentCount
+		}
+		return profile.Videos[i].VideoID < profile.Videos[j].VideoID
+	})
+
+	return profile, nil
+}
+
+// GetCommenterComments 获取评论者在所有已抓取任务中的评论（最新的在前）
+// 同一评论区被多个任务抓取时，同一条评论只返回一次
+func (s *CommenterService) GetCommenterComments(mid int64, offset, limit int) ([]CommenterComment, int, error) {
+	s.mu.Lock()
+	s.ensureIndex()
+	entry, exists := s.commenters[mid]
+	var taskIDs []string
+	if exists {
+		taskIDs = append(taskIDs, entry.tasks...)
+	}
+	s.mu.Unlock()
+
+	if !exists {
+		return nil, 0, fmt.Errorf("%w: %d", ErrCommenterNotFound, mid)
+	}
+
+	var history []CommenterComment
+	seen := make(map[int64]bool)
+	for _, taskID := range taskIDs {
+		summary, ok := s.commentService.GetTaskSummary(taskID)
+		if !ok {
+			continue
+		}
+		comments, err := s.commentService.loadResultComments(taskID)
+		if err != nil {
+			continue
+		}
+		walkComments(comments, func(c bilibili.CommentData) {
+			if c.Mid != mid || seen[c.RPID] {
+				return
+			}
+			seen[c.RPID] = true
+			history = append(history, CommenterComment{
+				TaskID:      taskID,
+				VideoID:     summary.VideoID,
+				VideoTitle:  summary.VideoTitle,
+				CommentType: commentTypeOrVideo(summary.CommentType),
+				RPID:        c.RPID,
+				Root:        c.Root,
+				IsReply:     c.Root != 0,
+				Message:     c.Content.Message,
+				Like:        c.Like,
+				Ctime:       time.Unix(int64(c.Ctime), 0),
+			})
+		})
+	}
+
+	sort.Slice(history, func(i, j int) bool {
+		if !history[i].Ctime.Equal(history[j].Ctime) {
+			return history[i].Ctime.After(history[j].Ctime)
+		}
+		return history[i].RPID > history[j].RPID
+	})
+
+	start, end := pageRange(len(history), offset, limit)
+	return history[start:end], len(history), nil
+}
+
+// IndexInfo 索引概况：评论者数量和构建时间
+func (s *CommenterService) IndexInfo() (int, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ensureIndex()
+	return len(s.commenters), s.builtAt
+}
+
+// ensureIndex 任务结果有变化时重建索引（调用方需持有锁）
+func (s *CommenterService) ensureIndex() {
+	tasks := s.commentService.resultTasks()
+
+	versions := make([]string, len(tasks))
+	for i, task := range tasks {
+		versions[i] = task.TaskID + "@" + task.Version
+	}
+	sort.Strings(versions)
+	version := strings.Join(versions, ",")
+
+	if s.commenters != nil && version == s.version {
+		return
+	}
+
+	start := time.Now()
+	commenters := make(map[int64]*commenterEntry)
+	seen := make(map[int64]bool) // 多个任务抓取同一评论区时按 rpid 去重
+	for _, task := range tasks {
+		comments, err := s.commentService.loadResultComments(task.TaskID)
+		if err != nil {
+			utils.LogError(fmt.Sprintf("评论者索引加载任务 %s 失败: %v", task.TaskID, err))
+			continue
+		}
+
+		commentType := commentTypeOrVideo(task.CommentType)
+		walkComments(comments, func(c bilibili.CommentData) {
+			if c.Mid == 0 || seen[c.RPID] {
+				return
+			}
+			seen[c.RPID] = true
+
+			entry, exists := commenters[c.Mid]
+			if !exists {
+				entry = &commenterEntry{
+					stats:  CommenterStats{Mid: c.Mid},
+					videos: make(map[string]*CommenterVideo),
+				}
+				commenters[c.Mid] = entry
+			}
+			// 同一视频可能分别以BV号和AV号抓取，按评论区 oid 归并
+			videoKey := fmt.Sprintf("%d:%s", commentType, task.VideoID)
+			if c.OID != 0 {
+				videoKey = fmt.Sprintf("%d:%d", commentType, c.OID)
+			}
+			entry.add(c, task, commentType, videoKey)
+		})
+	}
+
+	s.commenters = commenters
+	s.version = version
+	s.builtAt = time.Now()
+	utils.LogInfo(fmt.Sprintf("评论者索引已重建：%d个任务，%d位评论者，耗时%v", len(tasks), len(commenters), time.Since(start)))
+}
+
+// add 将一条评论计入评论者的汇总数据
+func (e *commenterEntry) add(c bilibili.CommentData, task resultTask, commentType int, videoKey string) {
+	ctime := time.Unix(int64(c.Ctime), 0)
+	stats := &e.stats
+
+	stats.CommentCount++
+	if c.Root != 0 {
+		stats.ReplyCount++
+	}
+	stats.TotalLikes += c.Like
+	if stats.FirstSeen.IsZero() || ctime.Before(stats.FirstSeen) {
+		stats.FirstSeen = ctime
+	}
+	if !ctime.Before(stats.LastSeen) {
+		stats.LastSeen = ctime
+		stats.Name = c.Member.Uname
+		stats.Level = c.Member.LevelInfo.CurrentLevel
+		stats.Avatar = c.Member.Avatar
+	}
+
+	video, exists := e.videos[videoKey]
+	if !exists {
+		video = &CommenterVideo{
+			VideoID:     task.VideoID,
+			VideoTitle:  task.VideoTitle,
+			CommentType: commentType,
+		}
+		e.videos[videoKey] = video
+		stats.VideoCount = len(e.videos)
+	}
+	video.CommentCount++
+	video.TotalLikes += c.Like
+
+	if len(e.tasks) == 0 || e.tasks[len(e.tasks)-1] != task.TaskID {
+		e.tasks = append(e.tasks, task.TaskID)
+	}
+}
+
+// walkComments 遍历评论及其所有子评论
+func walkComments(comments []bilibili.CommentData, fn func(bilibili.CommentData)) {
+	for _, c := range comments {
+		fn(c)
+		walkComments(c.Replies, fn)
+	}
+}
+
+// pageRange 按 offset/limit 计算截取范围，limit<=0 表示不限制
+func pageRange(total, offset, limit int) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+	return offset, end
+}
@@ -0,0 +1,682 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"bilibili/pkg/bilibili"
+	"bilibili/pkg/storage"
+	"bilibili/pkg/utils"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrDanmakuTaskNotFound 弹幕任务不存在
+	ErrDanmakuTaskNotFound = errors.New("danmaku task not found")
+	// ErrDanmakuTaskInvalidState 弹幕任务当前状态不允许执行该操作
+	ErrDanmakuTaskInvalidState = errors.New("invalid danmaku task state")
+	// ErrInvalidDanmakuSource 不支持的弹幕来源
+	ErrInvalidDanmakuSource = errors.New("invalid danmaku source")
+)
+
+// 弹幕来源
+const (
+	DanmakuSourceProtobuf = "protobuf" // 分段接口，可获取全部弹幕
+	DanmakuSourceXML      = "xml"      // XML接口，只返回弹幕池中最新的一部分
+)
+
+// DanmakuService 弹幕服务，管理弹幕抓取任务
+type DanmakuService struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.RWMutex
+	tasks   map[string]*DanmakuTask
+	slots   chan struct{} // 运行名额，限制同时运行的任务数
+	storage storage.DanmakuStorage
+	client  *bilibili.BilibiliClient
+}
+
+// DanmakuTask 弹幕抓取任务
+type DanmakuTask struct {
+	TaskID     string
+	VideoID    string
+	VideoTitle string
+	Page       int    // 目标分P序号，AllPages 表示全部分P
+	CID        int64  // 目标分P的cid（全部分P时为0）
+	Duration   int    // 所选分P的总时长（秒）
+	Source     string // protobuf, xml
+	Status     string // queued, running, completed, failed, cancelled
+	Danmaku    []bilibili.Danmaku
+	Progress   DanmakuProgress
+	StartTime  time.Time
+	EndTime    time.Time
+	Error      string
+
+	cancel context.CancelFunc // 取消任务（仅运行期间有效）
+}
+
+// DanmakuProgress 弹幕任务进度
+type DanmakuProgress struct {
+	CurrentPage    int `json:"current_page"`    // 正在抓取的分P序号
+	TotalPages     int `json:"total_pages"`     // 需要抓取的分P数量
+	CurrentSegment int `json:"current_segment"` // 已抓取的分段数（所有分P累计）
+	TotalSegments  int `json:"total_segments"`
+	TotalDanmaku   int `json:"total_danmaku"`
+}
+
+// HasResult 任务是否有可查看的弹幕结果（已完成或被取消时保留的部分结果）
+func (t *DanmakuTask) HasResult() bool {
+	return t.Status == "completed" || t.Status == "cancelled"
+}
+
+// summary 复制任务概要（不含弹幕数据），避免调用方与抓取 goroutine 并发读写
+func (t *DanmakuTask) summary() *DanmakuTask {
+	copied := *t
+	copied.Danmaku = nil
+	copied.cancel = nil
+	return &copied
+}
+
+// NewDanmakuService 创建弹幕服务
+// maxConcurrent 为同时运行的最大任务数（<=0 时使用默认值），超出的任务排队等待
+func NewDanmakuService(ctx context.Context, danmakuStorage storage.DanmakuStorage, client *bilibili.BilibiliClient, maxConcurrent int) *DanmakuService {
+	serviceCtx, cancel := context.WithCancel(ctx)
+
+	if client == nil {
+		client = bilibili.DefaultClient()
+	}
+	if maxConcurrent <= 0 {
+		maxConcurrent = DefaultMaxConcurrentTasks
+	}
+
+	ds := &DanmakuService{
+		ctx:     serviceCtx,
+		cancel:  cancel,
+		tasks:   make(map[string]*DanmakuTask),
+		slots:   make(chan struct{}, maxConcurrent),
+		storage: danmakuStorage,
+		client:  client,
+	}
+
+	ds.loadTasks()
+
+	return ds
+}
+
+// StartDanmakuTask 创建弹幕抓取任务并在后台执行，没有空闲名额时排队等待
+// source 为空时使用分段 protobuf 接口；page 为分P序号，AllPages 表示抓取全部分P
+func (ds *DanmakuService) StartDanmakuTask(videoID, source string, page int) (string, error) {
+	if source == "" {
+		source = DanmakuSourceProtobuf
+	}
+	if source != DanmakuSourceProtobuf && source != DanmakuSourceXML {
+		return "", fmt.Errorf("%w: %s", ErrInvalidDanmakuSource, source)
+	}
+	if page < 0 {
+		return "", fmt.Errorf("%w: P%d", ErrVideoPageNotFound, page)
+	}
+
+	taskCtx, cancel := context.WithCancel(ds.ctx)
+	task := &DanmakuTask{
+		TaskID:    uuid.New().String(),
+		VideoID:   videoID,
+		Page:      page,
+		Source:    source,
+		Status:    "queued",
+		StartTime: time.Now(),
+		cancel:    cancel,
+	}
+
+	ds.mu.Lock()
+	ds.tasks[task.TaskID] = task
+	ds.mu.Unlock()
+
+	ds.saveIndex()
+
+	ds.wg.Add(1)
+	go func() {
+		defer ds.wg.Done()
+		defer cancel()
+
+		// 等待运行名额，排队期间可以取消
+		select {
+		case ds.slots <- struct{}{}:
+		case <-taskCtx.Done():
+			ds.finishTask(task, "cancelled", "Task cancelled by user", nil)
+			return
+		}
+		defer func() { <-ds.slots }()
+
+		ds.mu.Lock()
+		task.Status = "running"
+		ds.mu.Unlock()
+		ds.saveIndex()
+
+		ds.executeDanmakuTask(taskCtx, task)
+	}()
+
+	return task.TaskID, nil
+}
+
+// executeDanmakuTask 执行弹幕抓取任务（后台goroutine）
+func (ds *DanmakuService) executeDanmakuTask(ctx context.Context, task *DanmakuTask) {
+	videoResp, err := getVideo(ctx, ds.client, task.VideoID)
+	if err != nil {
+		if ctx.Err() != nil {
+			ds.finishTask(task, "cancelled", "Task cancelled by user", nil)
+			return
+		}
+		ds.finishTask(task, "failed", fmt.Sprintf("failed to get video info: %v", err), nil)
+		return
+	}
+
+	pages, err := selectVideoPages(&videoResp.Data, task.Page)
+	if err != nil {
+		ds.finishTask(task, "failed", err.Error(), nil)
+		return
+	}
+
+	// XML接口每个分P只需一次请求
+	segments := 0
+	duration := 0
+	for _, page := range pages {
+		if task.Source == DanmakuSourceProtobuf {
+			segments += bilibili.DanmakuSegmentCount(page.Duration)
+		} else {
+			segments++
+		}
+		duration += page.Duration
+	}
+
+	ds.mu.Lock()
+	task.VideoTitle = videoResp.Data.Title
+	if len(pages) == 1 {
+		task.CID = pages[0].CID
+	}
+	task.Duration = duration
+	task.Progress.TotalPages = len(pages)
+	task.Progress.TotalSegments = segments
+	ds.mu.Unlock()
+
+	// 逐个分P抓取（请求速率由客户端的全局限流器控制），按弹幕ID去重
+	var danmaku []bilibili.Danmaku
+	seen := make(map[int64]bool)
+	done := 0
+	for _, page := range pages {
+		ds.mu.Lock()
+		task.Progress.CurrentPage = page.Page
+		ds.mu.Unlock()
+
+		pageSegments := 1
+		if task.Source == DanmakuSourceProtobuf {
+			pageSegments = bilibili.DanmakuSegmentCount(page.Duration)
+		}
+
+		for i := 1; i <= pageSegments; i++ {
+			var elems []bilibili.Danmaku
+			if task.Source == DanmakuSourceXML {
+				elems, err = ds.client.GetDanmakuXML(ctx, page.CID)
+			} else {
+				elems, err = ds.client.GetDanmakuSegment(ctx, page.CID, i)
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					ds.finishTask(task, "cancelled", "Task cancelled by user", danmaku)
+					return
+				}
+				ds.finishTask(task, "failed", fmt.Sprintf("failed to get danmaku of P%d segment %d: %v", page.Page, i, err), nil)
+				return
+			}
+			for _, d := range elems {
+				if !seen[d.ID] {
+					seen[d.ID] = true
+					d.Page = page.Page
+					danmaku = append(danmaku, d)
+				}
+			}
+			done++
+			ds.updateProgress(task, done, len(danmaku))
+		}
+	}
+
+	ds.finishTask(task, "completed", "", danmaku)
+}
+
+// updateProgress 更新任务进度
+func (ds *DanmakuService) updateProgress(task *DanmakuTask, segment, total int) {
+	ds.mu.Lock()
+	task.Progress.CurrentSegment = segment
+	task.Progress.TotalDanmaku = total
+	ds.mu.Unlock()
+}
+
+// finishTask 将任务置为终止状态并持久化弹幕数据
+func (ds *DanmakuService) finishTask(task *DanmakuTask, status, errMsg string, danmaku []bilibili.Danmaku) {
+	// 服务关闭导致的中断按失败处理，重启后不会自动恢复
+	if status == "cancelled" && ds.ctx.Err() != nil {
+		status, errMsg = "failed", "任务被中断（服务器关闭）"
+	}
+
+	// 按分P和视频时间排序
+	sort.SliceStable(danmaku, func(i, j int) bool {
+		if danmaku[i].Page != danmaku[j].Page {
+			return danmaku[i].Page < danmaku[j].Page
+		}
+		return danmaku[i].Progress < danmaku[j].Progress
+	})
+
+	ds.mu.Lock()
+	task.Status = status
+	task.Error = errMsg
+	task.Danmaku = danmaku
+	task.Progress.TotalDanmaku = len(danmaku)
+	task.EndTime = time.Now()
+	task.cancel = nil
+	data := convertDanmakuTaskToStorage(task)
+	ds.mu.Unlock()
+
+	if err := ds.storage.SaveDanmakuTask(data); err != nil {
+		utils.LogError(fmt.Sprintf("Failed to save danmaku task %s: %v", task.TaskID, err))
+	}
+	ds.saveIndex()
+
+	// 持久化后释放内存（懒加载）
+	ds.mu.Lock()
+	task.Danmaku = nil
+	ds.mu.Unlock()
+
+	utils.LogInfo(fmt.Sprintf("Danmaku task %s %s with %d danmaku", task.TaskID, status, len(danmaku)))
+}
+
+// GetTask 获取任务概要（不含弹幕数据）
+func (ds *DanmakuService) GetTask(taskID string) (*DanmakuTask, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	task, exists := ds.tasks[taskID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrDanmakuTaskNotFound, taskID)
+	}
+	return task.summary(), nil
+}
+
+// ListTasks 获取所有任务概要（最新的在前）
+func (ds *DanmakuService) ListTasks() []*DanmakuTask {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	tasks := make([]*DanmakuTask, 0, len(ds.tasks))
+	for _, task := range ds.tasks {
+		tasks = append(tasks, task.summary())
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].StartTime.After(tasks[j].StartTime)
+	})
+
+	return tasks
+}
+
+// CancelTask 取消排队中或运行中的任务，已抓取的弹幕会被保留
+func (ds *DanmakuService) CancelTask(taskID string) error {
+	ds.mu.RLock()
+	task, exists := ds.tasks[taskID]
+	var cancel context.CancelFunc
+	status := ""
+	if exists {
+		cancel = task.cancel
+		status = task.Status
+	}
+	ds.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrDanmakuTaskNotFound, taskID)
+	}
+	if (status != "queued" && status != "running") || cancel == nil {
+		return fmt.Errorf("%w: cannot be cancelled in status %s", ErrDanmakuTaskInvalidState, status)
+	}
+
+	cancel()
+	return nil
+}
+
+// GetDanmaku 获取任务的弹幕结果（带筛选排序），返回筛选后的总数
+// sortBy: progress（视频时间，默认）, time_desc, time_asc
+func (ds *DanmakuService) GetDanmaku(taskID, sortBy, keyword string, limit int) ([]bilibili.Danmaku, int, error) {
+	all, err := ds.loadDanmaku(taskID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	danmaku := make([]bilibili.Danmaku, 0, len(all))
+	keyword = strings.ToLower(keyword)
+	for _, d := range all {
+		if keyword == "" || strings.Contains(strings.ToLower(d.Content), keyword) {
+			danmaku = append(danmaku, d)
+		}
+	}
+	total := len(danmaku)
+
+	switch sortBy {
+	case "time_desc":
+		sort.SliceStable(danmaku, func(i, j int) bool { return danmaku[i].Ctime > danmaku[j].Ctime })
+	case "time_asc":
+		sort.SliceStable(danmaku, func(i, j int) bool { return danmaku[i].Ctime < danmaku[j].Ctime })
+	}
+
+	if limit > 0 && limit < len(danmaku) {
+		danmaku = danmaku[:limit]
+	}
+
+	return danmaku, total, nil
+}
+
+// loadDanmaku 获取任务的全部弹幕（按分P和视频时间排序），未加载时从存储懒加载
+func (ds *DanmakuService) loadDanmaku(taskID string) ([]bilibili.Danmaku, error) {
+	ds.mu.RLock()
+	task, exists := ds.tasks[taskID]
+	var status string
+	var danmaku []bilibili.Danmaku
+	if exists {
+		status = task.Status
+		danmaku = task.Danmaku
+	}
+	ds.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrDanmakuTaskNotFound, taskID)
+	}
+	if status != "completed" && status != "cancelled" {
+		return nil, fmt.Errorf("%w: task is %s", ErrDanmakuTaskInvalidState, status)
+	}
+	if danmaku != nil {
+		return danmaku, nil
+	}
+
+	data, err := ds.storage.LoadDanmakuTask(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load danmaku: %w", err)
+	}
+	danmaku = convertDanmakuFromStorage(data.Danmaku)
+
+	ds.mu.Lock()
+	task.Danmaku = danmaku
+	ds.mu.Unlock()
+
+	return danmaku, nil
+}
+
+// DanmakuStats 弹幕统计
+type DanmakuStats struct {
+	TaskID        string                `json:"task_id"`
+	TotalDanmaku  int                   `json:"total_danmaku"`
+	UniqueSenders int                   `json:"unique_senders"`    // 按发送者哈希去重
+	ByMinute      []int                 `json:"by_minute"`         // 视频每分钟的弹幕数（下标为分钟，多个分P时按各自时间轴叠加）
+	PeakMinute    int                   `json:"peak_minute"`       // 弹幕最密集的分钟
+	ByPage        map[int]int           `json:"by_page,omitempty"` // 按分P（仅抓取全部分P时）
+	ByMode        map[string]int        `json:"by_mode"`
+	ByDate        map[string]int        `json:"by_date"` // 按发送日期
+	TopContents   []DanmakuContentCount `json:"top_contents"`
+}
+
+// DanmakuContentCount 弹幕内容出现次数
+type DanmakuContentCount struct {
+	Content string `json:"content"`
+	Count   int    `json:"count"`
+}
+
+// topDanmakuContents 统计中保留的高频弹幕数量
+const topDanmakuContents = 20
+
+// GetStats 获取弹幕统计（时间轴分布、类型分布、发送日期分布和高频弹幕）
+func (ds *DanmakuService) GetStats(taskID string) (*DanmakuStats, error) {
+	danmaku, err := ds.loadDanmaku(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	ds.mu.RLock()
+	page := ds.tasks[taskID].Page
+	duration := ds.tasks[taskID].Duration
+	ds.mu.RUnlock()
+
+	// 全部分P时各分P时间轴叠加，按出现的最大时间扩展
+	minutes := 1
+	if page != AllPages {
+		minutes = duration/60 + 1
+	}
+
+	stats := &DanmakuStats{
+		TaskID:       taskID,
+		TotalDanmaku: len(danmaku),
+		ByMinute:     make([]int, minutes),
+		ByMode:       make(map[string]int),
+		ByDate:       make(map[string]int),
+	}
+	if page == AllPages {
+		stats.ByPage = make(map[int]int)
+	}
+
+	senders := make(map[string]bool)
+	contents := make(map[string]int)
+	for _, d := range danmaku {
+		minute := d.Progress / 60000
+		for minute >= len(stats.ByMinute) {
+			stats.ByMinute = append(stats.ByMinute, 0)
+		}
+		stats.ByMinute[minute]++
+		if stats.ByPage != nil {
+			stats.ByPage[d.Page]++
+		}
+
+		stats.ByMode[DanmakuModeName(d.Mode)]++
+		stats.ByDate[time.Unix(d.Ctime, 0).Format("2006-01-02")]++
+		senders[d.MidHash] = true
+		contents[strings.TrimSpace(d.Content)]++
+	}
+	stats.UniqueSenders = len(senders)
+
+	for minute, count := range stats.ByMinute {
+		if count > stats.ByMinute[stats.PeakMinute] {
+			stats.PeakMinute = minute
+		}
+	}
+
+	for content, count := range contents {
+		stats.TopContents = append(stats.TopContents, DanmakuContentCount{Content: content, Count: count})
+	}
+	sort.Slice(stats.TopContents, func(i, j int) bool {
+		if stats.TopContents[i].Count != stats.TopContents[j].Count {
+			return stats.TopContents[i].Count > stats.TopContents[j].Count
+		}
+		return stats.TopContents[i].Content < stats.TopContents[j].Content
+	})
+	if len(stats.TopContents) > topDanmakuContents {
+		stats.TopContents = stats.TopContents[:topDanmakuContents]
+	}
+
+	return stats, nil
+}
+
+// DanmakuModeName 弹幕类型名称
+func DanmakuModeName(mode int) string {
+	switch mode {
+	case 1, 2, 3:
+		return "滚动"
+	case 4:
+		return "底部"
+	case 5:
+		return "顶部"
+	case 6:
+		return "逆向"
+	case 7:
+		return "高级"
+	case 8:
+		return "代码"
+	case 9:
+		return "BAS"
+	default:
+		return "其他"
+	}
+}
+
+// FormatDanmakuProgress 将弹幕出现时间（毫秒）格式化为 mm:ss 或 h:mm:ss
+func FormatDanmakuProgress(progressMs int) string {
+	seconds := progressMs / 1000
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
+
+// loadTasks 从存储加载任务元数据（弹幕数据懒加载），重启前未完成的任务标记为失败
+func (ds *DanmakuService) loadTasks() {
+	index, err := ds.storage.LoadDanmakuIndex()
+	if err != nil {
+		utils.LogError("加载弹幕任务失败: " + err.Error())
+		return
+	}
+
+	interrupted := false
+	for _, meta := range index.Tasks {
+		task := &DanmakuTask{
+			TaskID:     meta.TaskID,
+			VideoID:    meta.VideoID,
+			VideoTitle: meta.VideoTitle,
+			Page:       meta.Page,
+			CID:        meta.CID,
+			Duration:   meta.Duration,
+			Source:     meta.Source,
+			Status:     meta.Status,
+			Progress:   DanmakuProgress{TotalDanmaku: meta.DanmakuCount},
+			StartTime:  meta.StartTime,
+			EndTime:    meta.EndTime,
+			Error:      meta.Error,
+		}
+		if task.Status == "queued" || task.Status == "running" {
+			task.Status = "failed"
+			task.Error = "任务被中断（服务器重启）"
+			interrupted = true
+		}
+		ds.tasks[task.TaskID] = task
+	}
+
+	if interrupted {
+		ds.saveIndex()
+	}
+}
+
+// saveIndex 持久化任务索引
+func (ds *DanmakuService) saveIndex() {
+	ds.mu.RLock()
+	metas := make([]storage.DanmakuTaskMeta, 0, len(ds.tasks))
+	for _, task := range ds.tasks {
+		metas = append(metas, storage.DanmakuTaskMeta{
+			TaskID:       task.TaskID,
+			VideoID:      task.VideoID,
+			VideoTitle:   task.VideoTitle,
+			Page:         task.Page,
+			CID:          task.CID,
+			Duration:     task.Duration,
+			Source:       task.Source,
+			Status:       task.Status,
+			DanmakuCount: task.Progress.TotalDanmaku,
+			StartTime:    task.StartTime,
+			EndTime:      task.EndTime,
+			Error:        task.Error,
+		})
+	}
+	ds.mu.RUnlock()
+
+	sort.Slice(metas, func(i, j int) bool {
+		return metas[i].StartTime.Before(metas[j].StartTime)
+	})
+
+	if err := ds.storage.SaveDanmakuIndex(&storage.DanmakuIndex{Tasks: metas}); err != nil {
+		utils.LogError("Failed to save danmaku index: " + err.Error())
+	}
+}
+
+// convertDanmakuTaskToStorage 转换为存储层格式（调用方需持有锁）
+func convertDanmakuTaskToStorage(task *DanmakuTask) *storage.DanmakuTaskData {
+	entries := make([]storage.DanmakuEntry, len(task.Danmaku))
+	for i, d := range task.Danmaku {
+		entries[i] = storage.DanmakuEntry{
+			ID:       d.ID,
+			Progress: d.Progress,
+			Mode:     d.Mode,
+			FontSize: d.FontSize,
+			Color:    d.Color,
+			MidHash:  d.MidHash,
+			Content:  d.Content,
+			Ctime:    d.Ctime,
+			Weight:   d.Weight,
+			Pool:     d.Pool,
+			Page:     d.Page,
+		}
+	}
+
+	return &storage.DanmakuTaskData{
+		TaskID:     task.TaskID,
+		VideoID:    task.VideoID,
+		VideoTitle: task.VideoTitle,
+		Page:       task.Page,
+		CID:        task.CID,
+		Duration:   task.Duration,
+		Source:     task.Source,
+		Status:     task.Status,
+		Danmaku:    entries,
+		StartTime:  task.StartTime,
+		EndTime:    task.EndTime,
+		Error:      task.Error,
+	}
+}
+
+// convertDanmakuFromStorage 从存储层格式转换
+func convertDanmakuFromStorage(entries []storage.DanmakuEntry) []bilibili.Danmaku {
+	danmaku := make([]bilibili.Danmaku, len(entries))
+	for i, e := range entries {
+		danmaku[i] = bilibili.Danmaku{
+			ID:       e.ID,
+			Progress: e.Progress,
+			Mode:     e.Mode,
+			FontSize: e.FontSize,
+			Color:    e.Color,
+			MidHash:  e.MidHash,
+			Content:  e.Content,
+			Ctime:    e.Ctime,
+			Weight:   e.Weight,
+			Pool:     e.Pool,
+			Page:     e.Page,
+		}
+	}
+	return danmaku
+}
+
+// Shutdown 优雅关闭服务，运行中的任务被中断
+func (ds *DanmakuService) Shutdown(ctx context.Context) error {
+	utils.LogInfo("Shutting down DanmakuService...")
+
+	ds.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		ds.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		utils.LogInfo("DanmakuService shutdown complete")
+		return nil
+	case <-ctx.Done():
+		utils.LogError("DanmakuService shutdown timeout")
+		return ctx.Err()
+	}
+}
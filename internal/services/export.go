@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -62,6 +63,16 @@ func NewExportService(ctx context.Context, exportDir string) *ExportService {
 
 // ExportComments 导出评论
 func (es *ExportService) ExportComments(comments []bilibili.CommentData, format, customFilename string) (*ExportFile, error) {
+	return es.exportRows(es.PrepareCommentRows(comments), format, customFilename, "comments")
+}
+
+// ExportDanmaku 导出弹幕（保留弹幕在视频中出现的时间）
+func (es *ExportService) ExportDanmaku(danmaku []bilibili.Danmaku, format, customFilename string) (*ExportFile, error) {
+	return es.exportRows(es.PrepareDanmakuRows(danmaku), format, customFilename, "danmaku")
+}
+
+// exportRows 将数据行写入导出文件，defaultPrefix 为未指定文件名时的前缀
+func (es *ExportService) exportRows(rows [][]string, format, customFilename, defaultPrefix string) (*ExportFile, error) {
 	fileID := uuid.New().String()
 
 	// 生成文件名
@@ -70,14 +81,11 @@ func (es *ExportService) ExportComments(comments []bilibili.CommentData, format,
 	if customFilename != "" {
 		filename = fmt.Sprintf("%s_%s.%s", customFilename, timestamp, format)
 	} else {
-		filename = fmt.Sprintf("comments_%s.%s", timestamp, format)
+		filename = fmt.Sprintf("%s_%s.%s", defaultPrefix, timestamp, format)
 	}
 
 	filePath := filepath.Join(es.exportDir, filename)
 
-	// 准备数据
-	rows := es.PrepareCommentRows(comments)
-
 	// 根据格式导出
 	var err error
 	switch format {
@@ -127,7 +135,7 @@ func (es *ExportService) GetExportFile(fileID string) (*ExportFile, error) {
 func (es *ExportService) PrepareCommentRows(comments []bilibili.CommentData) [][]string {
 	// 表头
 	rows := [][]string{
-		{"层级", "评论ID", "用户ID", "用户名", "等级", "评论内容", "点赞数", "评论时间"},
+		{"层级", "评论ID", "用户ID", "用户名", "等级", "大会员", "评论内容", "点赞数", "评论时间", "评论区类型", "IP属地", "置顶", "UP主点赞", "UP主回复", "图片"},
 	}
 
 	// 递归添加评论数据
@@ -149,15 +157,35 @@ func (es *ExportService) addCommentRow(rows *[][]string, comment bilibili.Commen
 		levelStr = fmt.Sprintf("└ 回复 (L%d)", level)
 	}
 
+	vipLabel := ""
+	if comment.Member.Vip.IsVip() {
+		vipLabel = comment.Member.Vip.Label.Text
+		if vipLabel == "" {
+			vipLabel = "大会员"
+		}
+	}
+
+	pictures := make([]string, 0, len(comment.Content.Pictures))
+	for _, pic := range comment.Content.Pictures {
+		pictures = append(pictures, pic.ImgSrc)
+	}
+
 	row := []string{
 		levelStr,
 		strconv.FormatInt(comment.RPID, 10),
 		strconv.FormatInt(comment.Mid, 10),
 		comment.Member.Uname,
 		strconv.Itoa(comment.Member.LevelInfo.CurrentLevel),
+		vipLabel,
 		comment.Content.Message,
 		strconv.Itoa(comment.Like),
 		timeStr,
+		bilibili.CommentTypeName(comment.Type),
+		comment.IPLocation(),
+		yesOrEmpty(comment.IsTop),
+		yesOrEmpty(comment.UpAction.Like),
+		yesOrEmpty(comment.UpAction.Reply),
+		strings.Join(pictures, "\n"),
 	}
 	*rows = append(*rows, row)
 
@@ -169,6 +197,38 @@ func (es *ExportService) addCommentRow(rows *[][]string, comment bilibili.Commen
 	}
 }
 
+// yesOrEmpty 布尔列：是为"是"，否为空
+func yesOrEmpty(b bool) string {
+	if b {
+		return "是"
+	}
+	return ""
+}
+
+// PrepareDanmakuRows 准备弹幕数据行
+func (es *ExportService) PrepareDanmakuRows(danmaku []bilibili.Danmaku) [][]string {
+	rows := [][]string{
+		{"弹幕ID", "分P", "视频时间", "视频时间(秒)", "弹幕内容", "类型", "字号", "颜色", "发送者哈希", "发送时间"},
+	}
+
+	for _, d := range danmaku {
+		rows = append(rows, []string{
+			strconv.FormatInt(d.ID, 10),
+			strconv.Itoa(d.Page),
+			FormatDanmakuProgress(d.Progress),
+			strconv.FormatFloat(float64(d.Progress)/1000, 'f', 3, 64),
+			d.Content,
+			DanmakuModeName(d.Mode),
+			strconv.Itoa(d.FontSize),
+			fmt.Sprintf("#%06X", d.Color),
+			d.MidHash,
+			time.Unix(d.Ctime, 0).Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return rows
+}
+
 // cleanupWorker 定期清理旧文件（2小时前）
 func (es *ExportService) cleanupWorker() {
 	ticker := time.NewTicker(1 * time.Hour)
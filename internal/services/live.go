@@ -0,0 +1,654 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"bilibili/pkg/bilibili"
+	"bilibili/pkg/storage"
+	"bilibili/pkg/utils"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrLiveTaskNotFound 直播抓取任务不存在
+	ErrLiveTaskNotFound = errors.New("live task not found")
+	// ErrLiveTaskInvalidState 直播抓取任务当前状态不允许执行该操作
+	ErrLiveTaskInvalidState = errors.New("invalid live task state")
+	// ErrInvalidLiveCapture 直播抓取参数无效
+	ErrInvalidLiveCapture = errors.New("invalid live capture")
+)
+
+const (
+	// liveFlushInterval 缓冲的直播间事件写入存储的间隔
+	liveFlushInterval = 5 * time.Second
+	// liveReconnectDelay 连接断开后的首次重连等待时间，之后每次翻倍
+	liveReconnectDelay = 5 * time.Second
+	// maxLiveReconnectDelay 重连等待时间上限
+	maxLiveReconnectDelay = 2 * time.Minute
+)
+
+// liveRoomURLPattern 直播间链接，如 https://live.bilibili.com/21452505、https://live.bilibili.com/h5/21452505
+var liveRoomURLPattern = regexp.MustCompile(`live\.bilibili\.com/(?:h5/)?(\d+)`)
+
+// LiveService 直播弹幕服务，管理长时间运行的直播间抓取任务
+type LiveService struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	mu          sync.RWMutex
+	flushMu     sync.Mutex // 保证同一任务的事件按接收顺序写入
+	tasks       map[string]*LiveTask
+	storage     storage.LiveStorage
+	client      *bilibili.BilibiliClient
+	accounts    *AccountService
+	chatOptions []bilibili.LiveChatOption
+}
+
+// LiveTask 直播间抓取任务
+type LiveTask struct {
+	TaskID     string
+	RoomID     int64 // 用户输入的房间号（可能是短号）
+	RealRoomID int64 // 真实房间号
+	AnchorUID  int64
+	Title      string
+	Status     string // running, stopped, failed
+	Connected  bool   // 当前是否连接着弹幕服务器
+	Popularity int64  // 最近一次心跳回复中的人气值
+	Stats      LiveStats
+	Reconnects int
+	StartTime  time.Time
+	EndTime    time.Time
+	Deadline   time.Time // 自动停止时间，零值表示直到手动停止
+	AccountID  string    // 连接弹幕服务器使用的登录账号，为空时以游客身份连接
+	Error      string    // 运行中时为最近一次断线原因
+
+	pending []bilibili.LiveEvent // 尚未写入存储的事件
+	conn    *bilibili.LiveChatConn
+	cancel  context.CancelFunc // 停止任务（仅运行期间有效）
+}
+
+// LiveStats 直播间事件统计
+type LiveStats struct {
+	DanmakuCount   int       `json:"danmaku_count"`
+	SuperChatCount int       `json:"super_chat_count"`
+	GiftCount      int       `json:"gift_count"`
+	SuperChatTotal float64   `json:"super_chat_total"` // 醒目留言总金额（元）
+	GiftTotal      float64   `json:"gift_total"`       // 付费礼物总金额（元）
+	LastEventAt    time.Time `json:"last_event_at"`
+}
+
+// summary 复制任务概要，避免调用方与抓取 goroutine 并发读写（调用方需持有锁）
+func (t *LiveTask) summary() *LiveTask {
+	copied := *t
+	if t.conn != nil {
+		copied.Popularity = t.conn.Popularity()
+	}
+	copied.pending = nil
+	copied.conn = nil
+	copied.cancel = nil
+	return &copied
+}
+
+// NewLiveService 创建直播弹幕服务，调用 Start 后恢复重启前运行中的任务
+// chatOptions 作用于每次连接弹幕服务器（如指定服务器地址、brotli解压）
+func NewLiveService(ctx context.Context, liveStorage storage.LiveStorage, client *bilibili.BilibiliClient, chatOptions ...bilibili.LiveChatOption) *LiveService {
+	serviceCtx, cancel := context.WithCancel(ctx)
+
+	if client == nil {
+		client = bilibili.DefaultClient()
+	}
+
+	ls := &LiveService{
+		ctx:         serviceCtx,
+		cancel:      cancel,
+		tasks:       make(map[string]*LiveTask),
+		storage:     liveStorage,
+		client:      client,
+		chatOptions: chatOptions,
+	}
+
+	return ls
+}
+
+// Start 从存储加载任务，重启前运行中的任务自动恢复抓取
+// 需在 SetAccountService 之后调用，使用登录账号的任务恢复时才能取得认证信息
+func (ls *LiveService) Start() {
+	ls.loadTasks()
+}
+
+// SetAccountService 设置登录账号服务，设置后任务可使用已保存的账号连接弹幕服务器
+// 未登录时服务器下发的弹幕会隐藏用户名和UID
+func (ls *LiveService) SetAccountService(accounts *AccountService) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.accounts = accounts
+}
+
+// accountChatOptions 获取账号的直播弹幕认证选项，未设置账号服务时按账号不存在处理
+func (ls *LiveService) accountChatOptions(accountID string) ([]bilibili.LiveChatOption, error) {
+	ls.mu.RLock()
+	accounts := ls.accounts
+	ls.mu.RUnlock()
+
+	if accounts == nil {
+		return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, accountID)
+	}
+	return accounts.LiveChatOptions(accountID)
+}
+
+// ParseLiveRoomInput 解析直播间房间号，支持纯数字和直播间链接
+func ParseLiveRoomInput(input string) (int64, error) {
+	input = strings.TrimSpace(input)
+	if m := liveRoomURLPattern.FindStringSubmatch(input); m != nil {
+		input = m[1]
+	}
+
+	roomID, err := strconv.ParseInt(input, 10, 64)
+	if err != nil || roomID <= 0 {
+		return 0, fmt.Errorf("%w: invalid room %q", ErrInvalidLiveCapture, input)
+	}
+	return roomID, nil
+}
+
+// StartLiveCapture 创建直播间抓取任务并在后台运行
+// durationMinutes 为抓取时长，0 表示一直抓取直到手动停止；同一直播间同时只能有一个运行中的任务
+// accountID 不为空时使用该登录账号连接弹幕服务器
+func (ls *LiveService) StartLiveCapture(roomID int64, durationMinutes int, accountID string) (string, error) {
+	if roomID <= 0 {
+		return "", fmt.Errorf("%w: room_id must be positive", ErrInvalidLiveCapture)
+	}
+	if durationMinutes < 0 {
+		return "", fmt.Errorf("%w: duration_minutes must not be negative", ErrInvalidLiveCapture)
+	}
+	if accountID != "" {
+		if _, err := ls.accountChatOptions(accountID); err != nil {
+			return "", err
+		}
+	}
+
+	task := &LiveTask{
+		TaskID:    uuid.New().String(),
+		RoomID:    roomID,
+		Status:    "running",
+		StartTime: time.Now(),
+		AccountID: accountID,
+	}
+	if durationMinutes > 0 {
+		task.Deadline = task.StartTime.Add(time.Duration(durationMinutes) * time.Minute)
+	}
+
+	ls.mu.Lock()
+	for _, t := range ls.tasks {
+		if t.Status == "running" && (t.RoomID == roomID || t.RealRoomID == roomID) {
+			ls.mu.Unlock()
+			return "", fmt.Errorf("%w: room %d is already being captured by task %s", ErrLiveTaskInvalidState, roomID, t.TaskID)
+		}
+	}
+	ls.tasks[task.TaskID] = task
+	ls.mu.Unlock()
+
+	ls.saveIndex()
+	ls.launch(task)
+
+	return task.TaskID, nil
+}
+
+// launch 启动任务的后台 goroutine
+func (ls *LiveService) launch(task *LiveTask) {
+	var taskCtx context.Context
+	var cancel context.CancelFunc
+	if task.Deadline.IsZero() {
+		taskCtx, cancel = context.WithCancel(ls.ctx)
+	} else {
+		taskCtx, cancel = context.WithDeadline(ls.ctx, task.Deadline)
+	}
+
+	ls.mu.Lock()
+	task.cancel = cancel
+	ls.mu.Unlock()
+
+	ls.wg.Add(1)
+	go func() {
+		defer ls.wg.Done()
+		defer cancel()
+		ls.runCapture(taskCtx, task)
+	}()
+}
+
+// runCapture 运行抓取任务（后台goroutine）：连接断开后按指数退避重连，直到停止、到期或服务关闭
+func (ls *LiveService) runCapture(ctx context.Context, task *LiveTask) {
+	ls.mu.RLock()
+	realRoomID := task.RealRoomID
+	ls.mu.RUnlock()
+
+	// 恢复的任务已有真实房间号，不再重复查询
+	if realRoomID == 0 {
+		roomResp, err := ls.client.GetLiveRoomInfo(ctx, task.RoomID)
+		if err != nil {
+			if ctx.Err() != nil {
+				ls.finishTask(task, "stopped", "")
+				return
+			}
+			ls.finishTask(task, "failed", fmt.Sprintf("failed to get live room info: %v", err))
+			return
+		}
+
+		realRoomID = roomResp.Data.RoomID
+		ls.mu.Lock()
+		task.RealRoomID = realRoomID
+		task.AnchorUID = roomResp.Data.UID
+		task.Title = roomResp.Data.Title
+		ls.mu.Unlock()
+		ls.saveIndex()
+	}
+
+	// 定期将缓冲的事件写入存储
+	flushDone := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(liveFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-flushDone:
+				return
+			case <-ticker.C:
+				ls.flushEvents(task)
+			}
+		}
+	}()
+
+	delay := liveReconnectDelay
+	for {
+		// 每次连接时重新读取账号，使用账号最新的Cookie；账号被删除或失效时任务失败
+		chatOptions := ls.chatOptions
+		if task.AccountID != "" {
+			accountOptions, err := ls.accountChatOptions(task.AccountID)
+			if err != nil {
+				close(flushDone)
+				ls.finishTask(task, "failed", fmt.Sprintf("account unavailable: %v", err))
+				return
+			}
+			chatOptions = append(append([]bilibili.LiveChatOption{}, ls.chatOptions...), accountOptions...)
+		}
+
+		conn, err := ls.client.ConnectLiveChat(ctx, realRoomID, chatOptions...)
+		if err == nil {
+			ls.mu.Lock()
+			task.Connected = true
+			task.conn = conn
+			ls.mu.Unlock()
+			utils.LogInfo(fmt.Sprintf("Live task %s connected to room %d", task.TaskID, realRoomID))
+
+			delay = liveReconnectDelay
+			err = conn.Run(ctx, func(event bilibili.LiveEvent) {
+				ls.addEvent(task, event)
+			})
+			conn.Close()
+
+			ls.mu.Lock()
+			task.Connected = false
+			task.Popularity = conn.Popularity()
+			task.conn = nil
+			ls.mu.Unlock()
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		ls.mu.Lock()
+		task.Reconnects++
+		task.Error = fmt.Sprintf("connection lost: %v", err)
+		ls.mu.Unlock()
+		ls.saveIndex()
+		utils.LogError(fmt.Sprintf("Live task %s disconnected, reconnecting in %v: %v", task.TaskID, delay, err))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		delay *= 2
+		if delay > maxLiveReconnectDelay {
+			delay = maxLiveReconnectDelay
+		}
+	}
+
+	close(flushDone)
+	ls.finishTask(task, "stopped", "")
+}
+
+// addEvent 记录一个直播间事件
+func (ls *LiveService) addEvent(task *LiveTask, event bilibili.LiveEvent) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	switch event.Type {
+	case bilibili.LiveEventDanmaku:
+		task.Stats.DanmakuCount++
+	case bilibili.LiveEventSuperChat:
+		task.Stats.SuperChatCount++
+		task.Stats.SuperChatTotal += event.Price
+	case bilibili.LiveEventGift:
+		task.Stats.GiftCount++
+		task.Stats.GiftTotal += event.Price
+	}
+	task.Stats.LastEventAt = time.Now()
+	task.pending = append(task.pending, event)
+}
+
+// flushEvents 将缓冲的事件追加写入存储，写入失败时保留在缓冲区等待下次重试
+func (ls *LiveService) flushEvents(task *LiveTask) {
+	ls.flushMu.Lock()
+	defer ls.flushMu.Unlock()
+
+	ls.mu.Lock()
+	events := task.pending
+	task.pending = nil
+	ls.mu.Unlock()
+
+	if len(events) == 0 {
+		return
+	}
+
+	entries := make([]storage.LiveEventEntry, len(events))
+	for i, event := range events {
+		entries[i] = liveEventToStorage(event)
+	}
+	if err := ls.storage.AppendLiveEvents(task.TaskID, entries); err != nil {
+		utils.LogError(fmt.Sprintf("Failed to save live events of task %s: %v", task.TaskID, err))
+		ls.mu.Lock()
+		task.pending = append(events, task.pending...)
+		ls.mu.Unlock()
+		return
+	}
+
+	ls.saveIndex()
+}
+
+// finishTask 结束任务并写入剩余事件
+// 服务关闭导致的结束保持 running 状态，重启后自动恢复抓取
+func (ls *LiveService) finishTask(task *LiveTask, status, errMsg string) {
+	ls.flushEvents(task)
+
+	if ls.ctx.Err() != nil && status == "stopped" {
+		ls.mu.Lock()
+		task.Connected = false
+		task.cancel = nil
+		ls.mu.Unlock()
+		ls.saveIndex()
+		utils.LogInfo(fmt.Sprintf("Live task %s paused for shutdown", task.TaskID))
+		return
+	}
+
+	ls.mu.Lock()
+	task.Status = status
+	task.Error = errMsg
+	task.Connected = false
+	task.EndTime = time.Now()
+	task.cancel = nil
+	stats := task.Stats
+	ls.mu.Unlock()
+
+	ls.saveIndex()
+
+	utils.LogInfo(fmt.Sprintf("Live task %s %s with %d danmaku, %d super chats and %d gifts",
+		task.TaskID, status, stats.DanmakuCount, stats.SuperChatCount, stats.GiftCount))
+}
+
+// StopLiveCapture 停止运行中的任务，已抓取的事件会被保留
+func (ls *LiveService) StopLiveCapture(taskID string) error {
+	ls.mu.RLock()
+	task, exists := ls.tasks[taskID]
+	var cancel context.CancelFunc
+	status := ""
+	if exists {
+		cancel = task.cancel
+		status = task.Status
+	}
+	ls.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrLiveTaskNotFound, taskID)
+	}
+	if status != "running" || cancel == nil {
+		return fmt.Errorf("%w: cannot be stopped in status %s", ErrLiveTaskInvalidState, status)
+	}
+
+	cancel()
+	return nil
+}
+
+// GetTask 获取任务概要
+func (ls *LiveService) GetTask(taskID string) (*LiveTask, error) {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+
+	task, exists := ls.tasks[taskID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrLiveTaskNotFound, taskID)
+	}
+	return task.summary(), nil
+}
+
+// ListTasks 获取所有任务概要（最新的在前）
+func (ls *LiveService) ListTasks() []*LiveTask {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+
+	tasks := make([]*LiveTask, 0, len(ls.tasks))
+	for _, task := range ls.tasks {
+		tasks = append(tasks, task.summary())
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].StartTime.After(tasks[j].StartTime)
+	})
+
+	return tasks
+}
+
+// GetEvents 获取任务已抓取的直播间事件（按接收顺序），返回筛选后的总数
+// eventType 为空时返回全部类型；运行中的任务会先写入缓冲的事件
+func (ls *LiveService) GetEvents(taskID, eventType, keyword string, offset, limit int) ([]bilibili.LiveEvent, int, error) {
+	switch eventType {
+	case "", bilibili.LiveEventDanmaku, bilibili.LiveEventSuperChat, bilibili.LiveEventGift:
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown event type %s", ErrInvalidLiveCapture, eventType)
+	}
+
+	ls.mu.RLock()
+	task, exists := ls.tasks[taskID]
+	ls.mu.RUnlock()
+	if !exists {
+		return nil, 0, fmt.Errorf("%w: %s", ErrLiveTaskNotFound, taskID)
+	}
+
+	ls.flushEvents(task)
+
+	entries, err := ls.storage.LoadLiveEvents(taskID)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, 0, fmt.Errorf("failed to load live events: %w", err)
+	}
+
+	events := make([]bilibili.LiveEvent, 0, len(entries))
+	keyword = strings.ToLower(keyword)
+	for _, e := range entries {
+		if eventType != "" && e.Type != eventType {
+			continue
+		}
+		if keyword != "" && !strings.Contains(strings.ToLower(e.Content+" "+e.Uname+" "+e.GiftName), keyword) {
+			continue
+		}
+		events = append(events, liveEventFromStorage(e))
+	}
+	total := len(events)
+
+	if offset > 0 {
+		if offset >= len(events) {
+			events = events[:0]
+		} else {
+			events = events[offset:]
+		}
+	}
+	if limit > 0 && limit < len(events) {
+		events = events[:limit]
+	}
+
+	return events, total, nil
+}
+
+// loadTasks 从存储加载任务元数据，重启前运行中且未到期的任务恢复抓取
+func (ls *LiveService) loadTasks() {
+	index, err := ls.storage.LoadLiveIndex()
+	if err != nil {
+		utils.LogError("加载直播抓取任务失败: " + err.Error())
+		return
+	}
+
+	var resumed []*LiveTask
+	expired := false
+	for _, meta := range index.Tasks {
+		task := &LiveTask{
+			TaskID:     meta.TaskID,
+			RoomID:     meta.RoomID,
+			RealRoomID: meta.RealRoomID,
+			AnchorUID:  meta.AnchorUID,
+			Title:      meta.Title,
+			Status:     meta.Status,
+			Stats: LiveStats{
+				DanmakuCount:   meta.DanmakuCount,
+				SuperChatCount: meta.SuperChatCount,
+				GiftCount:      meta.GiftCount,
+				SuperChatTotal: meta.SuperChatTotal,
+				GiftTotal:      meta.GiftTotal,
+			},
+			Reconnects: meta.Reconnects,
+			StartTime:  meta.StartTime,
+			EndTime:    meta.EndTime,
+			Deadline:   meta.Deadline,
+			AccountID:  meta.AccountID,
+			Error:      meta.Error,
+		}
+		if task.Status == "running" {
+			if !task.Deadline.IsZero() && time.Now().After(task.Deadline) {
+				task.Status = "stopped"
+				task.EndTime = task.Deadline
+				expired = true
+			} else {
+				resumed = append(resumed, task)
+			}
+		}
+		ls.tasks[task.TaskID] = task
+	}
+
+	if expired {
+		ls.saveIndex()
+	}
+	for _, task := range resumed {
+		utils.LogInfo(fmt.Sprintf("恢复直播抓取任务 %s（房间 %d）", task.TaskID, task.RoomID))
+		ls.launch(task)
+	}
+}
+
+// saveIndex 持久化任务索引
+func (ls *LiveService) saveIndex() {
+	ls.mu.RLock()
+	metas := make([]storage.LiveTaskMeta, 0, len(ls.tasks))
+	for _, task := range ls.tasks {
+		metas = append(metas, storage.LiveTaskMeta{
+			TaskID:         task.TaskID,
+			RoomID:         task.RoomID,
+			RealRoomID:     task.RealRoomID,
+			AnchorUID:      task.AnchorUID,
+			Title:          task.Title,
+			Status:         task.Status,
+			DanmakuCount:   task.Stats.DanmakuCount,
+			SuperChatCount: task.Stats.SuperChatCount,
+			GiftCount:      task.Stats.GiftCount,
+			SuperChatTotal: task.Stats.SuperChatTotal,
+			GiftTotal:      task.Stats.GiftTotal,
+			Reconnects:     task.Reconnects,
+			StartTime:      task.StartTime,
+			EndTime:        task.EndTime,
+			Deadline:       task.Deadline,
+			AccountID:      task.AccountID,
+			Error:          task.Error,
+		})
+	}
+	ls.mu.RUnlock()
+
+	sort.Slice(metas, func(i, j int) bool {
+		return metas[i].StartTime.Before(metas[j].StartTime)
+	})
+
+	if err := ls.storage.SaveLiveIndex(&storage.LiveIndex{Tasks: metas}); err != nil {
+		utils.LogError("Failed to save live index: " + err.Error())
+	}
+}
+
+// liveEventToStorage 转换为存储层格式
+func liveEventToStorage(event bilibili.LiveEvent) storage.LiveEventEntry {
+	return storage.LiveEventEntry{
+		Type:       event.Type,
+		Time:       event.Time,
+		UID:        event.UID,
+		Uname:      event.Uname,
+		Content:    event.Content,
+		MedalName:  event.MedalName,
+		MedalLevel: event.MedalLevel,
+		GiftName:   event.GiftName,
+		GiftNum:    event.GiftNum,
+		Price:      event.Price,
+	}
+}
+
+// liveEventFromStorage 从存储层格式转换
+func liveEventFromStorage(e storage.LiveEventEntry) bilibili.LiveEvent {
+	return bilibili.LiveEvent{
+		Type:       e.Type,
+		Time:       e.Time,
+		UID:        e.UID,
+		Uname:      e.Uname,
+		Content:    e.Content,
+		MedalName:  e.MedalName,
+		MedalLevel: e.MedalLevel,
+		GiftName:   e.GiftName,
+		GiftNum:    e.GiftNum,
+		Price:      e.Price,
+	}
+}
+
+// Shutdown 优雅关闭服务，运行中的任务写入已抓取的事件后暂停，重启后继续
+func (ls *LiveService) Shutdown(ctx context.Context) error {
+	utils.LogInfo("Shutting down LiveService...")
+
+	ls.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		ls.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		utils.LogInfo("LiveService shutdown complete")
+		return nil
+	case <-ctx.Done():
+		utils.LogError("LiveService shutdown timeout")
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+)
+
+// ErrQueueFull 等待队列已满
+var ErrQueueFull = errors.New("task queue is full")
+
+// 任务优先级：数值越大越先执行，相同优先级按入队顺序执行
+const (
+	PriorityLow    = -1 // 定时监控等后台任务
+	PriorityNormal = 0
+	PriorityHigh   = 1
+)
+
+// DefaultMaxConcurrentTasks 默认同时运行的任务数
+const DefaultMaxConcurrentTasks = 2
+
+// enqueueLocked 将任务加入等待队列（调用方需持有锁）
+// 新任务排在所有优先级不低于它的任务之后
+func (cs *CommentService) enqueueLocked(task *ScrapeTask) {
+	task.Status = "queued"
+
+	pos := len(cs.queue)
+	for i, queued := range cs.queue {
+		if queued.Priority < task.Priority {
+			pos = i
+			break
+		}
+	}
+
+	cs.queue = append(cs.queue, nil)
+	copy(cs.queue[pos+1:], cs.queue[pos:])
+	cs.queue[pos] = task
+
+	cs.updateQueuePositionsLocked()
+}
+
+// removeFromQueueLocked 将任务移出等待队列（调用方需持有锁），返回任务是否在队列中
+func (cs *CommentService) removeFromQueueLocked(task *ScrapeTask) bool {
+	for i, queued := range cs.queue {
+		if queued == task {
+			cs.queue = append(cs.queue[:i], cs.queue[i+1:]...)
+			task.Progress.QueuePosition = 0
+			cs.updateQueuePositionsLocked()
+			return true
+		}
+	}
+	return false
+}
+
+// updateQueuePositionsLocked 刷新队列中每个任务的排队位置（从1开始）
+func (cs *CommentService) updateQueuePositionsLocked() {
+	for i, queued := range cs.queue {
+		queued.Progress.QueuePosition = i + 1
+	}
+}
+
+// queueFullLocked 等待队列是否已满（调用方需持有锁）
+func (cs *CommentService) queueFullLocked() bool {
+	return cs.maxQueued > 0 && len(cs.queue) >= cs.maxQueued
+}
+
+// dispatch 在有空闲名额时按优先级启动排队中的任务
+// 新任务启动执行；暂停后恢复的任务直接唤醒原有的执行 goroutine
+func (cs *CommentService) dispatch() {
+	// 服务关闭时不再启动新任务，排队状态会被持久化，重启后继续
+	if cs.ctx.Err() != nil {
+		return
+	}
+
+	var toLaunch []*ScrapeTask
+	dispatched := 0
+
+	cs.mu.Lock()
+	for cs.running < cs.maxConcurrent && len(cs.queue) > 0 {
+		dispatched++
+		task := cs.queue[0]
+		cs.queue = cs.queue[1:]
+		task.Progress.QueuePosition = 0
+		// 已结束的任务不再启动
+		if task.Status == "completed" || task.Status == "cancelled" || task.Status == "failed" {
+			continue
+		}
+
+		cs.running++
+		task.holdsSlot = true
+		task.Status = "running"
+
+		if task.resumeCh != nil {
+			close(task.resumeCh)
+			task.resumeCh = nil
+		} else {
+			toLaunch = append(toLaunch, task)
+		}
+	}
+	cs.updateQueuePositionsLocked()
+	cs.mu.Unlock()
+
+	for _, task := range toLaunch {
+		cs.launchTask(task)
+	}
+	if dispatched > 0 {
+		go cs.updateIndex()
+	}
+}
+
+// releaseSlotLocked 释放任务占用的运行名额（调用方需持有锁）
+func (cs *CommentService) releaseSlotLocked(task *ScrapeTask) {
+	if task.holdsSlot {
+		task.holdsSlot = false
+		cs.running--
+	}
+}
@@ -0,0 +1,693 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bilibili/pkg/bilibili"
+	"bilibili/pkg/storage"
+	"bilibili/pkg/utils"
+)
+
+var (
+	// ErrScheduleNotFound 定时计划不存在
+	ErrScheduleNotFound = errors.New("schedule not found")
+	// ErrInvalidSchedule 定时计划参数不合法
+	ErrInvalidSchedule = errors.New("invalid schedule")
+)
+
+const (
+	// scheduleCheckInterval 检查到期计划的间隔
+	scheduleCheckInterval = 30 * time.Second
+	// minScheduleInterval 允许的最小抓取间隔（分钟）
+	minScheduleInterval = 5
+	// maxScheduleRuns 每个计划保留的运行历史条数
+	maxScheduleRuns = 50
+)
+
+// 定时计划的运行方式
+const (
+	ScheduleModeFull    = "full"    // 每次运行创建新任务完整抓取
+	ScheduleModeRefresh = "refresh" // 首次完整抓取，之后增量刷新同一个任务
+)
+
+// ScheduleService 定时监控服务，按计划定期重新抓取视频评论
+type ScheduleService struct {
+	ctx            context.Context
+	cancel         context.CancelFunc
+	wg             sync.WaitGroup
+	mu             sync.RWMutex
+	schedules      map[string]*Schedule
+	storage        storage.ScheduleStorage
+	commentService *CommentService
+}
+
+// Schedule 定时监控计划
+type Schedule struct {
+	ScheduleID      string
+	VideoID         string
+	IntervalMinutes int
+	SortMode        string
+	PageLimit       int
+	DelayMs         int
+	IncludeReplies  bool
+	Options         ScrapeOptions
+	Mode            string // ScheduleMode* 常量
+	RefreshTaskID   string // 增量刷新模式下被刷新的任务，计划修改后重新完整抓取
+	AuthType        string // none, cookie, app, account
+	Cookie          string // 认证信息只保存在内存中，持久化时加密存储
+	AppKey          string
+	AppSecret       string
+	CredentialID    string // 认证信息在加密存储中的引用ID，没有认证信息时为空
+	AccountID       string
+	Enabled         bool
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	LastRunAt       time.Time
+	NextRunAt       time.Time
+	Runs            []ScheduleRun // 运行历史（最新的在前），引用的任务不会被清理
+}
+
+// ScheduleRun 定时计划的一次运行记录
+type ScheduleRun struct {
+	TaskID    string
+	StartedAt time.Time
+	Skipped   bool // 上一次运行的任务尚未结束，本次跳过
+	Error     string
+}
+
+// ScheduleSpec 创建或更新定时计划的参数
+// 更新时认证方式不变且未提供 Cookie/AppKey 等认证信息时，沿用原有的认证信息
+type ScheduleSpec struct {
+	VideoID         string
+	IntervalMinutes int
+	SortMode        string
+	PageLimit       int
+	DelayMs         int
+	IncludeReplies  bool
+	Options         ScrapeOptions
+	Mode            string
+	AuthType        string
+	Cookie          string
+	AppKey          string
+	AppSecret       string
+	AccountID       string
+	Enabled         bool
+}
+
+// clone 复制计划（不含认证信息），避免调用方与后台调度并发读写
+func (s *Schedule) clone() *Schedule {
+	copied := *s
+	copied.Runs = append([]ScheduleRun(nil), s.Runs...)
+	copied.Cookie = ""
+	copied.AppKey = ""
+	copied.AppSecret = ""
+	return &copied
+}
+
+// taskIDs 运行历史引用的任务（去重）
+func (s *Schedule) taskIDs() map[string]bool {
+	ids := make(map[string]bool)
+	for _, run := range s.Runs {
+		if run.TaskID != "" {
+			ids[run.TaskID] = true
+		}
+	}
+	return ids
+}
+
+// NewScheduleService 创建定时监控服务
+func NewScheduleService(ctx context.Context, scheduleStorage storage.ScheduleStorage, commentService *CommentService) *ScheduleService {
+	serviceCtx, cancel := context.WithCancel(ctx)
+
+	ss := &ScheduleService{
+		ctx:            serviceCtx,
+		cancel:         cancel,
+		schedules:      make(map[string]*Schedule),
+		storage:        scheduleStorage,
+		commentService: commentService,
+	}
+
+	// 启动时从存储加载计划
+	ss.loadSchedules()
+
+	// 启动调度goroutine
+	ss.wg.Add(1)
+	go func() {
+		defer ss.wg.Done()
+		ss.scheduleWorker()
+	}()
+
+	return ss
+}
+
+// CreateSchedule 创建定时计划，启用的计划在一个间隔后首次运行
+func (ss *ScheduleService) CreateSchedule(spec ScheduleSpec) (*Schedule, error) {
+	if err := ss.validateScheduleSpec(&spec); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	schedule := &Schedule{
+		ScheduleID: uuid.New().String(),
+		CreatedAt:  now,
+		Runs:       []ScheduleRun{},
+	}
+
+	// 认证信息加密保存，计划文件中只记录引用ID
+	credentialID, err := ss.saveScheduleCredential(schedule.ScheduleID, spec)
+	if err != nil {
+		return nil, err
+	}
+	schedule.CredentialID = credentialID
+	applyScheduleSpec(schedule, spec, now)
+
+	ss.mu.Lock()
+	ss.schedules[schedule.ScheduleID] = schedule
+	result := schedule.clone()
+	ss.mu.Unlock()
+
+	if err := ss.saveSchedules(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UpdateSchedule 更新定时计划，间隔变化后从当前时间重新计算下次运行时间
+func (ss *ScheduleService) UpdateSchedule(scheduleID string, spec ScheduleSpec) (*Schedule, error) {
+	if err := ss.validateScheduleSpec(&spec); err != nil {
+		return nil, err
+	}
+
+	ss.mu.RLock()
+	schedule, exists := ss.schedules[scheduleID]
+	if exists && spec.AuthType == schedule.AuthType && spec.Cookie == "" && spec.AppKey == "" && spec.AppSecret == "" {
+		spec.Cookie, spec.AppKey, spec.AppSecret = schedule.Cookie, schedule.AppKey, schedule.AppSecret
+	}
+	ss.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrScheduleNotFound, scheduleID)
+	}
+
+	credentialID, err := ss.saveScheduleCredential(scheduleID, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	ss.mu.Lock()
+	schedule, exists = ss.schedules[scheduleID]
+	if !exists {
+		ss.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrScheduleNotFound, scheduleID)
+	}
+	schedule.CredentialID = credentialID
+	applyScheduleSpec(schedule, spec, time.Now())
+	result := schedule.clone()
+	ss.mu.Unlock()
+
+	if err := ss.saveSchedules(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteSchedule 删除定时计划，已创建的任务保留，之后按正常规则清理
+func (ss *ScheduleService) DeleteSchedule(scheduleID string) error {
+	ss.mu.Lock()
+	schedule, exists := ss.schedules[scheduleID]
+	if !exists {
+		ss.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrScheduleNotFound, scheduleID)
+	}
+	delete(ss.schedules, scheduleID)
+	taskIDs := schedule.taskIDs()
+	ss.mu.Unlock()
+
+	for taskID := range taskIDs {
+		ss.commentService.ReleaseTask(taskID)
+	}
+	if err := ss.storage.DeleteCredential(storage.ScheduleCredentialID(scheduleID)); err != nil {
+		utils.LogError(fmt.Sprintf("删除定时计划 %s 的认证信息失败: %v", scheduleID, err))
+	}
+
+	return ss.saveSchedules()
+}
+
+// GetSchedule 获取单个定时计划
+func (ss *ScheduleService) GetSchedule(scheduleID string) (*Schedule, error) {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	schedule, exists := ss.schedules[scheduleID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrScheduleNotFound, scheduleID)
+	}
+	return schedule.clone(), nil
+}
+
+// ListSchedules 获取所有定时计划（按创建时间排序）
+func (ss *ScheduleService) ListSchedules() []*Schedule {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	schedules := make([]*Schedule, 0, len(ss.schedules))
+	for _, schedule := range ss.schedules {
+		schedules = append(schedules, schedule.clone())
+	}
+
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].CreatedAt.Before(schedules[j].CreatedAt)
+	})
+
+	return schedules
+}
+
+// RunScheduleNow 立即运行一次定时计划（不影响下次定时运行时间）
+func (ss *ScheduleService) RunScheduleNow(scheduleID string) (*ScheduleRun, error) {
+	ss.mu.RLock()
+	_, exists := ss.schedules[scheduleID]
+	ss.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrScheduleNotFound, scheduleID)
+	}
+
+	run := ss.runSchedule(scheduleID, false)
+	if run == nil {
+		return nil, fmt.Errorf("%w: %s", ErrScheduleNotFound, scheduleID)
+	}
+
+	if err := ss.saveSchedules(); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// validateScheduleSpec 校验参数并填充默认值，account 认证时检查账号是否可用
+func (ss *ScheduleService) validateScheduleSpec(spec *ScheduleSpec) error {
+	if err := normalizeScheduleSpec(spec); err != nil {
+		return err
+	}
+	if spec.AuthType == "account" {
+		if _, err := ss.commentService.accountAuthOptions(spec.AccountID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveScheduleCredential 加密保存计划的认证信息，返回引用ID；没有认证信息时删除已保存的并返回空
+func (ss *ScheduleService) saveScheduleCredential(scheduleID string, spec ScheduleSpec) (string, error) {
+	credentialID := storage.ScheduleCredentialID(scheduleID)
+	credential := &storage.CredentialEntry{Cookie: spec.Cookie, AppKey: spec.AppKey, AppSecret: spec.AppSecret}
+	if credential.IsEmpty() {
+		if err := ss.storage.DeleteCredential(credentialID); err != nil {
+			utils.LogError(fmt.Sprintf("删除定时计划 %s 的认证信息失败: %v", scheduleID, err))
+		}
+		return "", nil
+	}
+
+	if err := ss.storage.SaveCredential(credentialID, credential); err != nil {
+		return "", fmt.Errorf("failed to save credential: %w", err)
+	}
+	return credentialID, nil
+}
+
+// normalizeScheduleSpec 校验参数并填充默认值（与手动创建任务的默认值一致）
+func normalizeScheduleSpec(spec *ScheduleSpec) error {
+	if spec.VideoID == "" {
+		return fmt.Errorf("%w: video_id is required", ErrInvalidSchedule)
+	}
+	if spec.IntervalMinutes < minScheduleInterval {
+		return fmt.Errorf("%w: interval_minutes must be at least %d", ErrInvalidSchedule, minScheduleInterval)
+	}
+	if spec.SortMode == "" {
+		spec.SortMode = "time"
+	}
+	if spec.SortMode != "time" && spec.SortMode != "hot" {
+		return fmt.Errorf("%w: sort_mode must be 'time' or 'hot'", ErrInvalidSchedule)
+	}
+	if spec.PageLimit <= 0 {
+		spec.PageLimit = 2
+	}
+	if spec.DelayMs <= 0 {
+		spec.DelayMs = 300
+	}
+	if spec.Mode == "" {
+		spec.Mode = ScheduleModeFull
+	}
+	if spec.Mode != ScheduleModeFull && spec.Mode != ScheduleModeRefresh {
+		return fmt.Errorf("%w: mode must be '%s' or '%s'", ErrInvalidSchedule, ScheduleModeFull, ScheduleModeRefresh)
+	}
+	switch spec.AuthType {
+	case "":
+		spec.AuthType = "none"
+	case "none", "cookie", "app", "account":
+	default:
+		return fmt.Errorf("%w: unsupported auth_type %q", ErrInvalidSchedule, spec.AuthType)
+	}
+	return nil
+}
+
+// applyScheduleSpec 将参数写入计划（调用方需持有锁）
+func applyScheduleSpec(schedule *Schedule, spec ScheduleSpec, now time.Time) {
+	intervalChanged := schedule.IntervalMinutes != spec.IntervalMinutes
+	enabling := spec.Enabled && !schedule.Enabled
+
+	schedule.VideoID = spec.VideoID
+	schedule.IntervalMinutes = spec.IntervalMinutes
+	schedule.SortMode = spec.SortMode
+	schedule.PageLimit = spec.PageLimit
+	schedule.DelayMs = spec.DelayMs
+	schedule.IncludeReplies = spec.IncludeReplies
+	schedule.Options = spec.Options
+	schedule.Mode = spec.Mode
+	schedule.AuthType = spec.AuthType
+	schedule.Cookie = spec.Cookie
+	schedule.AppKey = spec.AppKey
+	schedule.AppSecret = spec.AppSecret
+	schedule.AccountID = spec.AccountID
+	schedule.Enabled = spec.Enabled
+	schedule.UpdatedAt = now
+	// 抓取参数可能已变化，增量刷新模式下次运行时重新完整抓取
+	schedule.RefreshTaskID = ""
+
+	if intervalChanged || enabling || schedule.NextRunAt.IsZero() {
+		schedule.NextRunAt = now.Add(time.Duration(spec.IntervalMinutes) * time.Minute)
+	}
+}
+
+// scheduleWorker 定期检查并运行到期的计划
+func (ss *ScheduleService) scheduleWorker() {
+	ticker := time.NewTicker(scheduleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ss.ctx.Done():
+			utils.LogInfo("scheduleWorker stopped")
+			return
+		case <-ticker.C:
+			ss.runDueSchedules()
+		}
+	}
+}
+
+// runDueSchedules 运行所有到期的计划
+func (ss *ScheduleService) runDueSchedules() {
+	now := time.Now()
+
+	ss.mu.RLock()
+	var due []string
+	for id, schedule := range ss.schedules {
+		if schedule.Enabled && !now.Before(schedule.NextRunAt) {
+			due = append(due, id)
+		}
+	}
+	ss.mu.RUnlock()
+
+	if len(due) == 0 {
+		return
+	}
+
+	for _, id := range due {
+		ss.runSchedule(id, true)
+	}
+
+	if err := ss.saveSchedules(); err != nil {
+		utils.LogError("Failed to save schedules: " + err.Error())
+	}
+}
+
+// runSchedule 为计划运行一次抓取（完整抓取或增量刷新）并记录运行历史
+// 上一次运行的任务仍在进行时跳过本次，避免同一视频的任务堆积
+// 运行历史引用的任务会被保留，超出 maxScheduleRuns 被移出历史后才按正常规则清理
+// advance 为 true 时推进下次运行时间；计划已被删除时返回 nil
+func (ss *ScheduleService) runSchedule(scheduleID string, advance bool) *ScheduleRun {
+	ss.mu.RLock()
+	schedule, exists := ss.schedules[scheduleID]
+	if !exists {
+		ss.mu.RUnlock()
+		return nil
+	}
+	spec := *schedule
+	lastTaskID := ""
+	for _, run := range schedule.Runs {
+		if run.TaskID != "" {
+			lastTaskID = run.TaskID
+			break
+		}
+	}
+	ss.mu.RUnlock()
+
+	now := time.Now()
+	run := ScheduleRun{StartedAt: now}
+
+	if status, ok := ss.commentService.TaskStatus(lastTaskID); ok && (status == "queued" || status == "running" || status == "paused") {
+		run.Skipped = true
+		utils.LogWarn(fmt.Sprintf("Schedule %s skipped: previous task %s is still %s", scheduleID, lastTaskID, status))
+	} else {
+		taskID, err := ss.startRun(scheduleID, &spec)
+		if err != nil {
+			run.Error = err.Error()
+			utils.LogError(fmt.Sprintf("Schedule %s failed to start task: %v", scheduleID, err))
+		} else {
+			run.TaskID = taskID
+			ss.commentService.KeepTask(taskID)
+			utils.LogInfo(fmt.Sprintf("Schedule %s started task %s for video %s", scheduleID, taskID, spec.VideoID))
+		}
+	}
+
+	ss.mu.Lock()
+	schedule, exists = ss.schedules[scheduleID]
+	if !exists {
+		ss.mu.Unlock()
+		if run.TaskID != "" {
+			ss.commentService.ReleaseTask(run.TaskID)
+		}
+		return nil
+	}
+
+	schedule.LastRunAt = now
+	if advance {
+		schedule.NextRunAt = now.Add(time.Duration(schedule.IntervalMinutes) * time.Minute)
+	}
+	// 计划在运行期间被修改时不记录刷新的任务，下次按新参数重新完整抓取
+	if schedule.Mode == ScheduleModeRefresh && run.TaskID != "" && schedule.UpdatedAt.Equal(spec.UpdatedAt) {
+		schedule.RefreshTaskID = run.TaskID
+	}
+	schedule.Runs = append([]ScheduleRun{run}, schedule.Runs...)
+	var released []string
+	if len(schedule.Runs) > maxScheduleRuns {
+		dropped := schedule.Runs[maxScheduleRuns:]
+		schedule.Runs = schedule.Runs[:maxScheduleRuns]
+		kept := schedule.taskIDs()
+		for _, r := range dropped {
+			if r.TaskID != "" && !kept[r.TaskID] {
+				released = append(released, r.TaskID)
+			}
+		}
+	}
+	ss.mu.Unlock()
+
+	// 移出运行历史的任务不再保留
+	for _, taskID := range released {
+		ss.commentService.ReleaseTask(taskID)
+	}
+
+	return &run
+}
+
+// startRun 按计划的运行方式启动一次抓取，返回任务ID
+// 增量刷新模式下刷新上次的任务；任务不存在或状态不允许刷新时改为完整抓取
+func (ss *ScheduleService) startRun(scheduleID string, schedule *Schedule) (string, error) {
+	if schedule.Mode == ScheduleModeRefresh && schedule.RefreshTaskID != "" {
+		err := ss.commentService.RefreshTask(schedule.RefreshTaskID, schedule.PageLimit)
+		if err == nil {
+			return schedule.RefreshTaskID, nil
+		}
+		if !errors.Is(err, ErrTaskNotFound) && !errors.Is(err, ErrTaskInvalidState) {
+			return "", err
+		}
+		utils.LogWarn(fmt.Sprintf("Schedule %s cannot refresh task %s, starting a full scrape: %v", scheduleID, schedule.RefreshTaskID, err))
+	}
+
+	return ss.commentService.StartScrapeTask(ScrapeSpec{
+		VideoID:        schedule.VideoID,
+		CommentType:    bilibili.CommentTypeVideo,
+		AuthType:       schedule.AuthType,
+		Cookie:         schedule.Cookie,
+		AppKey:         schedule.AppKey,
+		AppSecret:      schedule.AppSecret,
+		AccountID:      schedule.AccountID,
+		SortMode:       schedule.SortMode,
+		IncludeReplies: schedule.IncludeReplies,
+		PageLimit:      schedule.PageLimit,
+		DelayMs:        schedule.DelayMs,
+		Priority:       PriorityLow,
+		Options:        schedule.Options,
+	})
+}
+
+// loadSchedules 从存储加载定时计划
+func (ss *ScheduleService) loadSchedules() {
+	index, err := ss.storage.LoadSchedules()
+	if err != nil {
+		utils.LogError("加载定时计划失败: " + err.Error())
+		return
+	}
+
+	for _, entry := range index.Schedules {
+		schedule := convertScheduleFromStorage(entry)
+		ss.loadScheduleCredential(schedule)
+		// 运行历史引用的任务不参与旧任务清理
+		for taskID := range schedule.taskIDs() {
+			ss.commentService.KeepTask(taskID)
+		}
+		ss.schedules[entry.ScheduleID] = schedule
+	}
+
+	utils.LogInfo(fmt.Sprintf("从存储加载 %d 个定时计划", len(index.Schedules)))
+}
+
+// loadScheduleCredential 读取计划的认证信息，读取失败时记录日志并按无认证信息运行
+func (ss *ScheduleService) loadScheduleCredential(schedule *Schedule) {
+	if schedule.CredentialID == "" {
+		return
+	}
+
+	credential, err := ss.storage.LoadCredential(schedule.CredentialID)
+	if err != nil {
+		utils.LogWarn(fmt.Sprintf("定时计划 %s 读取认证信息失败，将不带认证运行: %v", schedule.ScheduleID, err))
+		return
+	}
+	schedule.Cookie = credential.Cookie
+	schedule.AppKey = credential.AppKey
+	schedule.AppSecret = credential.AppSecret
+}
+
+// saveSchedules 持久化全部定时计划
+func (ss *ScheduleService) saveSchedules() error {
+	ss.mu.RLock()
+	entries := make([]storage.ScheduleEntry, 0, len(ss.schedules))
+	for _, schedule := range ss.schedules {
+		entries = append(entries, convertScheduleToStorage(schedule))
+	}
+	ss.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+
+	return ss.storage.SaveSchedules(&storage.ScheduleIndex{Schedules: entries})
+}
+
+// convertScheduleToStorage 转换为存储层格式
+func convertScheduleToStorage(s *Schedule) storage.ScheduleEntry {
+	runs := make([]storage.ScheduleRunEntry, len(s.Runs))
+	for i, r := range s.Runs {
+		runs[i] = storage.ScheduleRunEntry{
+			TaskID:    r.TaskID,
+			StartedAt: r.StartedAt,
+			Skipped:   r.Skipped,
+			Error:     r.Error,
+		}
+	}
+
+	return storage.ScheduleEntry{
+		ScheduleID:      s.ScheduleID,
+		VideoID:         s.VideoID,
+		IntervalMinutes: s.IntervalMinutes,
+		SortMode:        s.SortMode,
+		PageLimit:       s.PageLimit,
+		DelayMs:         s.DelayMs,
+		IncludeReplies:  s.IncludeReplies,
+		Options:         scrapeOptionsToStorage(s.Options),
+		Mode:            s.Mode,
+		RefreshTaskID:   s.RefreshTaskID,
+		AuthType:        s.AuthType,
+		CredentialID:    s.CredentialID,
+		AccountID:       s.AccountID,
+		Enabled:         s.Enabled,
+		CreatedAt:       s.CreatedAt,
+		UpdatedAt:       s.UpdatedAt,
+		LastRunAt:       s.LastRunAt,
+		NextRunAt:       s.NextRunAt,
+		Runs:            runs,
+	}
+}
+
+// convertScheduleFromStorage 从存储层格式转换
+func convertScheduleFromStorage(e storage.ScheduleEntry) *Schedule {
+	runs := make([]ScheduleRun, len(e.Runs))
+	for i, r := range e.Runs {
+		runs[i] = ScheduleRun{
+			TaskID:    r.TaskID,
+			StartedAt: r.StartedAt,
+			Skipped:   r.Skipped,
+			Error:     r.Error,
+		}
+	}
+
+	// 旧版本的计划没有运行方式和认证方式，按完整抓取、无认证处理
+	mode := e.Mode
+	if mode == "" {
+		mode = ScheduleModeFull
+	}
+	authType := e.AuthType
+	if authType == "" {
+		authType = "none"
+	}
+
+	return &Schedule{
+		ScheduleID:      e.ScheduleID,
+		VideoID:         e.VideoID,
+		IntervalMinutes: e.IntervalMinutes,
+		SortMode:        e.SortMode,
+		PageLimit:       e.PageLimit,
+		DelayMs:         e.DelayMs,
+		IncludeReplies:  e.IncludeReplies,
+		Options:         scrapeOptionsFromStorage(e.Options),
+		Mode:            mode,
+		RefreshTaskID:   e.RefreshTaskID,
+		AuthType:        authType,
+		CredentialID:    e.CredentialID,
+		AccountID:       e.AccountID,
+		Enabled:         e.Enabled,
+		CreatedAt:       e.CreatedAt,
+		UpdatedAt:       e.UpdatedAt,
+		LastRunAt:       e.LastRunAt,
+		NextRunAt:       e.NextRunAt,
+		Runs:            runs,
+	}
+}
+
+// Shutdown 优雅关闭服务
+func (ss *ScheduleService) Shutdown(ctx context.Context) error {
+	utils.LogInfo("Shutting down ScheduleService...")
+
+	ss.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		ss.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		if err := ss.saveSchedules(); err != nil {
+			return err
+		}
+		utils.LogInfo("ScheduleService shutdown complete")
+		return nil
+	case <-ctx.Done():
+		utils.LogError("ScheduleService shutdown timeout")
+		return ctx.Err()
+	}
+}
@@ -1,20 +1,143 @@
 package services
 
-// User 用户结构体
-type User struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"bilibili/pkg/bilibili"
+)
+
+// ErrUserNotFound 用户不存在
+var ErrUserNotFound = errors.New("user not found")
+
+const (
+	// userCacheTTL 用户信息缓存有效期
+	userCacheTTL = 10 * time.Minute
+	// maxCachedUsers 最多缓存的用户数，超出时先清理过期项
+	maxCachedUsers = 1000
+)
+
+// UserService 用户服务，查询用户资料和关注/粉丝数，结果在内存中缓存一段时间
+type UserService struct {
+	client *bilibili.BilibiliClient
+	mu     sync.Mutex
+	cache  map[int64]*UserProfile
+}
+
+// UserProfile 用户资料
+type UserProfile struct {
+	Mid           int64     `json:"mid"`
+	Name          string    `json:"name"`
+	Sex           string    `json:"sex"`
+	Sign          string    `json:"sign"`
+	Level         int       `json:"level"`
+	Face          string    `json:"face"`
+	Birthday      string    `json:"birthday"`
+	Banned        bool      `json:"banned"`
+	VipType       int       `json:"vip_type"`  // 0无，1月度大会员，2年度及以上大会员（未生效时为0）
+	VipLabel      string    `json:"vip_label"` // 如"年度大会员"
+	OfficialTitle string    `json:"official_title"`
+	LiveRoomID    int64     `json:"live_room_id"`
+	Living        bool      `json:"living"`
+	Following     int       `json:"following"`
+	Follower      int       `json:"follower"`
+	FetchedAt     time.Time `json:"fetched_at"`
+}
+
+// NewUserService 创建用户服务
+func NewUserService(client *bilibili.BilibiliClient) *UserService {
+	if client == nil {
+		client = bilibili.DefaultClient()
+	}
+	return &UserService{
+		client: client,
+		cache:  make(map[int64]*UserProfile),
+	}
 }
 
-// GetUserByID 根据ID获取用户信息
-func GetUserByID(id int) (*User, error) {
-	// 这里应该从数据库或其他数据源获取用户信息
-	// 现在我们只是返回一个示例用户
-	if id == 1 {
-		return &User{
-			ID:   1,
-			Name: "Bilibili User",
-		}, nil
-	}
-	return nil, nil
-}
\ No newline at end of file
+// GetUser 获取用户资料，refresh 为 true 时忽略缓存重新查询
+func (us *UserService) GetUser(ctx context.Context, mid int64, refresh bool) (*UserProfile, error) {
+	if mid <= 0 {
+		return nil, fmt.Errorf("%w: invalid mid %d", ErrUserNotFound, mid)
+	}
+
+	if !refresh {
+		if profile, ok := us.cached(mid); ok {
+			return profile, nil
+		}
+	}
+
+	userResp, err := us.client.GetUser(ctx, mid)
+	if err != nil {
+		if errors.Is(err, bilibili.ErrUserNotFound) {
+			return nil, fmt.Errorf("%w: %d", ErrUserNotFound, mid)
+		}
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+
+	statResp, err := us.client.GetRelationStat(ctx, mid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relation stat: %w", err)
+	}
+
+	info := userResp.Data
+	profile := &UserProfile{
+		Mid:           mid,
+		Name:          info.Name,
+		Sex:           info.Sex,
+		Sign:          info.Sign,
+		Level:         info.Level,
+		Face:          info.Face,
+		Birthday:      info.Birthday,
+		Banned:        info.Silence == 1,
+		VipLabel:      info.Vip.Label.Text,
+		OfficialTitle: info.Official.Title,
+		LiveRoomID:    info.LiveRoom.RoomID,
+		Living:        info.LiveRoom.LiveStatus == 1,
+		Following:     statResp.Data.Following,
+		Follower:      statResp.Data.Follower,
+		FetchedAt:     time.Now(),
+	}
+	if info.Vip.Status == 1 {
+		profile.VipType = info.Vip.Type
+	}
+
+	us.store(profile)
+
+	copied := *profile
+	return &copied, nil
+}
+
+// cached 获取未过期的缓存
+func (us *UserService) cached(mid int64) (*UserProfile, bool) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	profile, ok := us.cache[mid]
+	if !ok || time.Since(profile.FetchedAt) > userCacheTTL {
+		return nil, false
+	}
+	copied := *profile
+	return &copied, true
+}
+
+// store 写入缓存，缓存已满时先清理过期项，仍然已满则清空
+func (us *UserService) store(profile *UserProfile) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	if len(us.cache) >= maxCachedUsers {
+		for mid, p := range us.cache {
+			if time.Since(p.FetchedAt) > userCacheTTL {
+				delete(us.cache, mid)
+			}
+		}
+		if len(us.cache) >= maxCachedUsers {
+			us.cache = make(map[int64]*UserProfile)
+		}
+	}
+	us.cache[profile.Mid] = profile
+}
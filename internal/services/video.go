@@ -1,77 +1,219 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"bilibili/pkg/bilibili"
 )
 
+var (
+	// ErrVideoPageNotFound 视频不存在指定的分P
+	ErrVideoPageNotFound = errors.New("video page not found")
+	// ErrInvalidInput 输入的参数不合法（如不支持的评论区类型）
+	ErrInvalidInput = errors.New("invalid input")
+)
+
+// AllPages 选择视频的全部分P
+const AllPages = 0
+
 // VideoService 视频服务
-type VideoService struct{}
+type VideoService struct {
+	client *bilibili.BilibiliClient
+}
 
 // NewVideoService 创建视频服务
-func NewVideoService() *VideoService {
-	return &VideoService{}
+func NewVideoService(client *bilibili.BilibiliClient) *VideoService {
+	if client == nil {
+		client = bilibili.DefaultClient()
+	}
+	return &VideoService{
+		client: client,
+	}
 }
 
 // VideoInfo 视频信息
 type VideoInfo struct {
-	BVID          string `json:"bvid"`
-	AID           int64  `json:"aid"`
-	Title         string `json:"title"`
-	Author        string `json:"author"`
-	Views         int    `json:"views"`
-	CommentsTotal int    `json:"comments_total"`
-	Likes         int    `json:"likes"`
-	CreatedTime   int64  `json:"created_time"`
-	PicURL        string `json:"pic_url"`
-	Description   string `json:"description"`
+	BVID          string      `json:"bvid"`
+	AID           int64       `json:"aid"`
+	Title         string      `json:"title"`
+	Author        string      `json:"author"`
+	Views         int         `json:"views"`
+	CommentsTotal int         `json:"comments_total"`
+	Likes         int         `json:"likes"`
+	CreatedTime   int64       `json:"created_time"`
+	PicURL        string      `json:"pic_url"`
+	Description   string      `json:"description"`
+	Duration      int         `json:"duration"` // 全部分P的总时长（秒）
+	Pages         []VideoPage `json:"pages"`
 }
 
-// ParseVideoInput 解析视频输入（支持BV号、AV号、URL）
-func (vs *VideoService) ParseVideoInput(input string) (videoID string, videoType string, err error) {
-	input = strings.TrimSpace(input)
+// VideoPage 视频分P信息
+type VideoPage struct {
+	Page     int    `json:"page"` // 分P序号，从1开始
+	CID      int64  `json:"cid"`
+	Title    string `json:"title"`
+	Duration int    `json:"duration"` // 分P时长（秒）
+}
 
-	// 匹配 BV 号（直接输入或URL中）
-	bvPattern := regexp.MustCompile(`(BV[a-zA-Z0-9]+)`)
+// BV号和AV号必须是独立的片段（整个输入、URL路径的一段或分享文本中的一个词），前缀不区分大小写
+// 避免把 URL 或文本中碰巧包含 "av"+数字 的部分当作视频ID
+var (
+	// bvPattern 独立的BV号："BV" 加10位字符，前后不能紧邻字母或数字
+	bvPattern = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(bv[a-z0-9]{10})(?:[^a-z0-9]|$)`)
+	// avPattern 独立的AV号："av" 加数字，前后不能紧邻字母或数字
+	avPattern = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])av(\d+)(?:[^a-z0-9]|$)`)
+)
+
+// ParseVideoInput 解析视频输入（支持BV号、AV号、网页/移动端URL、b23.tv 短链接和包含链接的分享文本）
+// AV号离线转换为BV号，返回的 videoID 统一为BV号，videoType 表示输入的形式（bv 或 av）
+func (vs *VideoService) ParseVideoInput(ctx context.Context, input string) (videoID string, videoType string, err error) {
+	input, err = vs.resolveShortLink(ctx, strings.TrimSpace(input))
+	if err != nil {
+		return "", "", err
+	}
+
+	// 匹配 BV 号（直接输入或URL中），前缀统一为大写
 	if matches := bvPattern.FindStringSubmatch(input); len(matches) > 0 {
-		return matches[1], "bv", nil
+		bvid := "BV" + matches[1][2:]
+		if _, err := bilibili.BVToAV(bvid); err != nil {
+			return "", "", fmt.Errorf("invalid video ID format: %s", matches[1])
+		}
+		return bvid, "bv", nil
 	}
 
 	// 匹配 AV 号（直接输入或URL中）
-	avPattern := regexp.MustCompile(`[aA][vV](\d+)`)
 	if matches := avPattern.FindStringSubmatch(input); len(matches) > 0 {
-		return "av" + matches[1], "av", nil
+		return avToVideoID(matches[1])
+	}
+
+	// 匹配 APP 分享链接（bilibili://video/{aid}）和移动端URL中的 aid 参数
+	appPattern := regexp.MustCompile(`bilibili://video/(\d+)|[?&](?:aid|avid)=(\d+)`)
+	if matches := appPattern.FindStringSubmatch(input); len(matches) > 0 {
+		return avToVideoID(matches[1] + matches[2])
 	}
 
 	// 匹配纯数字（假定为AV号）
 	numPattern := regexp.MustCompile(`^\d+$`)
 	if numPattern.MatchString(input) {
-		return "av" + input, "av", nil
+		return avToVideoID(input)
 	}
 
 	return "", "", fmt.Errorf("invalid video ID format: %s", input)
 }
 
-// GetVideoInfo 获取视频信息
-func (vs *VideoService) GetVideoInfo(input string) (*VideoInfo, error) {
-	videoID, videoType, err := vs.ParseVideoInput(input)
+// avToVideoID 将AV号离线转换为BV号，AV号超出可转换范围时返回错误
+func avToVideoID(digits string) (videoID string, videoType string, err error) {
+	aid, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || aid <= 0 {
+		return "", "", fmt.Errorf("invalid video ID format: av%s", digits)
+	}
+	bvid := bilibili.AVToBV(aid)
+	if back, err := bilibili.BVToAV(bvid); err != nil || back != aid {
+		return "", "", fmt.Errorf("invalid video ID format: av%s", digits)
+	}
+	return bvid, "av", nil
+}
+
+// resolveShortLink 将输入中的 b23.tv 短链接替换为跳转后的完整地址，不含短链接时原样返回
+// 短链接路径本身就是BV号或AV号时（如 b23.tv/BV1xx411c7mu）无需请求
+func (vs *VideoService) resolveShortLink(ctx context.Context, input string) (string, error) {
+	shortPattern := regexp.MustCompile(`(?:https?://)?(?:b23\.tv|bili2233\.cn)/([A-Za-z0-9]+)`)
+	matches := shortPattern.FindStringSubmatch(input)
+	if len(matches) == 0 || regexp.MustCompile(`(?i)^(?:bv|av\d)`).MatchString(matches[1]) {
+		return input, nil
+	}
+
+	shortURL := matches[0]
+	if !strings.HasPrefix(shortURL, "http") {
+		shortURL = "https://" + shortURL
+	}
+
+	resolved, err := vs.client.ResolveShortLink(ctx, shortURL)
 	if err != nil {
-		return nil, err
+		return "", fmt.Errorf("failed to resolve short link %s: %w", shortURL, err)
+	}
+	return resolved, nil
+}
+
+// ParseCommentTarget 解析评论区输入（视频、动态、专栏、音频的ID或URL）
+// 返回规范化的目标ID和评论区类型：视频为BV/av号，专栏为cv号，音频为au号，动态为动态ID
+// commentType 为0时根据输入自动识别（无法识别时按视频处理）；指定类型时数字输入直接作为对应评论区的ID
+// 只支持视频、动态、专栏和音频评论区，其他类型返回 ErrInvalidInput
+func (vs *VideoService) ParseCommentTarget(ctx context.Context, input string, commentType int) (targetID string, resolvedType int, err error) {
+	switch commentType {
+	case 0, bilibili.CommentTypeVideo, bilibili.CommentTypeDynamic, bilibili.CommentTypeDynamicDraw,
+		bilibili.CommentTypeArticle, bilibili.CommentTypeAudio:
+	default:
+		return "", 0, fmt.Errorf("%w: unsupported comment_type %d", ErrInvalidInput, commentType)
 	}
 
-	var videoResp *bilibili.VideoResponse
+	// 分享的短链接可能指向视频、动态或专栏，先解析为完整地址
+	input, err = vs.resolveShortLink(ctx, strings.TrimSpace(input))
+	if err != nil {
+		return "", 0, err
+	}
+
+	if commentType == 0 {
+		// 动态：t.bilibili.com/{id}、www.bilibili.com/opus/{id}、m.bilibili.com/dynamic/{id}
+		dynamicPattern := regexp.MustCompile(`(?:t\.bilibili\.com|bilibili\.com/opus|bilibili\.com/dynamic)/(\d+)`)
+		if matches := dynamicPattern.FindStringSubmatch(input); len(matches) > 0 {
+			return matches[1], bilibili.CommentTypeDynamic, nil
+		}
+
+		// 专栏：cv号或 www.bilibili.com/read/cv{id}
+		articlePattern := regexp.MustCompile(`(?i)^cv(\d+)$|/read/(?:mobile/|cv)(\d+)`)
+		if matches := articlePattern.FindStringSubmatch(input); len(matches) > 0 {
+			return "cv" + matches[1] + matches[2], bilibili.CommentTypeArticle, nil
+		}
+
+		// 音频：au号或 www.bilibili.com/audio/au{id}
+		audioPattern := regexp.MustCompile(`(?i)^au(\d+)$|/audio/au(\d+)`)
+		if matches := audioPattern.FindStringSubmatch(input); len(matches) > 0 {
+			return "au" + matches[1] + matches[2], bilibili.CommentTypeAudio, nil
+		}
 
-	// 根据类型获取视频信息
-	if videoType == "bv" {
-		videoResp, err = bilibili.GetVideoByBVID(videoID)
-	} else {
-		// AV号需要去掉"av"前缀
-		videoResp, err = bilibili.GetVideoByBVID(videoID) // 优先尝试BV
+		commentType = bilibili.CommentTypeVideo
 	}
 
+	if commentType == bilibili.CommentTypeVideo {
+		videoID, _, err := vs.ParseVideoInput(ctx, input)
+		if err != nil {
+			return "", 0, err
+		}
+		return videoID, commentType, nil
+	}
+
+	// 其他类型取输入（或URL）中的最后一段数字作为ID
+	numbers := regexp.MustCompile(`\d+`).FindAllString(input, -1)
+	if len(numbers) == 0 {
+		return "", 0, fmt.Errorf("invalid %s ID format: %s", bilibili.CommentTypeName(commentType), input)
+	}
+	id := numbers[len(numbers)-1]
+
+	switch commentType {
+	case bilibili.CommentTypeArticle:
+		return "cv" + id, commentType, nil
+	case bilibili.CommentTypeAudio:
+		return "au" + id, commentType, nil
+	default:
+		return id, commentType, nil
+	}
+}
+
+// GetVideoInfo 获取视频信息
+func (vs *VideoService) GetVideoInfo(ctx context.Context, input string) (*VideoInfo, error) {
+	videoID, _, err := vs.ParseVideoInput(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// 根据类型获取视频信息（AV号使用 aid 查询）
+	videoResp, err := getVideo(ctx, vs.client, videoID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get video info: %v", err)
 	}
@@ -90,7 +232,31 @@ func (vs *VideoService) GetVideoInfo(input string) (*VideoInfo, error) {
 		Likes:       videoResp.Data.Stat.Like,
 		PicURL:      videoResp.Data.Pic,
 		Description: videoResp.Data.Desc,
+		Duration:    videoResp.Data.Duration,
+	}
+
+	for _, page := range videoResp.Data.PageList() {
+		info.Pages = append(info.Pages, VideoPage{
+			Page:     page.Page,
+			CID:      page.CID,
+			Title:    page.Part,
+			Duration: page.Duration,
+		})
 	}
 
 	return info, nil
 }
+
+// selectVideoPages 按分P序号选择要处理的分P，page 为 AllPages 时返回全部分P
+func selectVideoPages(video *bilibili.VideoInfo, page int) ([]bilibili.VideoPage, error) {
+	pages := video.PageList()
+	if page == AllPages {
+		return pages, nil
+	}
+	for _, p := range pages {
+		if p.Page == page {
+			return []bilibili.VideoPage{p}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: P%d (video has %d pages)", ErrVideoPageNotFound, page, len(pages))
+}
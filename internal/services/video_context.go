@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"bilibili/pkg/bilibili"
+	"bilibili/pkg/storage"
+	"bilibili/pkg/utils"
+)
+
+const (
+	// maxSubtitleParts 最多获取字幕的分P数（从P1开始），避免分P很多的视频产生大量请求
+	maxSubtitleParts = 10
+	// maxSubtitleRunes 渲染Prompt时字幕最多保留的字数，避免超出模型上下文
+	maxSubtitleRunes = 20000
+)
+
+// VideoContext 视频内容信息（简介、标签和字幕），供AI分析时对照评论
+type VideoContext struct {
+	Description  string         `json:"description"`
+	Tags         []string       `json:"tags"`
+	SubtitleLang string         `json:"subtitle_lang"` // 字幕语言名称，无字幕时为空
+	Subtitles    []SubtitleLine `json:"subtitles"`
+	FetchedAt    time.Time      `json:"fetched_at"`
+}
+
+// SubtitleLine 一句字幕
+type SubtitleLine struct {
+	Page    int     `json:"page"` // 所属分P序号
+	From    float64 `json:"from"` // 开始时间（秒）
+	To      float64 `json:"to"`   // 结束时间（秒）
+	Content string  `json:"content"`
+}
+
+// fetchVideoContext 获取视频的简介、标签和字幕
+// 标签和字幕获取失败不影响结果，只记录日志；字幕按分P的 cid 分别获取
+func fetchVideoContext(ctx context.Context, client *bilibili.BilibiliClient, video *bilibili.VideoInfo, opts []bilibili.CommentOption) *VideoContext {
+	videoContext := &VideoContext{
+		Description: strings.TrimSpace(video.Desc),
+		Tags:        []string{},
+		Subtitles:   []SubtitleLine{},
+		FetchedAt:   time.Now(),
+	}
+
+	if tagsResp, err := client.GetVideoTags(ctx, video.BVID); err != nil {
+		utils.LogError(fmt.Sprintf("获取视频 %s 标签失败: %v", video.BVID, err))
+	} else {
+		for _, tag := range tagsResp.Data {
+			videoContext.Tags = append(videoContext.Tags, tag.TagName)
+		}
+	}
+
+	pages, _ := selectVideoPages(video, AllPages)
+	if len(pages) > maxSubtitleParts {
+		pages = pages[:maxSubtitleParts]
+	}
+	for _, page := range pages {
+		if ctx.Err() != nil {
+			break
+		}
+
+		playerResp, err := client.GetPlayerInfo(ctx, video.AID, page.CID, opts...)
+		if err != nil {
+			utils.LogError(fmt.Sprintf("获取视频 %s P%d 字幕列表失败: %v", video.BVID, page.Page, err))
+			continue
+		}
+		subtitle, ok := pickSubtitle(playerResp.Data.Subtitle.Subtitles)
+		if !ok {
+			continue
+		}
+
+		lines, err := client.GetSubtitle(ctx, subtitle.SubtitleURL)
+		if err != nil {
+			utils.LogError(fmt.Sprintf("下载视频 %s P%d 字幕失败: %v", video.BVID, page.Page, err))
+			continue
+		}
+		if videoContext.SubtitleLang == "" {
+			videoContext.SubtitleLang = subtitle.LanDoc
+		}
+		for _, line := range lines {
+			videoContext.Subtitles = append(videoContext.Subtitles, SubtitleLine{
+				Page:    page.Page,
+				From:    line.From,
+				To:      line.To,
+				Content: line.Content,
+			})
+		}
+	}
+
+	return videoContext
+}
+
+// pickSubtitle 选择用于分析的字幕：优先人工上传的中文字幕，其次AI中文字幕，最后任意字幕
+func pickSubtitle(subtitles []bilibili.SubtitleInfo) (bilibili.SubtitleInfo, bool) {
+	if len(subtitles) == 0 {
+		return bilibili.SubtitleInfo{}, false
+	}
+
+	best, bestScore := subtitles[0], -1
+	for _, s := range subtitles {
+		score := 0
+		if strings.Contains(s.Lan, "zh") {
+			score += 2
+		}
+		if !s.IsAI() {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = s, score
+		}
+	}
+	return best, true
+}
+
+// videoContextToStorage 转换为存储层格式
+func videoContextToStorage(vc *VideoContext) *storage.VideoContextEntry {
+	if vc == nil {
+		return nil
+	}
+	entry := &storage.VideoContextEntry{
+		Description:  vc.Description,
+		Tags:         vc.Tags,
+		SubtitleLang: vc.SubtitleLang,
+		FetchedAt:    vc.FetchedAt,
+	}
+	for _, line := range vc.Subtitles {
+		entry.Subtitles = append(entry.Subtitles, storage.SubtitleEntry{
+			Page:    line.Page,
+			From:    line.From,
+			To:      line.To,
+			Content: line.Content,
+		})
+	}
+	return entry
+}
+
+// videoContextFromStorage 从存储层格式转换
+func videoContextFromStorage(e *storage.VideoContextEntry) *VideoContext {
+	if e == nil {
+		return nil
+	}
+	vc := &VideoContext{
+		Description:  e.Description,
+		Tags:         e.Tags,
+		SubtitleLang: e.SubtitleLang,
+		Subtitles:    make([]SubtitleLine, 0, len(e.Subtitles)),
+		FetchedAt:    e.FetchedAt,
+	}
+	if vc.Tags == nil {
+		vc.Tags = []string{}
+	}
+	for _, line := range e.Subtitles {
+		vc.Subtitles = append(vc.Subtitles, SubtitleLine{
+			Page:    line.Page,
+			From:    line.From,
+			To:      line.To,
+			Content: line.Content,
+		})
+	}
+	return vc
+}
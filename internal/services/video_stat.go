@@ -0,0 +1,482 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"bilibili/pkg/bilibili"
+	"bilibili/pkg/storage"
+	"bilibili/pkg/utils"
+)
+
+var (
+	// ErrVideoStatsNotFound 视频没有快照记录
+	ErrVideoStatsNotFound = errors.New("video stats not found")
+	// ErrInvalidStatWatch 定时记录参数不合法
+	ErrInvalidStatWatch = errors.New("invalid stat watch")
+)
+
+// 快照来源
+const (
+	StatSourceTask   = "task"   // 抓取任务运行时记录
+	StatSourcePoll   = "poll"   // 定时记录
+	StatSourceManual = "manual" // 手动记录
+)
+
+const (
+	// statCheckInterval 检查到期定时记录的间隔
+	statCheckInterval = 30 * time.Second
+	// minStatWatchInterval 定时记录允许的最小间隔（分钟）
+	minStatWatchInterval = 5
+	// maxVideoSnapshots 每个视频保留的快照数，超出时丢弃最早的
+	maxVideoSnapshots = 10000
+)
+
+// VideoStatService 视频数据快照服务，记录播放、点赞、评论数等随时间的变化
+// 快照在抓取任务运行时自动记录，也可以为视频开启定时记录
+type VideoStatService struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	storage storage.VideoStatStorage
+	client  *bilibili.BilibiliClient
+	watches map[string]*VideoStatWatch // key 为BV号
+}
+
+// VideoStatSnapshot 视频数据快照
+type VideoStatSnapshot struct {
+	Time     time.Time `json:"time"`
+	Source   string    `json:"source"`
+	View     int       `json:"view"`
+	Danmaku  int       `json:"danmaku"`
+	Reply    int       `json:"reply"`
+	Favorite int       `json:"favorite"`
+	Coin     int       `json:"coin"`
+	Share    int       `json:"share"`
+	Like     int       `json:"like"`
+}
+
+// VideoStatPoint 时间序列中的一个点，包含与上一个快照相比的增量和每小时增速
+type VideoStatPoint struct {
+	VideoStatSnapshot
+	ViewDelta      int     `json:"view_delta"`
+	ReplyDelta     int     `json:"reply_delta"`
+	LikeDelta      int     `json:"like_delta"`
+	DanmakuDelta   int     `json:"danmaku_delta"`
+	ViewsPerHour   float64 `json:"views_per_hour"`
+	RepliesPerHour float64 `json:"replies_per_hour"`
+}
+
+// VideoStatSeries 视频数据时间序列
+type VideoStatSeries struct {
+	BVID                 string           `json:"bvid"`
+	AID                  int64            `json:"aid"`
+	Title                string           `json:"title"`
+	WatchIntervalMinutes int              `json:"watch_interval_minutes"`
+	TotalSnapshots       int              `json:"total_snapshots"`
+	Points               []VideoStatPoint `json:"points"`
+}
+
+// VideoStatWatch 视频的定时记录
+type VideoStatWatch struct {
+	BVID            string    `json:"bvid"`
+	Title           string    `json:"title"`
+	IntervalMinutes int       `json:"interval_minutes"`
+	LastAt          time.Time `json:"last_at"`
+	NextAt          time.Time `json:"next_at"`
+}
+
+// NewVideoStatService 创建视频数据快照服务
+func NewVideoStatService(ctx context.Context, statStorage storage.VideoStatStorage, client *bilibili.BilibiliClient) *VideoStatService {
+	if client == nil {
+		client = bilibili.DefaultClient()
+	}
+	serviceCtx, cancel := context.WithCancel(ctx)
+
+	vs := &VideoStatService{
+		ctx:     serviceCtx,
+		cancel:  cancel,
+		storage: statStorage,
+		client:  client,
+		watches: make(map[string]*VideoStatWatch),
+	}
+
+	// 启动时加载已开启的定时记录
+	vs.loadWatches()
+
+	vs.wg.Add(1)
+	go func() {
+		defer vs.wg.Done()
+		vs.watchWorker()
+	}()
+
+	return vs
+}
+
+// Record 记录视频当前的数据快照
+func (vs *VideoStatService) Record(video *bilibili.VideoInfo, source string) (*VideoStatSnapshot, error) {
+	if video == nil || video.BVID == "" {
+		return nil, fmt.Errorf("video info is required")
+	}
+
+	snapshot := storage.VideoStatSnapshot{
+		Time:     time.Now(),
+		Source:   source,
+		View:     video.Stat.View,
+		Danmaku:  video.Stat.Danmaku,
+		Reply:    video.Stat.Reply,
+		Favorite: video.Stat.Favorite,
+		Coin:     video.Stat.Coin,
+		Share:    video.Stat.Share,
+		Like:     video.Stat.Like,
+	}
+
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	history, err := vs.loadHistory(video.BVID)
+	if err != nil {
+		return nil, err
+	}
+	history.AID = video.AID
+	history.Title = video.Title
+	history.Snapshots = append(history.Snapshots, snapshot)
+	if len(history.Snapshots) > maxVideoSnapshots {
+		history.Snapshots = history.Snapshots[len(history.Snapshots)-maxVideoSnapshots:]
+	}
+
+	if err := vs.storage.SaveVideoStats(history); err != nil {
+		return nil, fmt.Errorf("failed to save video stats: %w", err)
+	}
+
+	if watch, ok := vs.watches[video.BVID]; ok {
+		watch.Title = video.Title
+		watch.LastAt = snapshot.Time
+	}
+
+	result := snapshotFromStorage(snapshot)
+	return &result, nil
+}
+
+// Snapshot 立即获取视频数据并记录快照
+func (vs *VideoStatService) Snapshot(ctx context.Context, videoID string) (*VideoStatSnapshot, error) {
+	return vs.fetchAndRecord(ctx, videoID, StatSourceManual)
+}
+
+// GetSeries 获取视频在时间范围内的快照序列（零值表示不限制）
+func (vs *VideoStatService) GetSeries(videoID string, since, until time.Time) (*VideoStatSeries, error) {
+	bvid, err := statBVID(videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	vs.mu.Lock()
+	history, err := vs.storage.LoadVideoStats(bvid)
+	vs.mu.Unlock()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrVideoStatsNotFound, bvid)
+		}
+		return nil, err
+	}
+
+	series := &VideoStatSeries{
+		BVID:                 history.BVID,
+		AID:                  history.AID,
+		Title:                history.Title,
+		WatchIntervalMinutes: history.WatchIntervalMinutes,
+		TotalSnapshots:       len(history.Snapshots),
+		Points:               []VideoStatPoint{},
+	}
+
+	var prev *storage.VideoStatSnapshot
+	for i := range history.Snapshots {
+		snapshot := history.Snapshots[i]
+		inRange := (since.IsZero() || !snapshot.Time.Before(since)) && (until.IsZero() || !snapshot.Time.After(until))
+		if inRange {
+			point := VideoStatPoint{VideoStatSnapshot: snapshotFromStorage(snapshot)}
+			// 增量相对于前一个快照计算（包括范围外的），保证范围内第一个点也有增量
+			if prev != nil {
+				point.ViewDelta = snapshot.View - prev.View
+				point.ReplyDelta = snapshot.Reply - prev.Reply
+				point.LikeDelta = snapshot.Like - prev.Like
+				point.DanmakuDelta = snapshot.Danmaku - prev.Danmaku
+				if hours := snapshot.Time.Sub(prev.Time).Hours(); hours > 0 {
+					point.ViewsPerHour = float64(point.ViewDelta) / hours
+					point.RepliesPerHour = float64(point.ReplyDelta) / hours
+				}
+			}
+			series.Points = append(series.Points, point)
+		}
+		prev = &history.Snapshots[i]
+	}
+
+	return series, nil
+}
+
+// ListVideos 列出所有有快照记录的视频（最新的快照在前，不含快照数据）
+func (vs *VideoStatService) ListVideos() ([]VideoStatSeries, error) {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	bvids, err := vs.storage.ListVideoStats()
+	if err != nil {
+		return nil, err
+	}
+
+	videos := make([]VideoStatSeries, 0, len(bvids))
+	latest := make(map[string]time.Time, len(bvids))
+	for _, bvid := range bvids {
+		history, err := vs.storage.LoadVideoStats(bvid)
+		if err != nil {
+			utils.LogError(fmt.Sprintf("加载视频快照 %s 失败: %v", bvid, err))
+			continue
+		}
+		video := VideoStatSeries{
+			BVID:                 history.BVID,
+			AID:                  history.AID,
+			Title:                history.Title,
+			WatchIntervalMinutes: history.WatchIntervalMinutes,
+			TotalSnapshots:       len(history.Snapshots),
+			Points:               []VideoStatPoint{},
+		}
+		if n := len(history.Snapshots); n > 0 {
+			last := history.Snapshots[n-1]
+			video.Points = append(video.Points, VideoStatPoint{VideoStatSnapshot: snapshotFromStorage(last)})
+			latest[history.BVID] = last.Time
+		}
+		videos = append(videos, video)
+	}
+
+	sort.Slice(videos, func(i, j int) bool {
+		return latest[videos[i].BVID].After(latest[videos[j].BVID])
+	})
+
+	return videos, nil
+}
+
+// Watch 为视频开启（或修改）定时记录，并立即记录一次快照
+func (vs *VideoStatService) Watch(ctx context.Context, videoID string, intervalMinutes int) (*VideoStatWatch, error) {
+	if intervalMinutes < minStatWatchInterval {
+		return nil, fmt.Errorf("%w: interval_minutes must be at least %d", ErrInvalidStatWatch, minStatWatchInterval)
+	}
+
+	// 立即记录一次快照，同时确认视频存在
+	snapshot, err := vs.fetchAndRecord(ctx, videoID, StatSourcePoll)
+	if err != nil {
+		return nil, err
+	}
+	bvid, err := statBVID(videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	history, err := vs.loadHistory(bvid)
+	if err != nil {
+		return nil, err
+	}
+	history.WatchIntervalMinutes = intervalMinutes
+	if err := vs.storage.SaveVideoStats(history); err != nil {
+		return nil, fmt.Errorf("failed to save video stats: %w", err)
+	}
+
+	watch := &VideoStatWatch{
+		BVID:            bvid,
+		Title:           history.Title,
+		IntervalMinutes: intervalMinutes,
+		LastAt:          snapshot.Time,
+		NextAt:          snapshot.Time.Add(time.Duration(intervalMinutes) * time.Minute),
+	}
+	vs.watches[bvid] = watch
+
+	copied := *watch
+	return &copied, nil
+}
+
+// Unwatch 关闭视频的定时记录（保留已有快照）
+func (vs *VideoStatService) Unwatch(videoID string) error {
+	bvid, err := statBVID(videoID)
+	if err != nil {
+		return err
+	}
+
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	if _, ok := vs.watches[bvid]; !ok {
+		return fmt.Errorf("%w: %s is not watched", ErrVideoStatsNotFound, bvid)
+	}
+	delete(vs.watches, bvid)
+
+	history, err := vs.loadHistory(bvid)
+	if err != nil {
+		return err
+	}
+	history.WatchIntervalMinutes = 0
+	return vs.storage.SaveVideoStats(history)
+}
+
+// ListWatches 获取所有定时记录（按下次记录时间排序）
+func (vs *VideoStatService) ListWatches() []VideoStatWatch {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	watches := make([]VideoStatWatch, 0, len(vs.watches))
+	for _, watch := range vs.watches {
+		watches = append(watches, *watch)
+	}
+	sort.Slice(watches, func(i, j int) bool {
+		return watches[i].NextAt.Before(watches[j].NextAt)
+	})
+	return watches
+}
+
+// fetchAndRecord 获取视频信息并记录快照
+func (vs *VideoStatService) fetchAndRecord(ctx context.Context, videoID, source string) (*VideoStatSnapshot, error) {
+	videoResp, err := getVideo(ctx, vs.client, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video info: %w", err)
+	}
+	return vs.Record(&videoResp.Data, source)
+}
+
+// watchWorker 定期记录到期的视频快照
+func (vs *VideoStatService) watchWorker() {
+	ticker := time.NewTicker(statCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-vs.ctx.Done():
+			utils.LogInfo("watchWorker stopped in VideoStatService")
+			return
+		case <-ticker.C:
+			vs.recordDueWatches()
+		}
+	}
+}
+
+// recordDueWatches 为所有到期的定时记录获取快照，失败时等待下一个间隔
+func (vs *VideoStatService) recordDueWatches() {
+	now := time.Now()
+
+	vs.mu.Lock()
+	var due []string
+	for bvid, watch := range vs.watches {
+		if !now.Before(watch.NextAt) {
+			due = append(due, bvid)
+			watch.NextAt = now.Add(time.Duration(watch.IntervalMinutes) * time.Minute)
+		}
+	}
+	vs.mu.Unlock()
+
+	for _, bvid := range due {
+		if vs.ctx.Err() != nil {
+			return
+		}
+		if _, err := vs.fetchAndRecord(vs.ctx, bvid, StatSourcePoll); err != nil {
+			utils.LogError(fmt.Sprintf("定时记录视频 %s 数据失败: %v", bvid, err))
+		}
+	}
+}
+
+// loadWatches 从存储加载已开启的定时记录，下次记录时间从上一个快照推算
+func (vs *VideoStatService) loadWatches() {
+	bvids, err := vs.storage.ListVideoStats()
+	if err != nil {
+		utils.LogError("加载视频定时记录失败: " + err.Error())
+		return
+	}
+
+	for _, bvid := range bvids {
+		history, err := vs.storage.LoadVideoStats(bvid)
+		if err != nil || history.WatchIntervalMinutes <= 0 {
+			continue
+		}
+
+		watch := &VideoStatWatch{
+			BVID:            history.BVID,
+			Title:           history.Title,
+			IntervalMinutes: history.WatchIntervalMinutes,
+			NextAt:          time.Now(),
+		}
+		if n := len(history.Snapshots); n > 0 {
+			watch.LastAt = history.Snapshots[n-1].Time
+			watch.NextAt = watch.LastAt.Add(time.Duration(watch.IntervalMinutes) * time.Minute)
+		}
+		vs.watches[history.BVID] = watch
+	}
+}
+
+// loadHistory 加载视频的快照历史，不存在时返回空历史（调用方需持有锁）
+func (vs *VideoStatService) loadHistory(bvid string) (*storage.VideoStatHistory, error) {
+	history, err := vs.storage.LoadVideoStats(bvid)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &storage.VideoStatHistory{BVID: bvid, Snapshots: []storage.VideoStatSnapshot{}}, nil
+		}
+		return nil, fmt.Errorf("failed to load video stats: %w", err)
+	}
+	return history, nil
+}
+
+// statBVID 将视频ID规范化为BV号（快照按BV号存储，AV号离线转换）
+func statBVID(videoID string) (string, error) {
+	if strings.HasPrefix(videoID, "BV") {
+		return videoID, nil
+	}
+	if strings.HasPrefix(strings.ToLower(videoID), "av") {
+		if aid, err := strconv.ParseInt(videoID[2:], 10, 64); err == nil && aid > 0 {
+			return bilibili.AVToBV(aid), nil
+		}
+	}
+	return "", fmt.Errorf("%w: invalid video ID %s", ErrInvalidStatWatch, videoID)
+}
+
+// snapshotFromStorage 从存储层格式转换
+func snapshotFromStorage(s storage.VideoStatSnapshot) VideoStatSnapshot {
+	return VideoStatSnapshot{
+		Time:     s.Time,
+		Source:   s.Source,
+		View:     s.View,
+		Danmaku:  s.Danmaku,
+		Reply:    s.Reply,
+		Favorite: s.Favorite,
+		Coin:     s.Coin,
+		Share:    s.Share,
+		Like:     s.Like,
+	}
+}
+
+// Shutdown 优雅关闭服务
+func (vs *VideoStatService) Shutdown(ctx context.Context) error {
+	utils.LogInfo("Shutting down VideoStatService...")
+
+	vs.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		vs.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		utils.LogInfo("VideoStatService shutdown complete")
+		return nil
+	case <-ctx.Done():
+		utils.LogError("VideoStatService shutdown timeout")
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"bilibili/pkg/bilibili"
+)
+
+func TestParseVideoInput(t *testing.T) {
+	vs := NewVideoService(nil)
+
+	tests := []struct {
+		name      string
+		input     string
+		wantID    string
+		wantType  string
+		wantError bool
+	}{
+		{"bv id", "BV17x411w7KC", "BV17x411w7KC", "bv", false},
+		{"lowercase bv prefix", "bv17x411w7KC", "BV17x411w7KC", "bv", false},
+		{"web url", "https://www.bilibili.com/video/BV17x411w7KC/?p=2", "BV17x411w7KC", "bv", false},
+		{"mobile url", "https://m.bilibili.com/video/BV17x411w7KC", "BV17x411w7KC", "bv", false},
+		{"share text", "【视频标题】 https://www.bilibili.com/video/BV17x411w7KC?share_source=copy_web", "BV17x411w7KC", "bv", false},
+		{"short link with bv path", "https://b23.tv/BV17x411w7KC", "BV17x411w7KC", "bv", false},
+		{"av id", "av170001", "BV17x411w7KC", "av", false},
+		{"uppercase av prefix", "AV170001", "BV17x411w7KC", "av", false},
+		{"av url", "https://www.bilibili.com/video/av170001/", "BV17x411w7KC", "av", false},
+		{"av in share text", "看这个视频av170001", "BV17x411w7KC", "av", false},
+		{"app link", "bilibili://video/170001", "BV17x411w7KC", "av", false},
+		{"aid query", "https://m.bilibili.com/video?aid=170001", "BV17x411w7KC", "av", false},
+		{"plain number", "170001", "BV17x411w7KC", "av", false},
+		{"av inside word", "https://example.com/nav170001", "", "", true},
+		{"av followed by letters", "av170001x", "", "", true},
+		{"bv inside word", "xBV17x411w7KC", "", "", true},
+		{"bv too long", "BV17x411w7KC0", "", "", true},
+		{"bv with invalid char", "BV17x411w7K0", "", "", true},
+		{"zero aid", "av0", "", "", true},
+		{"text", "hello", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			videoID, videoType, err := vs.ParseVideoInput(context.Background(), tt.input)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("ParseVideoInput(%q) error = %v, wantError %v", tt.input, err, tt.wantError)
+			}
+			if videoID != tt.wantID || videoType != tt.wantType {
+				t.Errorf("ParseVideoInput(%q) = %q, %q, want %q, %q", tt.input, videoID, videoType, tt.wantID, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestParseCommentTarget(t *testing.T) {
+	vs := NewVideoService(nil)
+
+	tests := []struct {
+		name        string
+		input       string
+		commentType int
+		wantID      string
+		wantType    int
+		wantInvalid bool
+	}{
+		{"video detected", "BV17x411w7KC", 0, "BV17x411w7KC", bilibili.CommentTypeVideo, false},
+		{"video explicit", "av170001", bilibili.CommentTypeVideo, "BV17x411w7KC", bilibili.CommentTypeVideo, false},
+		{"dynamic url", "https://t.bilibili.com/123456789", 0, "123456789", bilibili.CommentTypeDynamic, false},
+		{"dynamic id", "123456789", bilibili.CommentTypeDynamic, "123456789", bilibili.CommentTypeDynamic, false},
+		{"draw dynamic id", "98765", bilibili.CommentTypeDynamicDraw, "98765", bilibili.CommentTypeDynamicDraw, false},
+		{"article cv", "cv12345", 0, "cv12345", bilibili.CommentTypeArticle, false},
+		{"article id", "12345", bilibili.CommentTypeArticle, "cv12345", bilibili.CommentTypeArticle, false},
+		{"audio url", "https://www.bilibili.com/audio/au6789", 0, "au6789", bilibili.CommentTypeAudio, false},
+		{"unsupported type", "12345", 2, "", 0, true},
+		{"unknown type", "12345", 33, "", 0, true},
+		{"negative type", "12345", -1, "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targetID, commentType, err := vs.ParseCommentTarget(context.Background(), tt.input, tt.commentType)
+			if tt.wantInvalid {
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Fatalf("ParseCommentTarget(%q, %d) error = %v, want ErrInvalidInput", tt.input, tt.commentType, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCommentTarget(%q, %d) error = %v", tt.input, tt.commentType, err)
+			}
+			if targetID != tt.wantID || commentType != tt.wantType {
+				t.Errorf("ParseCommentTarget(%q, %d) = %q, %d, want %q, %d", tt.input, tt.commentType, targetID, commentType, tt.wantID, tt.wantType)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package bilibili
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// ArticleResponse 专栏文章信息响应
+type ArticleResponse struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    ArticleInfo `json:"data"`
+}
+
+// ArticleInfo 专栏文章信息
+type ArticleInfo struct {
+	Title      string `json:"title"`
+	Mid        int64  `json:"mid"`
+	AuthorName string `json:"author_name"`
+	Stats      struct {
+		View  int `json:"view"`
+		Like  int `json:"like"`
+		Reply int `json:"reply"`
+	} `json:"stats"`
+}
+
+// GetArticleInfo 获取专栏文章信息
+func GetArticleInfo(cvid int64) (*ArticleResponse, error) {
+	return defaultClient.GetArticleInfo(context.Background(), cvid)
+}
+
+// GetArticleInfo 获取专栏文章信息（cvid 为不带 cv 前缀的文章ID）
+func (c *BilibiliClient) GetArticleInfo(ctx context.Context, cvid int64) (*ArticleResponse, error) {
+	params := url.Values{}
+	params.Add("id", fmt.Sprintf("%d", cvid))
+
+	body, err := c.get(ctx, "/x/article/viewinfo", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var articleResp ArticleResponse
+	if err := json.Unmarshal(body, &articleResp); err != nil {
+		return nil, fmt.Errorf("解析JSON失败: %v", err)
+	}
+
+	if articleResp.Code != 0 {
+		return nil, fmt.Errorf("API返回错误，错误码: %d, 错误信息: %s", articleResp.Code, articleResp.Message)
+	}
+
+	return &articleResp, nil
+}
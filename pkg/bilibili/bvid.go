@@ -0,0 +1,49 @@
+package bilibili
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AV号与BV号互转参数（2024年起的新算法，兼容超过 2^30 的 aid）
+const (
+	bvXorCode  = 23442827791579
+	bvMaskCode = 1<<51 - 1
+	bvMaxAID   = 1 << 51
+	bvBase     = 58
+	bvTable    = "FcwAPNKTMug3GV5Lj7EJnHpWsx4tb8haYeviqBz6rkCy12mUSDQX9RdoZf"
+)
+
+// AVToBV 将 aid 转换为BV号（离线计算，无需请求接口）
+func AVToBV(aid int64) string {
+	bvid := []byte("BV1000000000")
+	tmp := (bvMaxAID | aid) ^ bvXorCode
+	for i := len(bvid) - 1; tmp > 0 && i >= 3; i-- {
+		bvid[i] = bvTable[tmp%bvBase]
+		tmp /= bvBase
+	}
+	bvid[3], bvid[9] = bvid[9], bvid[3]
+	bvid[4], bvid[7] = bvid[7], bvid[4]
+	return string(bvid)
+}
+
+// BVToAV 将BV号转换为 aid（离线计算，无需请求接口）
+func BVToAV(bvid string) (int64, error) {
+	if len(bvid) != 12 || !strings.EqualFold(bvid[:2], "BV") || bvid[2] != '1' {
+		return 0, fmt.Errorf("invalid BV ID: %s", bvid)
+	}
+
+	chars := []byte(bvid)
+	chars[3], chars[9] = chars[9], chars[3]
+	chars[4], chars[7] = chars[7], chars[4]
+
+	var tmp int64
+	for _, ch := range chars[3:] {
+		idx := strings.IndexByte(bvTable, ch)
+		if idx < 0 {
+			return 0, fmt.Errorf("invalid BV ID: %s", bvid)
+		}
+		tmp = tmp*bvBase + int64(idx)
+	}
+	return (tmp & bvMaskCode) ^ bvXorCode, nil
+}
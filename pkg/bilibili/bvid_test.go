@@ -0,0 +1,64 @@
+package bilibili
+
+import "testing"
+
+func TestAVBVConversion(t *testing.T) {
+	tests := []struct {
+		aid  int64
+		bvid string
+	}{
+		{2, "BV1xx411c7mD"},
+		{170001, "BV17x411w7KC"},
+		{1054803170, "BV1mH4y1u7UA"},
+		{111298867365120, "BV1L9Uoa9EUx"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.bvid, func(t *testing.T) {
+			if got := AVToBV(tt.aid); got != tt.bvid {
+				t.Errorf("AVToBV(%d) = %s, want %s", tt.aid, got, tt.bvid)
+			}
+			got, err := BVToAV(tt.bvid)
+			if err != nil {
+				t.Fatalf("BVToAV(%s) error = %v", tt.bvid, err)
+			}
+			if got != tt.aid {
+				t.Errorf("BVToAV(%s) = %d, want %d", tt.bvid, got, tt.aid)
+			}
+		})
+	}
+}
+
+func TestAVBVRoundTrip(t *testing.T) {
+	for _, aid := range []int64{1, 99, 1 << 30, 1<<30 + 1, 1 << 40, bvMaxAID - 1} {
+		bvid := AVToBV(aid)
+		got, err := BVToAV(bvid)
+		if err != nil || got != aid {
+			t.Errorf("BVToAV(AVToBV(%d)) = %d, %v (bvid %s)", aid, got, err, bvid)
+		}
+	}
+}
+
+func TestBVToAVLowercasePrefix(t *testing.T) {
+	got, err := BVToAV("bv17x411w7KC")
+	if err != nil || got != 170001 {
+		t.Errorf("BVToAV(bv17x411w7KC) = %d, %v, want 170001", got, err)
+	}
+}
+
+func TestBVToAVInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"BV17x411w7K",   // 长度不足
+		"AV17x411w7KC",  // 前缀错误
+		"BV27x411w7KC",  // 第三位必须为1
+		"BV17x411w7K0",  // 0 不在编码表中
+		"BV17x411w7KC1", // 长度超出
+	}
+
+	for _, bvid := range tests {
+		if _, err := BVToAV(bvid); err == nil {
+			t.Errorf("BVToAV(%q) error = nil, want error", bvid)
+		}
+	}
+}
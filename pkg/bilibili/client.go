@@ -1,23 +1,126 @@
 package bilibili
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 )
 
+// DefaultBaseURL Bilibili API 默认地址
+const DefaultBaseURL = "https://api.bilibili.com"
+
+// DefaultLiveBaseURL 直播 API 默认地址
+const DefaultLiveBaseURL = "https://api.live.bilibili.com"
+
+// DefaultPassportBaseURL 登录 API 默认地址
+const DefaultPassportBaseURL = "https://passport.bilibili.com"
+
+// 默认限流参数：每秒请求数和突发请求数
+const (
+	DefaultRequestRate  = 4.0
+	DefaultRequestBurst = 4
+)
+
 // BilibiliClient Bilibili API客户端
+// 客户端可在多个任务间共享复用，所有请求方法都接收 context 以支持取消
 type BilibiliClient struct {
-	client  *http.Client
-	cookies map[string]string
-	appkey  string
-	appsec  string
+	client      *http.Client
+	baseURL     string
+	liveBaseURL string
+	passportURL string
+	cookies     map[string]string
+	appkey      string
+	appsec      string
+	wbiTTL      time.Duration
+	wbiKeys     *WBIKeyManager
+	limiter     *RateLimiter
+	retry       RetryPolicy
+}
+
+// ClientOption 客户端配置选项
+type ClientOption func(*BilibiliClient)
+
+// WithBaseURL 设置API基础地址（测试时可指向 httptest 服务器）
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *BilibiliClient) {
+		if baseURL != "" {
+			c.baseURL = strings.TrimRight(baseURL, "/")
+		}
+	}
+}
+
+// WithLiveBaseURL 设置直播API基础地址（测试时可指向 httptest 服务器）
+func WithLiveBaseURL(liveBaseURL string) ClientOption {
+	return func(c *BilibiliClient) {
+		if liveBaseURL != "" {
+			c.liveBaseURL = strings.TrimRight(liveBaseURL, "/")
+		}
+	}
+}
+
+// WithPassportBaseURL 设置登录API基础地址（测试时可指向 httptest 服务器）
+func WithPassportBaseURL(passportBaseURL string) ClientOption {
+	return func(c *BilibiliClient) {
+		if passportBaseURL != "" {
+			c.passportURL = strings.TrimRight(passportBaseURL, "/")
+		}
+	}
+}
+
+// WithTransport 设置底层 HTTP Transport
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c *BilibiliClient) {
+		if transport != nil {
+			c.client.Transport = transport
+		}
+	}
+}
+
+// WithHTTPClient 直接使用外部提供的 HTTP 客户端
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *BilibiliClient) {
+		if httpClient != nil {
+			c.client = httpClient
+		}
+	}
+}
+
+// WithTimeout 设置单次请求超时时间
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *BilibiliClient) {
+		if timeout > 0 {
+			c.client.Timeout = timeout
+		}
+	}
+}
+
+// WithWBIKeyTTL 设置WBI密钥缓存有效期
+func WithWBIKeyTTL(ttl time.Duration) ClientOption {
+	return func(c *BilibiliClient) {
+		c.wbiTTL = ttl
+	}
+}
+
+// WithRateLimiter 设置请求限流器（传入同一个限流器可在多个客户端间共享）
+func WithRateLimiter(limiter *RateLimiter) ClientOption {
+	return func(c *BilibiliClient) {
+		c.limiter = limiter
+	}
+}
+
+// WithRetryPolicy 设置请求重试策略
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *BilibiliClient) {
+		c.retry = policy
+	}
 }
 
 // NewBilibiliClient 创建新的Bilibili客户端
-func NewBilibiliClient() *BilibiliClient {
+func NewBilibiliClient(options ...ClientOption) *BilibiliClient {
 	// 创建自定义 Transport，禁用代理
 	transport := &http.Transport{
 		Proxy: func(*http.Request) (*url.URL, error) {
@@ -25,12 +128,38 @@ func NewBilibiliClient() *BilibiliClient {
 		},
 	}
 
-	return &BilibiliClient{
+	c := &BilibiliClient{
 		client: &http.Client{
 			Timeout:   10 * time.Second,
 			Transport: transport,
 		},
+		baseURL:     DefaultBaseURL,
+		liveBaseURL: DefaultLiveBaseURL,
+		passportURL: DefaultPassportBaseURL,
+		limiter:     NewRateLimiter(DefaultRequestRate, DefaultRequestBurst),
+		retry:       DefaultRetryPolicy,
 	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	c.wbiKeys = NewWBIKeyManager(c.wbiTTL, c.FetchWBIKey)
+
+	return c
+}
+
+// defaultClient 包级函数使用的共享客户端
+var defaultClient = NewBilibiliClient()
+
+// DefaultClient 返回包级函数使用的共享客户端
+func DefaultClient() *BilibiliClient {
+	return defaultClient
+}
+
+// BaseURL 返回客户端使用的API基础地址
+func (c *BilibiliClient) BaseURL() string {
+	return c.baseURL
 }
 
 // SetCookies 设置Cookie
@@ -46,10 +175,91 @@ func (c *BilibiliClient) SetAppAuth(appkey, appsec string) {
 
 // SendRequest 发送HTTP GET请求
 func (c *BilibiliClient) SendRequest(url string) ([]byte, error) {
+	return c.SendRequestContext(context.Background(), url)
+}
+
+// SendRequestContext 发送HTTP GET请求（支持 context 取消）
+func (c *BilibiliClient) SendRequestContext(ctx context.Context, url string) ([]byte, error) {
+	return c.doGet(ctx, url, nil)
+}
+
+// get 请求 baseURL + path 的接口
+func (c *BilibiliClient) get(ctx context.Context, path string, params url.Values, opts *CommentOptions) ([]byte, error) {
+	fullURL := c.baseURL + path
+	if len(params) > 0 {
+		fullURL += "?" + params.Encode()
+	}
+	return c.doGet(ctx, fullURL, opts)
+}
+
+// doGet 发送GET请求，opts 中的认证信息仅作用于本次请求
+// 每次尝试前都会经过限流器；遇到 412/429/5xx 或风控错误码时按重试策略退避重试
+func (c *BilibiliClient) doGet(ctx context.Context, url string, opts *CommentOptions) ([]byte, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	stats := statsFromContext(ctx)
+
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		// 等待限流令牌
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		stats.addRequest()
+		body, resp, err := c.doOnce(ctx, url, opts)
+
+		switch {
+		case err != nil:
+			// context 取消不重试
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			lastErr = err
+		case isRetryableStatus(resp.StatusCode):
+			lastErr = fmt.Errorf("HTTP状态码异常: %d", resp.StatusCode)
+		case resp.StatusCode != http.StatusOK:
+			return nil, fmt.Errorf("HTTP状态码异常: %d", resp.StatusCode)
+		case isRiskControlResponse(body):
+			lastErr = fmt.Errorf("触发风控: %s", string(body))
+		default:
+			return body, nil
+		}
+
+		// 以下均为可重试的错误
+		if attempt >= c.retry.MaxRetries {
+			stats.addFailure()
+			// 风控错误码重试耗尽时仍返回响应体，由调用方解析错误信息
+			if err == nil && resp.StatusCode == http.StatusOK {
+				return body, nil
+			}
+			return nil, lastErr
+		}
+
+		// 退避等待（优先使用 Retry-After）
+		delay := retryAfter(resp)
+		if delay == 0 {
+			delay = c.retry.backoff(attempt)
+		}
+		stats.addRetry()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// doOnce 发送一次GET请求
+func (c *BilibiliClient) doOnce(ctx context.Context, url string, opts *CommentOptions) ([]byte, *http.Response, error) {
 	// 创建请求
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("创建请求失败: %v", err)
+		return nil, nil, fmt.Errorf("创建请求失败: %v", err)
 	}
 
 	// 设置通用请求头
@@ -58,10 +268,25 @@ func (c *BilibiliClient) SendRequest(url string) ([]byte, error) {
 	req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
 	req.Header.Set("Referer", "https://www.bilibili.com/")
 
+	// 合并客户端级别和请求级别的Cookie
+	cookies := make(map[string]string, len(c.cookies))
+	for key, value := range c.cookies {
+		cookies[key] = value
+	}
+	appkey, appsec := c.appkey, c.appsec
+	if opts != nil {
+		for key, value := range opts.cookies {
+			cookies[key] = value
+		}
+		if opts.appkey != "" && opts.appsec != "" {
+			appkey, appsec = opts.appkey, opts.appsec
+		}
+	}
+
 	// 如果设置了Cookie，则添加到请求中
-	if c.cookies != nil {
+	if len(cookies) > 0 {
 		var cookieStr string
-		for key, value := range c.cookies {
+		for key, value := range cookies {
 			if cookieStr != "" {
 				cookieStr += "; "
 			}
@@ -71,22 +296,22 @@ func (c *BilibiliClient) SendRequest(url string) ([]byte, error) {
 	}
 
 	// 如果设置了APP认证信息，则添加相应头部
-	if c.appkey != "" && c.appsec != "" {
-		req.Header.Set("APP-KEY", c.appkey)
+	if appkey != "" && appsec != "" {
+		req.Header.Set("APP-KEY", appkey)
 	}
 
 	// 发送请求
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("发送请求失败: %v", err)
+		return nil, nil, fmt.Errorf("发送请求失败: %v", err)
 	}
 	defer resp.Body.Close()
 
 	// 读取响应
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("读取响应失败: %v", err)
+		return nil, resp, fmt.Errorf("读取响应失败: %v", err)
 	}
 
-	return body, nil
+	return body, resp, nil
 }
@@ -0,0 +1,155 @@
+package bilibili
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fastRetry 测试用的重试策略，避免真实退避拖慢测试
+var fastRetry = RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+// statusSequenceServer 依次返回 statuses 中的状态码，之后一律返回 200 和 body
+func statusSequenceServer(t *testing.T, statuses []int, header http.Header, body string) (*httptest.Server, func() []time.Time) {
+	t.Helper()
+	var mu sync.Mutex
+	var hits []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		n := len(hits)
+		hits = append(hits, time.Now())
+		mu.Unlock()
+
+		if n < len(statuses) {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(statuses[n])
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]time.Time(nil), hits...)
+	}
+}
+
+func TestDoGetRetriesRetryableStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+	}{
+		{"412 precondition failed", []int{http.StatusPreconditionFailed}},
+		{"429 too many requests", []int{http.StatusTooManyRequests}},
+		{"500 internal server error", []int{http.StatusInternalServerError}},
+		{"mixed 5xx", []int{http.StatusBadGateway, http.StatusServiceUnavailable}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, hits := statusSequenceServer(t, tt.statuses, nil, `{"code":0}`)
+			client := NewBilibiliClient(WithRateLimiter(nil), WithRetryPolicy(fastRetry))
+
+			stats := &RequestStats{}
+			body, err := client.doGet(ContextWithStats(context.Background(), stats), srv.URL, nil)
+			if err != nil {
+				t.Fatalf("doGet() error = %v", err)
+			}
+			if string(body) != `{"code":0}` {
+				t.Errorf("doGet() body = %q", body)
+			}
+			if got, want := len(hits()), len(tt.statuses)+1; got != want {
+				t.Errorf("server hits = %d, want %d", got, want)
+			}
+			if got := stats.Retries(); got != int64(len(tt.statuses)) {
+				t.Errorf("stats.Retries() = %d, want %d", got, len(tt.statuses))
+			}
+		})
+	}
+}
+
+func TestDoGetDoesNotRetryClientError(t *testing.T) {
+	srv, hits := statusSequenceServer(t, []int{http.StatusNotFound}, nil, `{"code":0}`)
+	client := NewBilibiliClient(WithRateLimiter(nil), WithRetryPolicy(fastRetry))
+
+	if _, err := client.doGet(context.Background(), srv.URL, nil); err == nil {
+		t.Fatal("doGet() error = nil, want HTTP status error")
+	}
+	if got := len(hits()); got != 1 {
+		t.Errorf("server hits = %d, want 1", got)
+	}
+}
+
+func TestDoGetHonorsRetryAfter(t *testing.T) {
+	header := http.Header{"Retry-After": []string{"1"}}
+	srv, hits := statusSequenceServer(t, []int{http.StatusTooManyRequests}, header, `{"code":0}`)
+	client := NewBilibiliClient(WithRateLimiter(nil), WithRetryPolicy(fastRetry))
+
+	if _, err := client.doGet(context.Background(), srv.URL, nil); err != nil {
+		t.Fatalf("doGet() error = %v", err)
+	}
+	got := hits()
+	if len(got) != 2 {
+		t.Fatalf("server hits = %d, want 2", len(got))
+	}
+	// 退避策略只有毫秒级，间隔达到1秒说明使用了 Retry-After
+	if gap := got[1].Sub(got[0]); gap < time.Second {
+		t.Errorf("retry gap = %v, want at least Retry-After (1s)", gap)
+	}
+}
+
+func TestDoGetStopsAtMaxRetries(t *testing.T) {
+	statuses := []int{500, 500, 500, 500, 500, 500}
+	srv, hits := statusSequenceServer(t, statuses, nil, `{"code":0}`)
+	client := NewBilibiliClient(WithRateLimiter(nil), WithRetryPolicy(fastRetry))
+
+	stats := &RequestStats{}
+	if _, err := client.doGet(ContextWithStats(context.Background(), stats), srv.URL, nil); err == nil {
+		t.Fatal("doGet() error = nil, want error after retries exhausted")
+	}
+	if got, want := len(hits()), fastRetry.MaxRetries+1; got != want {
+		t.Errorf("server hits = %d, want %d", got, want)
+	}
+	if stats.Requests() != int64(fastRetry.MaxRetries+1) || stats.Retries() != int64(fastRetry.MaxRetries) || stats.Failures() != 1 {
+		t.Errorf("stats = %d requests, %d retries, %d failures", stats.Requests(), stats.Retries(), stats.Failures())
+	}
+}
+
+func TestDoGetRiskControlExhaustedReturnsBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":-412,"message":"请求被拦截"}`))
+	}))
+	defer srv.Close()
+	client := NewBilibiliClient(WithRateLimiter(nil), WithRetryPolicy(RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond}))
+
+	body, err := client.doGet(context.Background(), srv.URL, nil)
+	if err != nil {
+		t.Fatalf("doGet() error = %v", err)
+	}
+	if !isRiskControlResponse(body) {
+		t.Errorf("doGet() body = %q, want risk control response", body)
+	}
+}
+
+func TestDoGetCancelDuringBackoff(t *testing.T) {
+	header := http.Header{"Retry-After": []string{"30"}}
+	srv, _ := statusSequenceServer(t, []int{http.StatusServiceUnavailable}, header, `{"code":0}`)
+	client := NewBilibiliClient(WithRateLimiter(nil), WithRetryPolicy(fastRetry))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := client.doGet(ctx, srv.URL, nil); err != context.DeadlineExceeded {
+		t.Fatalf("doGet() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("doGet() returned after %v, want it to stop when ctx expires", elapsed)
+	}
+}
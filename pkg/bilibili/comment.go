@@ -1,16 +1,46 @@
 package bilibili
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
 	"time"
 )
 
+// 评论区类型（评论接口的 type 参数）
+const (
+	CommentTypeVideo       = 1  // 视频，oid 为 aid
+	CommentTypeDynamicDraw = 11 // 图片动态，oid 为相簿ID
+	CommentTypeArticle     = 12 // 专栏，oid 为 cv 号
+	CommentTypeAudio       = 14 // 音频，oid 为 au 号
+	CommentTypeDynamic     = 17 // 文字/转发动态，oid 为动态ID
+)
+
+// CommentTypeName 评论区类型名称
+func CommentTypeName(commentType int) string {
+	switch commentType {
+	case CommentTypeVideo:
+		return "视频"
+	case CommentTypeDynamicDraw, CommentTypeDynamic:
+		return "动态"
+	case CommentTypeArticle:
+		return "专栏"
+	case CommentTypeAudio:
+		return "音频"
+	default:
+		return fmt.Sprintf("类型%d", commentType)
+	}
+}
+
 // CommentOptions 评论请求配置选项
+// 认证信息只作用于单次调用，不会修改共享的客户端
 type CommentOptions struct {
-	client   *BilibiliClient
-	sortMode string // "time" 按时间, "hot" 按热度
+	cookies     map[string]string
+	appkey      string
+	appsec      string
+	sortMode    string // "time" 按时间, "hot" 按热度
+	commentType int    // 评论区类型，默认视频
 }
 
 // CommentOption 评论选项类型
@@ -19,22 +49,30 @@ type CommentOption func(*CommentOptions)
 // WithCookie Cookie认证选项
 func WithCookie(sessdata string) CommentOption {
 	return func(opts *CommentOptions) {
-		if opts.client == nil {
-			opts.client = NewBilibiliClient()
+		if opts.cookies == nil {
+			opts.cookies = make(map[string]string)
+		}
+		opts.cookies["SESSDATA"] = sessdata
+	}
+}
+
+// WithCookies 完整Cookie认证选项（如登录账号的 SESSDATA、bili_jct、buvid3、DedeUserID）
+func WithCookies(cookies map[string]string) CommentOption {
+	return func(opts *CommentOptions) {
+		if opts.cookies == nil {
+			opts.cookies = make(map[string]string, len(cookies))
+		}
+		for key, value := range cookies {
+			opts.cookies[key] = value
 		}
-		opts.client.SetCookies(map[string]string{
-			"SESSDATA": sessdata,
-		})
 	}
 }
 
 // WithAppAuth APP认证选项
 func WithAppAuth(appkey, appsec string) CommentOption {
 	return func(opts *CommentOptions) {
-		if opts.client == nil {
-			opts.client = NewBilibiliClient()
-		}
-		opts.client.SetAppAuth(appkey, appsec)
+		opts.appkey = appkey
+		opts.appsec = appsec
 	}
 }
 
@@ -46,33 +84,83 @@ func WithSortMode(sortMode string) CommentOption {
 	}
 }
 
+// WithCommentType 评论区类型选项（CommentType* 常量）
+func WithCommentType(commentType int) CommentOption {
+	return func(opts *CommentOptions) {
+		opts.commentType = commentType
+	}
+}
+
 // AuthOption 认证选项类型 (保持向后兼容)
 type AuthOption = CommentOption
 
+// newCommentOptions 应用评论选项（默认按时间排序）
+func newCommentOptions(commentOptions []CommentOption) *CommentOptions {
+	opts := &CommentOptions{
+		sortMode:    "time", // 默认按时间排序
+		commentType: CommentTypeVideo,
+	}
+	for _, option := range commentOptions {
+		option(opts)
+	}
+	return opts
+}
+
 // GetComments 获取视频评论 (使用wbi/main端点)
 // next: 用于翻页的游标值（从上一页响应的 Cursor.Next 获取）
 // nextOffset: 可选的 next_offset 字符串（从上一页响应的 Cursor.PaginationReply.NextOffset 获取）
 func GetComments(oid int64, pn int, ps int, next int, commentOptions ...CommentOption) (*CommentResponse, error) {
-	return GetCommentsWithOffset(oid, pn, ps, next, "", commentOptions...)
+	return defaultClient.GetComments(context.Background(), oid, pn, ps, next, commentOptions...)
 }
 
 // GetCommentsWithOffset 获取视频评论（支持 next_offset 字符串）
 func GetCommentsWithOffset(oid int64, pn int, ps int, next int, nextOffset string, commentOptions ...CommentOption) (*CommentResponse, error) {
-	// 处理选项
-	opts := &CommentOptions{
-		sortMode: "time", // 默认按时间排序
-	}
-	for _, option := range commentOptions {
-		option(opts)
-	}
+	return defaultClient.GetCommentsWithOffset(context.Background(), oid, pn, ps, next, nextOffset, commentOptions...)
+}
 
-	// 如果没有提供客户端，创建一个新的
-	if opts.client == nil {
-		opts.client = NewBilibiliClient()
-	}
+// GetCommentsFallback 备用方法，使用原始reply接口
+func GetCommentsFallback(oid int64, pn int, ps int, commentOptions ...CommentOption) (*CommentResponse, error) {
+	return defaultClient.GetCommentsFallback(context.Background(), oid, pn, ps, commentOptions...)
+}
+
+// GetHotComments 获取视频的热门评论 (使用main端点)
+// 已废弃: 推荐使用 GetComments 配合 WithSortMode("hot") 选项
+func GetHotComments(oid int64, pn int, ps int, commentOptions ...CommentOption) (*CommentResponse, error) {
+	// 添加热门排序选项
+	opts := append(commentOptions, WithSortMode("hot"))
+	return GetComments(oid, pn, ps, 0, opts...)
+}
 
-	// 构造API URL (使用wbi/main端点)
-	apiURL := "https://api.bilibili.com/x/v2/reply/main"
+// GetAllComments 获取视频的所有评论
+// 支持通过 WithSortMode("hot") 或 WithSortMode("time") 设置排序模式，默认按时间排序
+func GetAllComments(oid int64, commentOptions ...CommentOption) ([]CommentData, error) {
+	return defaultClient.GetAllComments(context.Background(), oid, commentOptions...)
+}
+
+// GetSubComments 获取评论的子评论（最多3条）
+// oid: 视频aid
+// root: 根评论的rpid
+// commentOptions: 可选的认证选项
+func GetSubComments(oid int64, root int64, commentOptions ...CommentOption) ([]CommentData, error) {
+	return defaultClient.GetSubComments(context.Background(), oid, root, commentOptions...)
+}
+
+// GetAllSubComments 分页获取评论的全部子评论
+// maxPages <= 0 表示不限制页数，delay 为两页之间的等待时间
+func GetAllSubComments(oid int64, root int64, maxPages int, delay time.Duration, commentOptions ...CommentOption) ([]CommentData, error) {
+	return defaultClient.GetAllSubComments(context.Background(), oid, root, maxPages, delay, commentOptions...)
+}
+
+// GetComments 获取视频评论 (使用wbi/main端点)
+func (c *BilibiliClient) GetComments(ctx context.Context, oid int64, pn int, ps int, next int, commentOptions ...CommentOption) (*CommentResponse, error) {
+	return c.GetCommentsWithOffset(ctx, oid, pn, ps, next, "", commentOptions...)
+}
+
+// GetCommentsWithOffset 获取视频评论（支持 next_offset 字符串）
+// 其他评论区（动态、专栏、音频等）通过 WithCommentType 选项指定类型，oid 为对应的评论区ID
+func (c *BilibiliClient) GetCommentsWithOffset(ctx context.Context, oid int64, pn int, ps int, next int, nextOffset string, commentOptions ...CommentOption) (*CommentResponse, error) {
+	// 处理选项
+	opts := newCommentOptions(commentOptions)
 
 	// 构造查询参数
 	params := url.Values{}
@@ -105,7 +193,7 @@ func GetCommentsWithOffset(oid int64, pn int, ps int, next int, nextOffset strin
 		params.Add("pagination_str", paginationStr)
 	}
 
-	params.Add("type", "1") // 视频评论类型
+	params.Add("type", fmt.Sprintf("%d", opts.commentType)) // 评论区类型
 
 	// 根据排序模式设置 mode 参数
 	// mode=2: 按时间排序, mode=3: 按热度排序
@@ -115,14 +203,8 @@ func GetCommentsWithOffset(oid int64, pn int, ps int, next int, nextOffset strin
 		params.Add("mode", "2") // 默认按时间排序
 	}
 
-	// 获取WBI密钥并签名参数
-	wbiKey := GetWBIKey()
-	signedParams := SignParams(params, wbiKey)
-
-	// 完整URL
-	fullURL := apiURL + "?" + signedParams.Encode()
-
-	body, err := opts.client.SendRequest(fullURL)
+	// 使用WBI签名请求（签名失效时自动刷新密钥重试）
+	body, err := c.signedGet(ctx, "/x/v2/reply/main", params, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -138,7 +220,7 @@ func GetCommentsWithOffset(oid int64, pn int, ps int, next int, nextOffset strin
 		// 如果是权限错误，尝试使用备用接口
 		if commentResp.Code == -403 {
 			fmt.Println("权限错误，尝试使用备用接口获取评论...")
-			return GetCommentsFallback(oid, pn, ps, commentOptions...)
+			return c.GetCommentsFallback(ctx, oid, pn, ps, commentOptions...)
 		}
 		return nil, fmt.Errorf("API返回错误，错误码: %d, 错误信息: %s", commentResp.Code, commentResp.Message)
 	}
@@ -147,29 +229,16 @@ func GetCommentsWithOffset(oid int64, pn int, ps int, next int, nextOffset strin
 }
 
 // GetCommentsFallback 备用方法，使用原始reply接口
-func GetCommentsFallback(oid int64, pn int, ps int, commentOptions ...CommentOption) (*CommentResponse, error) {
+func (c *BilibiliClient) GetCommentsFallback(ctx context.Context, oid int64, pn int, ps int, commentOptions ...CommentOption) (*CommentResponse, error) {
 	// 处理选项
-	opts := &CommentOptions{
-		sortMode: "time", // 默认按时间排序
-	}
-	for _, option := range commentOptions {
-		option(opts)
-	}
-
-	// 如果没有提供客户端，创建一个新的
-	if opts.client == nil {
-		opts.client = NewBilibiliClient()
-	}
-
-	// 构造API URL (使用原始reply接口)
-	apiURL := "https://api.bilibili.com/x/v2/reply"
+	opts := newCommentOptions(commentOptions)
 
 	// 构造查询参数
 	params := url.Values{}
 	params.Add("oid", fmt.Sprintf("%d", oid))
 	params.Add("pn", fmt.Sprintf("%d", pn)) // fallback接口使用pn参数
 	params.Add("ps", fmt.Sprintf("%d", ps))
-	params.Add("type", "1") // 视频评论类型
+	params.Add("type", fmt.Sprintf("%d", opts.commentType)) // 评论区类型
 
 	// 根据排序模式设置 sort 参数
 	// sort=2: 按时间倒序排序, sort=1: 按热度排序
@@ -179,14 +248,8 @@ func GetCommentsFallback(oid int64, pn int, ps int, commentOptions ...CommentOpt
 		params.Add("sort", "2") // 默认按时间倒序排序
 	}
 
-	// 获取WBI密钥并签名参数
-	wbiKey := GetWBIKey()
-	signedParams := SignParams(params, wbiKey)
-
-	// 完整URL
-	fullURL := apiURL + "?" + signedParams.Encode()
-
-	body, err := opts.client.SendRequest(fullURL)
+	// 使用WBI签名请求（签名失效时自动刷新密钥重试）
+	body, err := c.signedGet(ctx, "/x/v2/reply", params, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -205,23 +268,14 @@ func GetCommentsFallback(oid int64, pn int, ps int, commentOptions ...CommentOpt
 	return &commentResp, nil
 }
 
-// GetHotComments 获取视频的热门评论 (使用main端点)
-// 已废弃: 推荐使用 GetComments 配合 WithSortMode("hot") 选项
-func GetHotComments(oid int64, pn int, ps int, commentOptions ...CommentOption) (*CommentResponse, error) {
-	// 添加热门排序选项
-	opts := append(commentOptions, WithSortMode("hot"))
-	return GetComments(oid, pn, ps, 0, opts...)
-}
-
 // GetAllComments 获取视频的所有评论
-// 支持通过 WithSortMode("hot") 或 WithSortMode("time") 设置排序模式，默认按时间排序
-func GetAllComments(oid int64, commentOptions ...CommentOption) ([]CommentData, error) {
+func (c *BilibiliClient) GetAllComments(ctx context.Context, oid int64, commentOptions ...CommentOption) ([]CommentData, error) {
 	// 使用map来去重，以RPID为键
 	uniqueComments := make(map[int64]CommentData)
 	var allComments []CommentData
 
 	// 先使用main接口获取第一页评论
-	firstPage, err := GetComments(oid, 1, 20, 0, commentOptions...)
+	firstPage, err := c.GetComments(ctx, oid, 1, 20, 0, commentOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("获取第一页评论失败: %v", err)
 	}
@@ -241,25 +295,24 @@ func GetAllComments(oid int64, commentOptions ...CommentOption) ([]CommentData,
 
 	// 确定排序模式文字描述
 	sortModeText := "时间"
-	for _, opt := range commentOptions {
-		testOpts := &CommentOptions{}
-		opt(testOpts)
-		if testOpts.sortMode == "hot" {
-			sortModeText = "热门"
-			break
-		}
+	if newCommentOptions(commentOptions).sortMode == "hot" {
+		sortModeText = "热门"
 	}
 
 	fmt.Printf("总评论数: %d, 总页数: %d, 排序模式: %s\n", totalCount, totalPages, sortModeText)
 
 	// 获取剩余页的评论
 	for page := 2; page <= totalPages && page <= 100; page++ { // 限制最多获取100页以避免过多请求
-		// 添加延迟避免请求过于频繁
-		time.Sleep(300 * time.Millisecond)
+		// 添加延迟避免请求过于频繁，同时响应取消
+		select {
+		case <-ctx.Done():
+			return allComments, ctx.Err()
+		case <-time.After(300 * time.Millisecond):
+		}
 
 		fmt.Printf("正在获取第 %d 页评论...\n", page)
 
-		resp, err := GetComments(oid, page, pageSize, 0, commentOptions...)
+		resp, err := c.GetComments(ctx, oid, page, pageSize, 0, commentOptions...)
 		if err != nil {
 			// 如果某页获取失败，记录错误并继续获取下一页
 			fmt.Printf("获取第%d页评论失败: %v\n", page, err)
@@ -292,42 +345,39 @@ func GetAllComments(oid int64, commentOptions ...CommentOption) ([]CommentData,
 }
 
 // GetSubComments 获取评论的子评论（最多3条）
-// oid: 视频aid
-// root: 根评论的rpid
-// commentOptions: 可选的认证选项
-func GetSubComments(oid int64, root int64, commentOptions ...CommentOption) ([]CommentData, error) {
-	// 处理选项
-	opts := &CommentOptions{
-		sortMode: "time",
-	}
-	for _, option := range commentOptions {
-		option(opts)
+func (c *BilibiliClient) GetSubComments(ctx context.Context, oid int64, root int64, commentOptions ...CommentOption) ([]CommentData, error) {
+	resp, err := c.GetSubCommentsPage(ctx, oid, root, 1, 3, commentOptions...)
+	if err != nil {
+		return nil, err
 	}
 
-	// 如果没有提供客户端，创建一个新的
-	if opts.client == nil {
-		opts.client = NewBilibiliClient()
+	// 检查API是否返回错误
+	if resp.Code != 0 {
+		// 子评论获取失败不应该导致整个任务失败，返回空列表
+		return []CommentData{}, nil
 	}
 
-	// 构造API URL (获取子评论的端点)
-	apiURL := "https://api.bilibili.com/x/v2/reply/reply"
+	return resp.Data.Replies, nil
+}
+
+// GetSubCommentsPage 获取评论的一页子评论
+// pn: 页码（从1开始）
+// ps: 每页数量（接口上限为 SubCommentMaxPageSize）
+// 返回的 Data.Page.Count 为子评论总数，可用于判断是否还有下一页
+func (c *BilibiliClient) GetSubCommentsPage(ctx context.Context, oid int64, root int64, pn int, ps int, commentOptions ...CommentOption) (*CommentResponse, error) {
+	// 处理选项
+	opts := newCommentOptions(commentOptions)
 
 	// 构造查询参数
 	params := url.Values{}
 	params.Add("oid", fmt.Sprintf("%d", oid))
 	params.Add("root", fmt.Sprintf("%d", root))
-	params.Add("type", "1") // 视频评论类型
-	params.Add("pn", "1")   // 第一页
-	params.Add("ps", "3")   // 每页3条
-
-	// 获取WBI密钥并签名参数
-	wbiKey := GetWBIKey()
-	signedParams := SignParams(params, wbiKey)
-
-	// 完整URL
-	fullURL := apiURL + "?" + signedParams.Encode()
+	params.Add("type", fmt.Sprintf("%d", opts.commentType)) // 评论区类型
+	params.Add("pn", fmt.Sprintf("%d", pn))
+	params.Add("ps", fmt.Sprintf("%d", ps))
 
-	body, err := opts.client.SendRequest(fullURL)
+	// 使用WBI签名请求（签名失效时自动刷新密钥重试）
+	body, err := c.signedGet(ctx, "/x/v2/reply/reply", params, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -338,11 +388,49 @@ func GetSubComments(oid int64, root int64, commentOptions ...CommentOption) ([]C
 		return nil, fmt.Errorf("解析JSON失败: %v", err)
 	}
 
-	// 检查API是否返回错误
-	if commentResp.Code != 0 {
-		// 子评论获取失败不应该导致整个任务失败，返回空列表
-		return []CommentData{}, nil
+	return &commentResp, nil
+}
+
+// SubCommentMaxPageSize 子评论接口每页最大数量
+const SubCommentMaxPageSize = 20
+
+// GetAllSubComments 分页获取评论的全部子评论
+// maxPages: 最多抓取的页数，<=0 表示不限制
+// delay: 两页之间的等待时间
+// 中途失败或被取消时返回已获取的子评论和错误
+func (c *BilibiliClient) GetAllSubComments(ctx context.Context, oid int64, root int64, maxPages int, delay time.Duration, commentOptions ...CommentOption) ([]CommentData, error) {
+	var replies []CommentData
+	seen := make(map[int64]bool)
+
+	for pn := 1; maxPages <= 0 || pn <= maxPages; pn++ {
+		if pn > 1 && delay > 0 {
+			select {
+			case <-ctx.Done():
+				return replies, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		resp, err := c.GetSubCommentsPage(ctx, oid, root, pn, SubCommentMaxPageSize, commentOptions...)
+		if err != nil {
+			return replies, fmt.Errorf("获取第%d页子评论失败: %w", pn, err)
+		}
+		if resp.Code != 0 {
+			return replies, fmt.Errorf("API返回错误，错误码: %d, 错误信息: %s", resp.Code, resp.Message)
+		}
+
+		for _, reply := range resp.Data.Replies {
+			if !seen[reply.RPID] {
+				seen[reply.RPID] = true
+				replies = append(replies, reply)
+			}
+		}
+
+		// 本页不足一页或已取完全部子评论
+		if len(resp.Data.Replies) < SubCommentMaxPageSize || pn*SubCommentMaxPageSize >= resp.Data.Page.Count {
+			break
+		}
 	}
 
-	return commentResp.Data.Replies, nil
+	return replies, nil
 }
@@ -0,0 +1,225 @@
+package bilibili
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DanmakuSegmentDuration 分段弹幕接口每段覆盖的视频时长（6分钟）
+const DanmakuSegmentDuration = 6 * time.Minute
+
+// Danmaku 弹幕数据
+type Danmaku struct {
+	ID       int64  `json:"id"`
+	Progress int    `json:"progress"`  // 在视频中出现的时间（毫秒）
+	Mode     int    `json:"mode"`      // 1-3 滚动, 4 底部, 5 顶部, 6 逆向, 7 高级, 8 代码, 9 BAS
+	FontSize int    `json:"font_size"` // 字号
+	Color    int    `json:"color"`     // 十进制RGB颜色
+	MidHash  string `json:"mid_hash"`  // 发送者UID的CRC32哈希
+	Content  string `json:"content"`
+	Ctime    int64  `json:"ctime"`  // 发送时间（Unix秒）
+	Weight   int    `json:"weight"` // 屏蔽权重（0-10）
+	Pool     int    `json:"pool"`   // 弹幕池：0 普通, 1 字幕, 2 特殊
+
+	// Page 本地记录的所属分P序号，非API返回字段
+	Page int `json:"page,omitempty"`
+}
+
+// GetDanmakuXML 通过XML接口获取弹幕
+func GetDanmakuXML(cid int64) ([]Danmaku, error) {
+	return defaultClient.GetDanmakuXML(context.Background(), cid)
+}
+
+// GetDanmakuSegment 通过分段protobuf接口获取弹幕
+func GetDanmakuSegment(cid int64, segmentIndex int) ([]Danmaku, error) {
+	return defaultClient.GetDanmakuSegment(context.Background(), cid, segmentIndex)
+}
+
+// GetDanmakuXML 通过XML接口获取弹幕（只返回弹幕池中最新的一部分，数量受视频时长限制）
+func (c *BilibiliClient) GetDanmakuXML(ctx context.Context, cid int64) ([]Danmaku, error) {
+	params := url.Values{}
+	params.Add("oid", fmt.Sprintf("%d", cid))
+
+	body, err := c.get(ctx, "/x/v1/dm/list.so", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// 接口返回的XML可能经过 deflate 压缩
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] != '<' {
+		inflated, err := io.ReadAll(flate.NewReader(bytes.NewReader(body)))
+		if err != nil {
+			return nil, fmt.Errorf("解压弹幕数据失败: %v", err)
+		}
+		body = inflated
+	}
+
+	return parseDanmakuXML(body)
+}
+
+// GetDanmakuSegment 通过分段protobuf接口获取弹幕
+// segmentIndex 从1开始，每段覆盖6分钟视频
+func (c *BilibiliClient) GetDanmakuSegment(ctx context.Context, cid int64, segmentIndex int) ([]Danmaku, error) {
+	params := url.Values{}
+	params.Add("type", "1") // 视频弹幕
+	params.Add("oid", fmt.Sprintf("%d", cid))
+	params.Add("segment_index", strconv.Itoa(segmentIndex))
+
+	body, err := c.get(ctx, "/x/v2/dm/web/seg.so", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// 出错时接口返回JSON而不是protobuf
+	if len(body) > 0 && body[0] == '{' {
+		var errResp struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Code != 0 {
+			return nil, fmt.Errorf("API返回错误，错误码: %d, 错误信息: %s", errResp.Code, errResp.Message)
+		}
+	}
+
+	return decodeDanmakuSegment(body)
+}
+
+// GetAllDanmaku 按分段获取视频的全部弹幕
+// duration 为视频时长（秒），用于计算分段数量；结果按弹幕ID去重
+func (c *BilibiliClient) GetAllDanmaku(ctx context.Context, cid int64, duration int) ([]Danmaku, error) {
+	segments := DanmakuSegmentCount(duration)
+
+	var all []Danmaku
+	seen := make(map[int64]bool)
+	for i := 1; i <= segments; i++ {
+		elems, err := c.GetDanmakuSegment(ctx, cid, i)
+		if err != nil {
+			return all, fmt.Errorf("获取第%d段弹幕失败: %w", i, err)
+		}
+		for _, d := range elems {
+			if !seen[d.ID] {
+				seen[d.ID] = true
+				all = append(all, d)
+			}
+		}
+	}
+	return all, nil
+}
+
+// DanmakuSegmentCount 根据视频时长（秒）计算弹幕分段数量，至少为1
+func DanmakuSegmentCount(duration int) int {
+	segmentSeconds := int(DanmakuSegmentDuration / time.Second)
+	count := (duration + segmentSeconds - 1) / segmentSeconds
+	if count < 1 {
+		count = 1
+	}
+	return count
+}
+
+// danmakuXML XML弹幕文件结构
+type danmakuXML struct {
+	Items []struct {
+		P       string `xml:"p,attr"`
+		Content string `xml:",chardata"`
+	} `xml:"d"`
+}
+
+// parseDanmakuXML 解析XML弹幕
+// p 属性依次为：出现时间(秒), 模式, 字号, 颜色, 发送时间, 弹幕池, 发送者哈希, 弹幕ID[, 权重]
+func parseDanmakuXML(data []byte) ([]Danmaku, error) {
+	var doc danmakuXML
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析弹幕XML失败: %v", err)
+	}
+
+	danmaku := make([]Danmaku, 0, len(doc.Items))
+	for _, item := range doc.Items {
+		fields := strings.Split(item.P, ",")
+		if len(fields) < 8 {
+			continue
+		}
+		progress, _ := strconv.ParseFloat(fields[0], 64)
+		d := Danmaku{
+			Progress: int(progress * 1000),
+			Mode:     atoi(fields[1]),
+			FontSize: atoi(fields[2]),
+			Color:    atoi(fields[3]),
+			Ctime:    int64(atoi(fields[4])),
+			Pool:     atoi(fields[5]),
+			MidHash:  fields[6],
+			Content:  item.Content,
+		}
+		d.ID, _ = strconv.ParseInt(fields[7], 10, 64)
+		if len(fields) > 8 {
+			d.Weight = atoi(fields[8])
+		}
+		danmaku = append(danmaku, d)
+	}
+	return danmaku, nil
+}
+
+// atoi 解析整数，失败时返回0
+func atoi(s string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(s))
+	return n
+}
+
+// decodeDanmakuSegment 解码分段弹幕（DmSegMobileReply，弹幕列表为字段1）
+func decodeDanmakuSegment(data []byte) ([]Danmaku, error) {
+	var danmaku []Danmaku
+	err := walkProtoFields(data, func(field int, wireType int, varint uint64, raw []byte) error {
+		if field != 1 || wireType != protoWireBytes {
+			return nil
+		}
+		d, err := decodeDanmakuElem(raw)
+		if err != nil {
+			return err
+		}
+		danmaku = append(danmaku, d)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("解析弹幕数据失败: %v", err)
+	}
+	return danmaku, nil
+}
+
+// decodeDanmakuElem 解码单条弹幕（DanmakuElem）
+func decodeDanmakuElem(data []byte) (Danmaku, error) {
+	var d Danmaku
+	err := walkProtoFields(data, func(field int, wireType int, varint uint64, raw []byte) error {
+		switch field {
+		case 1:
+			d.ID = int64(varint)
+		case 2:
+			d.Progress = int(int32(varint))
+		case 3:
+			d.Mode = int(int32(varint))
+		case 4:
+			d.FontSize = int(int32(varint))
+		case 5:
+			d.Color = int(uint32(varint))
+		case 6:
+			d.MidHash = string(raw)
+		case 7:
+			d.Content = string(raw)
+		case 8:
+			d.Ctime = int64(varint)
+		case 9:
+			d.Weight = int(int32(varint))
+		case 11:
+			d.Pool = int(int32(varint))
+		}
+		return nil
+	})
+	return d, err
+}
@@ -0,0 +1,77 @@
+package bilibili
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// DynamicResponse 动态详情响应
+type DynamicResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Item DynamicItem `json:"item"`
+	} `json:"data"`
+}
+
+// DynamicItem 动态详情
+type DynamicItem struct {
+	IDStr string `json:"id_str"`
+	Type  string `json:"type"` // DYNAMIC_TYPE_DRAW, DYNAMIC_TYPE_WORD, DYNAMIC_TYPE_AV 等
+	Basic struct {
+		CommentIDStr string `json:"comment_id_str"` // 评论区ID（评论接口的 oid）
+		CommentType  int    `json:"comment_type"`   // 评论区类型（评论接口的 type）
+		RidStr       string `json:"rid_str"`
+	} `json:"basic"`
+	Modules struct {
+		ModuleAuthor struct {
+			Mid   int64  `json:"mid"`
+			Name  string `json:"name"`
+			PubTs int64  `json:"pub_ts"`
+		} `json:"module_author"`
+		ModuleDynamic struct {
+			Desc *struct {
+				Text string `json:"text"`
+			} `json:"desc"`
+		} `json:"module_dynamic"`
+	} `json:"modules"`
+}
+
+// Text 动态正文（无正文时为空）
+func (d *DynamicItem) Text() string {
+	if d.Modules.ModuleDynamic.Desc == nil {
+		return ""
+	}
+	return d.Modules.ModuleDynamic.Desc.Text
+}
+
+// GetDynamicDetail 获取动态详情
+func GetDynamicDetail(dynamicID string) (*DynamicResponse, error) {
+	return defaultClient.GetDynamicDetail(context.Background(), dynamicID)
+}
+
+// GetDynamicDetail 获取动态详情
+// 不同类型动态的评论区类型和ID不同（图片动态为相簿ID，视频动态为aid），需从 Basic 中读取
+func (c *BilibiliClient) GetDynamicDetail(ctx context.Context, dynamicID string) (*DynamicResponse, error) {
+	params := url.Values{}
+	params.Add("id", dynamicID)
+	params.Add("features", "itemOpusStyle")
+
+	body, err := c.get(ctx, "/x/polymer/web-dynamic/v1/detail", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dynamicResp DynamicResponse
+	if err := json.Unmarshal(body, &dynamicResp); err != nil {
+		return nil, fmt.Errorf("解析JSON失败: %v", err)
+	}
+
+	if dynamicResp.Code != 0 {
+		return nil, fmt.Errorf("API返回错误，错误码: %d, 错误信息: %s", dynamicResp.Code, dynamicResp.Message)
+	}
+
+	return &dynamicResp, nil
+}
@@ -0,0 +1,113 @@
+package bilibili
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// ErrLiveRoomNotFound 直播间不存在
+var ErrLiveRoomNotFound = errors.New("live room not found")
+
+// 直播状态
+const (
+	LiveStatusOffline = 0 // 未开播
+	LiveStatusLive    = 1 // 直播中
+	LiveStatusRound   = 2 // 轮播中
+)
+
+// LiveRoomInfo 直播间信息
+type LiveRoomInfo struct {
+	RoomID     int64  `json:"room_id"`  // 真实房间号
+	ShortID    int64  `json:"short_id"` // 短号，没有时为0
+	UID        int64  `json:"uid"`      // 主播UID
+	Title      string `json:"title"`
+	LiveStatus int    `json:"live_status"` // LiveStatus* 常量
+	Online     int    `json:"online"`      // 人气值
+	AreaName   string `json:"area_name"`
+	LiveTime   string `json:"live_time"` // 开播时间（如 2024-01-01 20:00:00），未开播时为 0000-00-00 00:00:00
+}
+
+// LiveRoomInfoResponse 直播间信息响应
+type LiveRoomInfoResponse struct {
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Data    LiveRoomInfo `json:"data"`
+}
+
+// LiveDanmuInfo 直播弹幕服务器信息
+type LiveDanmuInfo struct {
+	Token    string `json:"token"` // 连接弹幕服务器时的认证密钥
+	HostList []struct {
+		Host    string `json:"host"`
+		Port    int    `json:"port"`
+		WssPort int    `json:"wss_port"`
+		WsPort  int    `json:"ws_port"`
+	} `json:"host_list"`
+}
+
+// LiveDanmuInfoResponse 直播弹幕服务器信息响应
+type LiveDanmuInfoResponse struct {
+	Code    int           `json:"code"`
+	Message string        `json:"message"`
+	Data    LiveDanmuInfo `json:"data"`
+}
+
+// GetLiveRoomInfo 获取直播间信息
+func GetLiveRoomInfo(roomID int64) (*LiveRoomInfoResponse, error) {
+	return defaultClient.GetLiveRoomInfo(context.Background(), roomID)
+}
+
+// GetLiveRoomInfo 获取直播间信息，roomID 可以是短号
+func (c *BilibiliClient) GetLiveRoomInfo(ctx context.Context, roomID int64) (*LiveRoomInfoResponse, error) {
+	params := url.Values{}
+	params.Add("room_id", fmt.Sprintf("%d", roomID))
+
+	body, err := c.doGet(ctx, c.liveBaseURL+"/room/v1/Room/get_info?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var roomResp LiveRoomInfoResponse
+	if err := json.Unmarshal(body, &roomResp); err != nil {
+		return nil, fmt.Errorf("解析JSON失败: %v", err)
+	}
+
+	// 1: 房间不存在, 60004: 直播间不存在
+	if roomResp.Code == 1 || roomResp.Code == 60004 {
+		return nil, fmt.Errorf("%w: %d", ErrLiveRoomNotFound, roomID)
+	}
+	if roomResp.Code != 0 {
+		return nil, fmt.Errorf("API返回错误，错误码: %d, 错误信息: %s", roomResp.Code, roomResp.Message)
+	}
+
+	return &roomResp, nil
+}
+
+// GetLiveDanmuInfo 获取直播间弹幕服务器地址和认证密钥
+// roomID 必须是真实房间号；未登录时服务器下发的弹幕会隐藏用户名
+func (c *BilibiliClient) GetLiveDanmuInfo(ctx context.Context, roomID int64, commentOptions ...CommentOption) (*LiveDanmuInfoResponse, error) {
+	params := url.Values{}
+	params.Add("id", fmt.Sprintf("%d", roomID))
+	params.Add("type", "0")
+
+	// 使用WBI签名请求
+	fullURL := c.liveBaseURL + "/xlive/web-room/v1/index/getDanmuInfo?" + SignParams(params, c.GetWBIKey(ctx)).Encode()
+	body, err := c.doGet(ctx, fullURL, newCommentOptions(commentOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	var infoResp LiveDanmuInfoResponse
+	if err := json.Unmarshal(body, &infoResp); err != nil {
+		return nil, fmt.Errorf("解析JSON失败: %v", err)
+	}
+
+	if infoResp.Code != 0 {
+		return nil, fmt.Errorf("API返回错误，错误码: %d, 错误信息: %s", infoResp.Code, infoResp.Message)
+	}
+
+	return &infoResp, nil
+}
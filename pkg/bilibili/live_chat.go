@@ -0,0 +1,676 @@
+package bilibili
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// 直播弹幕协议操作码
+const (
+	LiveOpHeartbeat      = 2 // 客户端心跳
+	LiveOpHeartbeatReply = 3 // 心跳回复，正文为4字节人气值
+	LiveOpMessage        = 5 // 通知消息，正文为JSON
+	LiveOpAuth           = 7 // 认证（进房）
+	LiveOpAuthReply      = 8 // 认证回复
+)
+
+// 直播弹幕协议正文格式（协议版本）
+const (
+	LiveProtoJSON   = 0 // 未压缩的JSON
+	LiveProtoInt    = 1 // 心跳、认证等控制包
+	LiveProtoZlib   = 2 // zlib压缩，解压后是若干个完整数据包
+	LiveProtoBrotli = 3 // brotli压缩，解压后是若干个完整数据包
+)
+
+// 直播间事件类型
+const (
+	LiveEventDanmaku   = "danmaku"    // 弹幕（DANMU_MSG）
+	LiveEventSuperChat = "super_chat" // 醒目留言（SUPER_CHAT_MESSAGE）
+	LiveEventGift      = "gift"       // 礼物（SEND_GIFT）
+)
+
+const (
+	// DefaultLiveHeartbeat 默认心跳间隔，服务器约70秒收不到心跳会断开连接
+	DefaultLiveHeartbeat = 30 * time.Second
+	// DefaultLiveChatURL 接口没有返回服务器列表时使用的弹幕服务器
+	DefaultLiveChatURL = "wss://broadcastlv.chat.bilibili.com/sub"
+
+	liveHeaderSize    = 16
+	liveDialTimeout   = 10 * time.Second
+	liveAuthTimeout   = 10 * time.Second
+	liveMaxPacketSize = 16 << 20
+)
+
+// ErrLiveAuthFailed 弹幕服务器认证失败
+var ErrLiveAuthFailed = errors.New("live chat auth failed")
+
+// LivePacket 直播弹幕协议数据包
+type LivePacket struct {
+	ProtoVer  uint16
+	Operation uint32
+	Body      []byte
+}
+
+// EncodeLivePacket 编码数据包
+// 包头16字节，大端序：包长度(4) 包头长度(2) 协议版本(2) 操作码(4) 序号(4)
+func EncodeLivePacket(protoVer uint16, operation uint32, body []byte) []byte {
+	buf := make([]byte, liveHeaderSize+len(body))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(buf)))
+	binary.BigEndian.PutUint16(buf[4:6], liveHeaderSize)
+	binary.BigEndian.PutUint16(buf[6:8], protoVer)
+	binary.BigEndian.PutUint32(buf[8:12], operation)
+	binary.BigEndian.PutUint32(buf[12:16], 1)
+	copy(buf[liveHeaderSize:], body)
+	return buf
+}
+
+// BrotliDecoder brotli解压函数
+// 标准库不支持brotli，项目也未引入第三方实现，服务端的直播抓取始终请求zlib压缩（协议版本2）；
+// 调用方自行接入brotli实现后可通过 WithBrotliDecoder 设置
+type BrotliDecoder func(r io.Reader) io.Reader
+
+// DecodeLivePackets 解码一条WebSocket消息中的全部数据包
+// 压缩包会被解压并展开为其中的数据包；收到brotli压缩包但 brotli 为 nil 时返回错误
+func DecodeLivePackets(data []byte, brotli BrotliDecoder) ([]LivePacket, error) {
+	var packets []LivePacket
+	for len(data) > 0 {
+		if len(data) < liveHeaderSize {
+			return nil, fmt.Errorf("数据包不完整: 剩余%d字节", len(data))
+		}
+		packetLen := int(binary.BigEndian.Uint32(data[0:4]))
+		headerLen := int(binary.BigEndian.Uint16(data[4:6]))
+		if headerLen < liveHeaderSize || packetLen < headerLen || packetLen > len(data) {
+			return nil, fmt.Errorf("数据包长度异常: 包长度%d, 包头长度%d, 剩余%d字节", packetLen, headerLen, len(data))
+		}
+
+		packet := LivePacket{
+			ProtoVer:  binary.BigEndian.Uint16(data[6:8]),
+			Operation: binary.BigEndian.Uint32(data[8:12]),
+			Body:      data[headerLen:packetLen],
+		}
+		data = data[packetLen:]
+
+		if packet.ProtoVer != LiveProtoZlib && packet.ProtoVer != LiveProtoBrotli {
+			packets = append(packets, packet)
+			continue
+		}
+
+		inflated, err := inflateLiveBody(packet.ProtoVer, packet.Body, brotli)
+		if err != nil {
+			return nil, err
+		}
+		inner, err := DecodeLivePackets(inflated, brotli)
+		if err != nil {
+			return nil, err
+		}
+		packets = append(packets, inner...)
+	}
+	return packets, nil
+}
+
+// inflateLiveBody 解压压缩包的正文
+func inflateLiveBody(protoVer uint16, body []byte, brotli BrotliDecoder) ([]byte, error) {
+	var r io.Reader
+	if protoVer == LiveProtoZlib {
+		zr, err := zlib.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("zlib解压失败: %v", err)
+		}
+		defer zr.Close()
+		r = zr
+	} else {
+		if brotli == nil {
+			return nil, errors.New("收到brotli压缩数据包，但未设置解压函数")
+		}
+		r = brotli(bytes.NewReader(body))
+	}
+
+	// 限制解压后的大小，避免异常数据占用大量内存
+	inflated, err := io.ReadAll(io.LimitReader(r, liveMaxPacketSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("解压数据包失败: %v", err)
+	}
+	if len(inflated) > liveMaxPacketSize {
+		return nil, fmt.Errorf("解压后的数据包过大: 超过%d字节", liveMaxPacketSize)
+	}
+	return inflated, nil
+}
+
+// LiveEvent 直播间事件
+type LiveEvent struct {
+	Type       string    `json:"type"` // LiveEvent* 常量
+	Time       time.Time `json:"time"`
+	UID        int64     `json:"uid"`
+	Uname      string    `json:"uname"`
+	Content    string    `json:"content,omitempty"` // 弹幕或醒目留言内容
+	MedalName  string    `json:"medal_name,omitempty"`
+	MedalLevel int       `json:"medal_level,omitempty"`
+	GiftName   string    `json:"gift_name,omitempty"`
+	GiftNum    int       `json:"gift_num,omitempty"`
+	Price      float64   `json:"price,omitempty"` // 金额（元）：醒目留言价格或付费礼物总价，免费礼物为0
+}
+
+// ParseLiveMessage 解析通知消息（LiveOpMessage 的正文）
+// 只解析弹幕、醒目留言和礼物，其他消息返回 nil, nil
+func ParseLiveMessage(body []byte) (*LiveEvent, error) {
+	var msg struct {
+		Cmd  string            `json:"cmd"`
+		Info []json.RawMessage `json:"info"`
+		Data json.RawMessage   `json:"data"`
+	}
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("解析JSON失败: %v", err)
+	}
+
+	// cmd 可能带有版本后缀，如 DANMU_MSG:4:0:2:2:2:0
+	cmd := msg.Cmd
+	if i := strings.Index(cmd, ":"); i >= 0 {
+		cmd = cmd[:i]
+	}
+
+	switch cmd {
+	case "DANMU_MSG":
+		return parseLiveDanmaku(msg.Info)
+	case "SUPER_CHAT_MESSAGE":
+		return parseLiveSuperChat(msg.Data)
+	case "SEND_GIFT":
+		return parseLiveGift(msg.Data)
+	}
+	return nil, nil
+}
+
+// parseLiveDanmaku 解析弹幕消息
+// info[0][4] 发送时间（毫秒），info[1] 内容，info[2] [uid, 用户名, ...]，info[3] [粉丝牌等级, 粉丝牌名称, ...]
+func parseLiveDanmaku(info []json.RawMessage) (*LiveEvent, error) {
+	if len(info) < 3 {
+		return nil, fmt.Errorf("弹幕消息格式异常: info长度%d", len(info))
+	}
+
+	event := &LiveEvent{Type: LiveEventDanmaku, Time: time.Now()}
+
+	var meta []json.RawMessage
+	if err := json.Unmarshal(info[0], &meta); err == nil && len(meta) > 4 {
+		var ms int64
+		if json.Unmarshal(meta[4], &ms) == nil && ms > 0 {
+			event.Time = time.Unix(0, ms*int64(time.Millisecond))
+		}
+	}
+
+	if err := json.Unmarshal(info[1], &event.Content); err != nil {
+		return nil, fmt.Errorf("弹幕消息格式异常: %v", err)
+	}
+
+	var user []json.RawMessage
+	if err := json.Unmarshal(info[2], &user); err != nil || len(user) < 2 {
+		return nil, errors.New("弹幕消息格式异常: 缺少用户信息")
+	}
+	json.Unmarshal(user[0], &event.UID)
+	json.Unmarshal(user[1], &event.Uname)
+
+	if len(info) > 3 {
+		var medal []json.RawMessage
+		if json.Unmarshal(info[3], &medal) == nil && len(medal) >= 2 {
+			json.Unmarshal(medal[0], &event.MedalLevel)
+			json.Unmarshal(medal[1], &event.MedalName)
+		}
+	}
+
+	return event, nil
+}
+
+// liveMedalInfo 醒目留言和礼物消息中的粉丝牌
+type liveMedalInfo struct {
+	MedalName  string `json:"medal_name"`
+	MedalLevel int    `json:"medal_level"`
+}
+
+// parseLiveSuperChat 解析醒目留言消息
+func parseLiveSuperChat(data json.RawMessage) (*LiveEvent, error) {
+	var sc struct {
+		UID       int64   `json:"uid"`
+		Price     float64 `json:"price"` // 元
+		Message   string  `json:"message"`
+		StartTime int64   `json:"start_time"`
+		UserInfo  struct {
+			Uname string `json:"uname"`
+		} `json:"user_info"`
+		MedalInfo *liveMedalInfo `json:"medal_info"`
+	}
+	if err := json.Unmarshal(data, &sc); err != nil {
+		return nil, fmt.Errorf("醒目留言消息格式异常: %v", err)
+	}
+
+	event := &LiveEvent{
+		Type:    LiveEventSuperChat,
+		Time:    time.Now(),
+		UID:     sc.UID,
+		Uname:   sc.UserInfo.Uname,
+		Content: sc.Message,
+		Price:   sc.Price,
+	}
+	if sc.StartTime > 0 {
+		event.Time = time.Unix(sc.StartTime, 0)
+	}
+	if sc.MedalInfo != nil {
+		event.MedalName = sc.MedalInfo.MedalName
+		event.MedalLevel = sc.MedalInfo.MedalLevel
+	}
+	return event, nil
+}
+
+// parseLiveGift 解析礼物消息
+// 付费礼物（金瓜子）total_coin 以1000金瓜子=1元换算，银瓜子礼物金额记为0
+func parseLiveGift(data json.RawMessage) (*LiveEvent, error) {
+	var gift struct {
+		UID       int64          `json:"uid"`
+		Uname     string         `json:"uname"`
+		GiftName  string         `json:"giftName"`
+		Num       int            `json:"num"`
+		CoinType  string         `json:"coin_type"`
+		TotalCoin int64          `json:"total_coin"`
+		Timestamp int64          `json:"timestamp"`
+		MedalInfo *liveMedalInfo `json:"medal_info"`
+	}
+	if err := json.Unmarshal(data, &gift); err != nil {
+		return nil, fmt.Errorf("礼物消息格式异常: %v", err)
+	}
+
+	event := &LiveEvent{
+		Type:     LiveEventGift,
+		Time:     time.Now(),
+		UID:      gift.UID,
+		Uname:    gift.Uname,
+		GiftName: gift.GiftName,
+		GiftNum:  gift.Num,
+	}
+	if gift.CoinType == "gold" {
+		event.Price = float64(gift.TotalCoin) / 1000
+	}
+	if gift.Timestamp > 0 {
+		event.Time = time.Unix(gift.Timestamp, 0)
+	}
+	if gift.MedalInfo != nil {
+		event.MedalName = gift.MedalInfo.MedalName
+		event.MedalLevel = gift.MedalInfo.MedalLevel
+	}
+	return event, nil
+}
+
+// LiveChatOptions 直播弹幕连接选项
+type LiveChatOptions struct {
+	uid       int64
+	sessdata  string
+	buvid     string
+	heartbeat time.Duration
+	brotli    BrotliDecoder
+	chatURL   string
+}
+
+// LiveChatOption 直播弹幕连接选项类型
+type LiveChatOption func(*LiveChatOptions)
+
+// WithLiveAuth 登录认证选项，未登录时服务器下发的弹幕会隐藏用户名和UID
+func WithLiveAuth(uid int64, sessdata string) LiveChatOption {
+	return func(opts *LiveChatOptions) {
+		opts.uid = uid
+		opts.sessdata = sessdata
+	}
+}
+
+// WithLiveBuvid 设备标识选项（Cookie 中的 buvid3）
+func WithLiveBuvid(buvid string) LiveChatOption {
+	return func(opts *LiveChatOptions) {
+		opts.buvid = buvid
+	}
+}
+
+// WithLiveHeartbeat 心跳间隔选项，默认 DefaultLiveHeartbeat
+func WithLiveHeartbeat(interval time.Duration) LiveChatOption {
+	return func(opts *LiveChatOptions) {
+		if interval > 0 {
+			opts.heartbeat = interval
+		}
+	}
+}
+
+// WithBrotliDecoder brotli解压选项，设置后向服务器请求brotli压缩（协议版本3）
+// 未设置时请求zlib压缩，两者收到的事件相同，brotli只是流量更小
+func WithBrotliDecoder(decoder BrotliDecoder) LiveChatOption {
+	return func(opts *LiveChatOptions) {
+		opts.brotli = decoder
+	}
+}
+
+// WithLiveChatURL 指定弹幕服务器地址（如 ws://127.0.0.1:8080/sub），忽略接口返回的服务器列表
+func WithLiveChatURL(chatURL string) LiveChatOption {
+	return func(opts *LiveChatOptions) {
+		opts.chatURL = chatURL
+	}
+}
+
+// LiveChatConn 直播弹幕连接
+type LiveChatConn struct {
+	ws         *websocket.Conn
+	roomID     int64
+	options    *LiveChatOptions
+	writeMu    sync.Mutex
+	popularity int64 // 原子访问
+	closeOnce  sync.Once
+}
+
+// ConnectLiveChat 连接直播间弹幕服务器并完成认证
+// roomID 必须是真实房间号（LiveRoomInfo.RoomID）；依次尝试接口返回的服务器，全部失败时返回最后一个错误
+func (c *BilibiliClient) ConnectLiveChat(ctx context.Context, roomID int64, opts ...LiveChatOption) (*LiveChatConn, error) {
+	options := &LiveChatOptions{heartbeat: DefaultLiveHeartbeat}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var commentOpts []CommentOption
+	if options.sessdata != "" {
+		commentOpts = append(commentOpts, WithCookie(options.sessdata))
+	}
+	info, err := c.GetLiveDanmuInfo(ctx, roomID, commentOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("获取弹幕服务器信息失败: %w", err)
+	}
+
+	var chatURLs []string
+	if options.chatURL != "" {
+		chatURLs = append(chatURLs, options.chatURL)
+	} else {
+		for _, host := range info.Data.HostList {
+			switch {
+			case host.Host == "":
+			case host.WssPort > 0:
+				chatURLs = append(chatURLs, fmt.Sprintf("wss://%s:%d/sub", host.Host, host.WssPort))
+			case host.WsPort > 0:
+				chatURLs = append(chatURLs, fmt.Sprintf("ws://%s:%d/sub", host.Host, host.WsPort))
+			}
+		}
+		if len(chatURLs) == 0 {
+			chatURLs = append(chatURLs, DefaultLiveChatURL)
+		}
+	}
+
+	var lastErr error
+	for _, chatURL := range chatURLs {
+		conn, err := dialLiveChat(ctx, chatURL, roomID, info.Data.Token, options)
+		if err == nil {
+			return conn, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("连接弹幕服务器失败: %w", lastErr)
+}
+
+// dialLiveChat 建立WebSocket连接并发送认证包，等待认证回复
+func dialLiveChat(ctx context.Context, chatURL string, roomID int64, token string, options *LiveChatOptions) (*LiveChatConn, error) {
+	config, err := websocket.NewConfig(chatURL, "https://live.bilibili.com")
+	if err != nil {
+		return nil, fmt.Errorf("弹幕服务器地址无效: %v", err)
+	}
+	config.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
+	if options.sessdata != "" {
+		config.Header.Set("Cookie", "SESSDATA="+options.sessdata)
+	}
+
+	ws, err := dialLiveWebSocket(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	conn := &LiveChatConn{ws: ws, roomID: roomID, options: options}
+
+	// 认证期间 ctx 取消时关闭连接，使阻塞的读取立即返回
+	authDone := make(chan struct{})
+	defer close(authDone)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-authDone:
+		}
+	}()
+
+	if err := conn.authenticate(token); err != nil {
+		conn.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	return conn, nil
+}
+
+// dialLiveWebSocket 建立TCP（wss 时为TLS）连接并完成WebSocket握手
+// websocket.DialConfig 不支持 ctx，这里自行建立连接：ctx 取消或超过 liveDialTimeout 时中断握手
+func dialLiveWebSocket(ctx context.Context, config *websocket.Config) (*websocket.Conn, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, liveDialTimeout)
+	defer cancel()
+
+	location := config.Location
+	port := location.Port()
+	if port == "" {
+		port = "80"
+		if location.Scheme == "wss" {
+			port = "443"
+		}
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(dialCtx, "tcp", net.JoinHostPort(location.Hostname(), port))
+	if err != nil {
+		return nil, err
+	}
+
+	// 握手期间 ctx 取消时关闭连接，使阻塞的读写立即返回
+	handshakeDone := make(chan struct{})
+	go func() {
+		select {
+		case <-dialCtx.Done():
+			conn.Close()
+		case <-handshakeDone:
+		}
+	}()
+	deadline, _ := dialCtx.Deadline()
+	conn.SetDeadline(deadline)
+
+	ws, err := handshakeLiveWebSocket(dialCtx, config, conn)
+	close(handshakeDone)
+	if err == nil && dialCtx.Err() != nil {
+		err = dialCtx.Err()
+	}
+	if err != nil {
+		conn.Close()
+		// 读写超时说明已到达 dialCtx 的截止时间，等它结束后再判断是否由 ctx 到期导致
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			<-dialCtx.Done()
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("WebSocket握手失败: %v", err)
+	}
+
+	conn.SetDeadline(time.Time{})
+	return ws, nil
+}
+
+// handshakeLiveWebSocket 在已建立的连接上完成TLS（wss 时）和WebSocket握手
+func handshakeLiveWebSocket(ctx context.Context, config *websocket.Config, conn net.Conn) (*websocket.Conn, error) {
+	var rwc net.Conn = conn
+	if config.Location.Scheme == "wss" {
+		tlsConfig := &tls.Config{}
+		if config.TlsConfig != nil {
+			tlsConfig = config.TlsConfig.Clone()
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = config.Location.Hostname()
+		}
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return nil, err
+		}
+		rwc = tlsConn
+	}
+	return websocket.NewClient(config, rwc)
+}
+
+// authenticate 发送认证包并等待认证回复
+func (lc *LiveChatConn) authenticate(token string) error {
+	protoVer := LiveProtoZlib
+	if lc.options.brotli != nil {
+		protoVer = LiveProtoBrotli
+	}
+
+	auth := map[string]interface{}{
+		"uid":      lc.options.uid,
+		"roomid":   lc.roomID,
+		"protover": protoVer,
+		"platform": "web",
+		"type":     2,
+		"key":      token,
+	}
+	if lc.options.buvid != "" {
+		auth["buvid"] = lc.options.buvid
+	}
+	body, err := json.Marshal(auth)
+	if err != nil {
+		return fmt.Errorf("序列化认证包失败: %v", err)
+	}
+	if err := lc.send(LiveOpAuth, body); err != nil {
+		return fmt.Errorf("发送认证包失败: %v", err)
+	}
+
+	lc.ws.SetReadDeadline(time.Now().Add(liveAuthTimeout))
+	defer lc.ws.SetReadDeadline(time.Time{})
+
+	packets, err := lc.receive()
+	if err != nil {
+		return fmt.Errorf("等待认证回复失败: %v", err)
+	}
+	for _, packet := range packets {
+		if packet.Operation != LiveOpAuthReply {
+			continue
+		}
+		var reply struct {
+			Code int `json:"code"`
+		}
+		if err := json.Unmarshal(packet.Body, &reply); err != nil {
+			return fmt.Errorf("解析认证回复失败: %v", err)
+		}
+		if reply.Code != 0 {
+			return fmt.Errorf("%w: 错误码 %d", ErrLiveAuthFailed, reply.Code)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: 未收到认证回复", ErrLiveAuthFailed)
+}
+
+// send 发送一个控制包，WebSocket 不支持并发写，需要加锁
+func (lc *LiveChatConn) send(operation uint32, body []byte) error {
+	lc.writeMu.Lock()
+	defer lc.writeMu.Unlock()
+	return websocket.Message.Send(lc.ws, EncodeLivePacket(LiveProtoInt, operation, body))
+}
+
+// receive 读取一条WebSocket消息并解码其中的数据包
+func (lc *LiveChatConn) receive() ([]LivePacket, error) {
+	var data []byte
+	if err := websocket.Message.Receive(lc.ws, &data); err != nil {
+		return nil, err
+	}
+	return DecodeLivePackets(data, lc.options.brotli)
+}
+
+// Run 发送心跳并持续接收直播间事件，直到连接断开或 ctx 取消
+// handler 在当前 goroutine 中按接收顺序调用；ctx 取消时返回 ctx.Err()
+func (lc *LiveChatConn) Run(ctx context.Context, handler func(LiveEvent)) error {
+	heartbeat := lc.options.heartbeat
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
+		// 认证成功后立即发送第一个心跳
+		if err := lc.send(LiveOpHeartbeat, nil); err != nil {
+			lc.Close()
+			return
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				lc.Close()
+				return
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := lc.send(LiveOpHeartbeat, nil); err != nil {
+					lc.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		// 每个心跳周期都会收到心跳回复，长时间没有数据说明连接已失效
+		lc.ws.SetReadDeadline(time.Now().Add(2*heartbeat + liveAuthTimeout))
+		packets, err := lc.receive()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		for _, packet := range packets {
+			switch packet.Operation {
+			case LiveOpHeartbeatReply:
+				if len(packet.Body) >= 4 {
+					atomic.StoreInt64(&lc.popularity, int64(binary.BigEndian.Uint32(packet.Body[:4])))
+				}
+			case LiveOpMessage:
+				event, err := ParseLiveMessage(packet.Body)
+				if err != nil || event == nil {
+					continue
+				}
+				handler(*event)
+			}
+		}
+	}
+}
+
+// Popularity 最近一次心跳回复中的人气值
+func (lc *LiveChatConn) Popularity() int64 {
+	return atomic.LoadInt64(&lc.popularity)
+}
+
+// Close 关闭连接，可重复调用
+func (lc *LiveChatConn) Close() error {
+	var err error
+	lc.closeOnce.Do(func() {
+		err = lc.ws.Close()
+	})
+	return err
+}
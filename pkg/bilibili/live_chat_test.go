@@ -0,0 +1,290 @@
+package bilibili
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestEncodeLivePacketHeader(t *testing.T) {
+	body := []byte(`{"roomid":1}`)
+	packet := EncodeLivePacket(LiveProtoInt, LiveOpAuth, body)
+
+	if len(packet) != liveHeaderSize+len(body) {
+		t.Fatalf("packet length = %d, want %d", len(packet), liveHeaderSize+len(body))
+	}
+	if got := binary.BigEndian.Uint32(packet[0:4]); got != uint32(len(packet)) {
+		t.Errorf("packet length field = %d, want %d", got, len(packet))
+	}
+	if got := binary.BigEndian.Uint16(packet[4:6]); got != liveHeaderSize {
+		t.Errorf("header length field = %d, want %d", got, liveHeaderSize)
+	}
+	if got := binary.BigEndian.Uint16(packet[6:8]); got != LiveProtoInt {
+		t.Errorf("protover field = %d, want %d", got, LiveProtoInt)
+	}
+	if got := binary.BigEndian.Uint32(packet[8:12]); got != LiveOpAuth {
+		t.Errorf("operation field = %d, want %d", got, LiveOpAuth)
+	}
+	if got := binary.BigEndian.Uint32(packet[12:16]); got != 1 {
+		t.Errorf("sequence field = %d, want 1", got)
+	}
+	if !bytes.Equal(packet[liveHeaderSize:], body) {
+		t.Errorf("body = %q, want %q", packet[liveHeaderSize:], body)
+	}
+}
+
+func TestDecodeLivePackets(t *testing.T) {
+	heartbeatReply := EncodeLivePacket(LiveProtoInt, LiveOpHeartbeatReply, []byte{0, 0, 0x30, 0x39})
+	message := EncodeLivePacket(LiveProtoJSON, LiveOpMessage, []byte(`{"cmd":"DANMU_MSG"}`))
+
+	tests := []struct {
+		name    string
+		data    []byte
+		brotli  BrotliDecoder
+		wantOps []uint32
+		wantErr bool
+	}{
+		{"single packet", message, nil, []uint32{LiveOpMessage}, false},
+		{"concatenated packets", concatBytes(heartbeatReply, message), nil, []uint32{LiveOpHeartbeatReply, LiveOpMessage}, false},
+		{"zlib packet", zlibLivePacket(t, concatBytes(message, message)), nil, []uint32{LiveOpMessage, LiveOpMessage}, false},
+		{"zlib packet after plain packet", concatBytes(heartbeatReply, zlibLivePacket(t, message)), nil, []uint32{LiveOpHeartbeatReply, LiveOpMessage}, false},
+		{"brotli packet without decoder", EncodeLivePacket(LiveProtoBrotli, LiveOpMessage, []byte{1, 2, 3}), nil, nil, true},
+		{"truncated header", message[:liveHeaderSize-1], nil, nil, true},
+		{"truncated body", message[:len(message)-1], nil, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packets, err := DecodeLivePackets(tt.data, tt.brotli)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeLivePackets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(packets) != len(tt.wantOps) {
+				t.Fatalf("got %d packets, want %d", len(packets), len(tt.wantOps))
+			}
+			for i, packet := range packets {
+				if packet.Operation != tt.wantOps[i] {
+					t.Errorf("packet %d operation = %d, want %d", i, packet.Operation, tt.wantOps[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConnectLiveChat(t *testing.T) {
+	type serverResult struct {
+		cookie    string
+		auth      map[string]interface{}
+		heartbeat bool
+		err       error
+	}
+	results := make(chan serverResult, 1)
+
+	chat := websocket.Handler(func(ws *websocket.Conn) {
+		result := serverResult{cookie: ws.Request().Header.Get("Cookie")}
+		defer func() { results <- result }()
+
+		// 认证包
+		packets, err := receiveLivePackets(ws)
+		if err != nil {
+			result.err = err
+			return
+		}
+		if len(packets) != 1 || packets[0].Operation != LiveOpAuth || packets[0].ProtoVer != LiveProtoInt {
+			result.err = fmt.Errorf("unexpected auth packets: %+v", packets)
+			return
+		}
+		if err := json.Unmarshal(packets[0].Body, &result.auth); err != nil {
+			result.err = err
+			return
+		}
+		websocket.Message.Send(ws, EncodeLivePacket(LiveProtoInt, LiveOpAuthReply, []byte(`{"code":0}`)))
+
+		// 认证成功后客户端立即发送心跳
+		packets, err = receiveLivePackets(ws)
+		if err != nil {
+			result.err = err
+			return
+		}
+		if len(packets) != 1 || packets[0].Operation != LiveOpHeartbeat {
+			result.err = fmt.Errorf("unexpected heartbeat packets: %+v", packets)
+			return
+		}
+		result.heartbeat = true
+
+		popularity := make([]byte, 4)
+		binary.BigEndian.PutUint32(popularity, 12345)
+		danmaku := `{"cmd":"DANMU_MSG","info":[[0,1,25,16777215,1700000000000],"hello",[42,"tester"],[3,"medal"]]}`
+		websocket.Message.Send(ws, EncodeLivePacket(LiveProtoInt, LiveOpHeartbeatReply, popularity))
+		websocket.Message.Send(ws, zlibLivePacket(t, EncodeLivePacket(LiveProtoJSON, LiveOpMessage, []byte(danmaku))))
+
+		// 等待客户端关闭连接
+		var discard []byte
+		for websocket.Message.Receive(ws, &discard) == nil {
+		}
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/sub", chat)
+	var chatHost string
+	var chatPort int
+	mux.HandleFunc("/x/web-interface/nav", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":0,"data":{"wbi_img":{"img_url":"https://x/7cd084941338484aae1ad9425b84077c.png","sub_url":"https://x/4932caff0ff746eab6f01bf08b70ac45.png"}}}`))
+	})
+	mux.HandleFunc("/xlive/web-room/v1/index/getDanmuInfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("id") != "1000" {
+			t.Errorf("getDanmuInfo id = %q, want 1000", r.URL.Query().Get("id"))
+		}
+		fmt.Fprintf(w, `{"code":0,"data":{"token":"test-token","host_list":[{"host":%q,"ws_port":%d}]}}`, chatHost, chatPort)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	chatHost = u.Hostname()
+	chatPort, _ = strconv.Atoi(u.Port())
+
+	client := NewBilibiliClient(WithBaseURL(srv.URL), WithLiveBaseURL(srv.URL), WithRateLimiter(nil))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := client.ConnectLiveChat(ctx, 1000, WithLiveAuth(42, "sess"), WithLiveBuvid("buvid-1"))
+	if err != nil {
+		t.Fatalf("ConnectLiveChat() error = %v", err)
+	}
+	defer conn.Close()
+
+	runCtx, stop := context.WithCancel(ctx)
+	var events []LiveEvent
+	err = conn.Run(runCtx, func(event LiveEvent) {
+		events = append(events, event)
+		stop()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() error = %v, want context.Canceled", err)
+	}
+
+	result := <-results
+	if result.err != nil {
+		t.Fatalf("server: %v", result.err)
+	}
+	if result.cookie != "SESSDATA=sess" {
+		t.Errorf("Cookie header = %q, want SESSDATA=sess", result.cookie)
+	}
+	wantAuth := map[string]interface{}{
+		"uid":      float64(42),
+		"roomid":   float64(1000),
+		"protover": float64(LiveProtoZlib),
+		"platform": "web",
+		"type":     float64(2),
+		"key":      "test-token",
+		"buvid":    "buvid-1",
+	}
+	for key, want := range wantAuth {
+		if got := result.auth[key]; got != want {
+			t.Errorf("auth[%q] = %v, want %v", key, got, want)
+		}
+	}
+	if !result.heartbeat {
+		t.Error("server did not receive heartbeat")
+	}
+
+	if conn.Popularity() != 12345 {
+		t.Errorf("Popularity() = %d, want 12345", conn.Popularity())
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	if e := events[0]; e.Type != LiveEventDanmaku || e.UID != 42 || e.Uname != "tester" || e.Content != "hello" || e.MedalName != "medal" {
+		t.Errorf("unexpected event: %+v", e)
+	}
+}
+
+func TestConnectLiveChatAuthFailed(t *testing.T) {
+	chat := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		receiveLivePackets(ws)
+		websocket.Message.Send(ws, EncodeLivePacket(LiveProtoInt, LiveOpAuthReply, []byte(`{"code":-101}`)))
+	}))
+	defer chat.Close()
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":0,"data":{"token":"t","host_list":[]}}`))
+	}))
+	defer api.Close()
+
+	client := NewBilibiliClient(WithBaseURL(api.URL), WithLiveBaseURL(api.URL), WithRateLimiter(nil))
+	_, err := client.ConnectLiveChat(context.Background(), 1, WithLiveChatURL("ws"+chat.URL[len("http"):]+"/sub"))
+	if !errors.Is(err, ErrLiveAuthFailed) {
+		t.Fatalf("ConnectLiveChat() error = %v, want ErrLiveAuthFailed", err)
+	}
+}
+
+func TestDialLiveChatHonorsContext(t *testing.T) {
+	// 接受TCP连接但从不响应WebSocket握手的服务器
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = dialLiveChat(ctx, "ws://"+listener.Addr().String()+"/sub", 1, "t", &LiveChatOptions{heartbeat: DefaultLiveHeartbeat})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("dialLiveChat() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("dialLiveChat() returned after %v, want it to stop when ctx expires", elapsed)
+	}
+}
+
+// receiveLivePackets 服务端读取一条WebSocket消息并解码
+func receiveLivePackets(ws *websocket.Conn) ([]LivePacket, error) {
+	var data []byte
+	if err := websocket.Message.Receive(ws, &data); err != nil {
+		return nil, err
+	}
+	return DecodeLivePackets(data, nil)
+}
+
+// zlibLivePacket 将若干数据包压缩为一个zlib数据包
+func zlibLivePacket(t *testing.T, packets []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(packets); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return EncodeLivePacket(LiveProtoZlib, LiveOpMessage, buf.Bytes())
+}
+
+func concatBytes(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
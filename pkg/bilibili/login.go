@@ -0,0 +1,210 @@
+package bilibili
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 扫码登录状态（轮询接口 data.code）
+const (
+	QRLoginSuccess    = 0     // 登录成功
+	QRLoginExpired    = 86038 // 二维码已失效
+	QRLoginScanned    = 86090 // 已扫码，等待手机端确认
+	QRLoginNotScanned = 86101 // 未扫码
+)
+
+// LoginCookieNames 登录后需要保存的Cookie
+var LoginCookieNames = []string{"SESSDATA", "bili_jct", "DedeUserID", "DedeUserID__ckMd5", "buvid3", "buvid4"}
+
+// QRCodeGenerateResponse 申请登录二维码响应
+type QRCodeGenerateResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		URL       string `json:"url"`        // 二维码内容，由前端生成二维码图片
+		QRCodeKey string `json:"qrcode_key"` // 轮询登录状态的密钥，有效期180秒
+	} `json:"data"`
+}
+
+// QRCodePollResponse 扫码登录状态响应
+type QRCodePollResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		URL          string `json:"url"` // 登录成功时为跨域跳转地址，查询参数中带有登录Cookie
+		RefreshToken string `json:"refresh_token"`
+		Timestamp    int64  `json:"timestamp"`
+		Code         int    `json:"code"` // QRLogin* 常量
+		Message      string `json:"message"`
+	} `json:"data"`
+}
+
+// QRLoginResult 扫码登录结果
+type QRLoginResult struct {
+	Status       int               // QRLogin* 常量
+	Message      string            // 状态说明
+	Cookies      map[string]string // 登录成功时的Cookie（SESSDATA、bili_jct、DedeUserID 等）
+	RefreshToken string
+	ExpiresAt    time.Time // SESSDATA 过期时间，未知时为零值
+}
+
+// SpiResponse 设备标识（buvid）响应
+type SpiResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Buvid3 string `json:"b_3"`
+		Buvid4 string `json:"b_4"`
+	} `json:"data"`
+}
+
+// GenerateLoginQRCode 申请网页端登录二维码
+func (c *BilibiliClient) GenerateLoginQRCode(ctx context.Context) (*QRCodeGenerateResponse, error) {
+	body, err := c.doGet(ctx, c.passportURL+"/x/passport-login/web/qrcode/generate", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var qrResp QRCodeGenerateResponse
+	if err := json.Unmarshal(body, &qrResp); err != nil {
+		return nil, fmt.Errorf("解析JSON失败: %v", err)
+	}
+
+	if qrResp.Code != 0 {
+		return nil, fmt.Errorf("API返回错误，错误码: %d, 错误信息: %s", qrResp.Code, qrResp.Message)
+	}
+
+	return &qrResp, nil
+}
+
+// PollLoginQRCode 查询扫码登录状态
+// 登录成功时从跳转地址的查询参数中取出Cookie；未扫码、待确认和已失效都不是错误，通过 Status 区分
+func (c *BilibiliClient) PollLoginQRCode(ctx context.Context, qrcodeKey string) (*QRLoginResult, error) {
+	params := url.Values{}
+	params.Add("qrcode_key", qrcodeKey)
+
+	body, err := c.doGet(ctx, c.passportURL+"/x/passport-login/web/qrcode/poll?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var pollResp QRCodePollResponse
+	if err := json.Unmarshal(body, &pollResp); err != nil {
+		return nil, fmt.Errorf("解析JSON失败: %v", err)
+	}
+
+	if pollResp.Code != 0 {
+		return nil, fmt.Errorf("API返回错误，错误码: %d, 错误信息: %s", pollResp.Code, pollResp.Message)
+	}
+
+	result := &QRLoginResult{
+		Status:       pollResp.Data.Code,
+		Message:      pollResp.Data.Message,
+		RefreshToken: pollResp.Data.RefreshToken,
+	}
+	if result.Status != QRLoginSuccess {
+		return result, nil
+	}
+
+	result.Cookies = parseCrossDomainCookies(pollResp.Data.URL)
+	if result.Cookies["SESSDATA"] == "" {
+		return nil, fmt.Errorf("登录成功但未返回SESSDATA")
+	}
+	if expires, err := strconv.ParseInt(result.Cookies["Expires"], 10, 64); err == nil && expires > 0 {
+		result.ExpiresAt = time.Unix(expires, 0)
+	}
+	delete(result.Cookies, "Expires")
+	delete(result.Cookies, "gourl")
+
+	return result, nil
+}
+
+// parseCrossDomainCookies 解析登录跳转地址中的Cookie
+// 不对参数值解码：SESSDATA 中的 %2C 等编码需要原样放入 Cookie 头
+func parseCrossDomainCookies(rawURL string) map[string]string {
+	cookies := make(map[string]string)
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return cookies
+	}
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		key, value := pair, ""
+		if i := strings.Index(pair, "="); i >= 0 {
+			key, value = pair[:i], pair[i+1:]
+		}
+		if key != "" && value != "" {
+			cookies[key] = value
+		}
+	}
+	return cookies
+}
+
+// GetBuvid 获取设备标识 buvid3 和 buvid4（登录后与账号Cookie一起使用，降低风控概率）
+func (c *BilibiliClient) GetBuvid(ctx context.Context) (*SpiResponse, error) {
+	body, err := c.get(ctx, "/x/frontend/finger/spi", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var spiResp SpiResponse
+	if err := json.Unmarshal(body, &spiResp); err != nil {
+		return nil, fmt.Errorf("解析JSON失败: %v", err)
+	}
+
+	if spiResp.Code != 0 {
+		return nil, fmt.Errorf("API返回错误，错误码: %d, 错误信息: %s", spiResp.Code, spiResp.Message)
+	}
+
+	return &spiResp, nil
+}
+
+// GetNav 获取当前Cookie对应的登录信息
+// 未登录或登录已失效时接口返回 -101，此时 Data.IsLogin 为 false，不作为错误返回
+func (c *BilibiliClient) GetNav(ctx context.Context, commentOptions ...CommentOption) (*NavResponse, error) {
+	body, err := c.get(ctx, "/x/web-interface/nav", nil, newCommentOptions(commentOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	var navResp NavResponse
+	if err := json.Unmarshal(body, &navResp); err != nil {
+		return nil, fmt.Errorf("解析JSON失败: %v", err)
+	}
+
+	if navResp.Code != 0 && navResp.Code != -101 {
+		return nil, fmt.Errorf("API返回错误，错误码: %d, 错误信息: %s", navResp.Code, navResp.Message)
+	}
+
+	return &navResp, nil
+}
+
+// ParseCookieString 解析浏览器复制的Cookie字符串（如 "SESSDATA=xxx; bili_jct=yyy"）
+// 只有一个值且不含等号时视为 SESSDATA
+func ParseCookieString(cookie string) map[string]string {
+	cookies := make(map[string]string)
+	cookie = strings.TrimSpace(cookie)
+	if cookie == "" {
+		return cookies
+	}
+	if !strings.Contains(cookie, "=") {
+		cookies["SESSDATA"] = cookie
+		return cookies
+	}
+
+	for _, part := range strings.Split(cookie, ";") {
+		part = strings.TrimSpace(part)
+		i := strings.Index(part, "=")
+		if i <= 0 {
+			continue
+		}
+		if value := strings.TrimSpace(part[i+1:]); value != "" {
+			cookies[strings.TrimSpace(part[:i])] = value
+		}
+	}
+	return cookies
+}
@@ -1,5 +1,7 @@
 package bilibili
 
+import "strings"
+
 // CommentMember 评论用户信息
 type CommentMember struct {
 	Mid       string `json:"mid"`
@@ -11,13 +13,56 @@ type CommentMember struct {
 	LevelInfo struct {
 		CurrentLevel int `json:"current_level"`
 	} `json:"level_info"`
+	Vip CommentVip `json:"vip"`
+}
+
+// CommentVip 评论用户的大会员信息
+type CommentVip struct {
+	VipType   int `json:"vipType"`   // 0无，1月度大会员，2年度及以上大会员
+	VipStatus int `json:"vipStatus"` // 1表示生效中
+	Label     struct {
+		Text string `json:"text"` // 如"年度大会员"
+	} `json:"label"`
+}
+
+// IsVip 大会员是否生效中
+func (v CommentVip) IsVip() bool {
+	return v.VipStatus == 1 && v.VipType > 0
 }
 
 // CommentContent 评论内容
 type CommentContent struct {
-	Message string             `json:"message"`
-	Emote   map[string]Emote   `json:"emote"`
-	JumpUrl map[string]JumpUrl `json:"jump_url"`
+	Message  string             `json:"message"`
+	Emote    map[string]Emote   `json:"emote"`
+	JumpUrl  map[string]JumpUrl `json:"jump_url"`
+	Pictures []CommentPicture   `json:"pictures"` // 评论附带的图片
+}
+
+// CommentPicture 评论图片
+type CommentPicture struct {
+	ImgSrc    string  `json:"img_src"`
+	ImgWidth  int     `json:"img_width"`
+	ImgHeight int     `json:"img_height"`
+	ImgSize   float64 `json:"img_size"` // 文件大小（KB）
+}
+
+// PinnedReplies 返回置顶评论（合并两种接口的返回格式，已标记 IsTop）
+func (r *CommentResponse) PinnedReplies() []CommentData {
+	var pinned []CommentData
+	seen := make(map[int64]bool)
+	candidates := append([]CommentData(nil), r.Data.TopReplies...)
+	if r.Data.Upper.Top != nil {
+		candidates = append(candidates, *r.Data.Upper.Top)
+	}
+	for _, c := range candidates {
+		if c.RPID == 0 || seen[c.RPID] {
+			continue
+		}
+		seen[c.RPID] = true
+		c.IsTop = true
+		pinned = append(pinned, c)
+	}
+	return pinned
 }
 
 // Emote 表情信息
@@ -37,6 +82,22 @@ type JumpUrl struct {
 	State int    `json:"state"`
 }
 
+// ReplyControl 评论的附加展示信息
+type ReplyControl struct {
+	Location string `json:"location"` // 如 "IP属地：广东"
+}
+
+// IPLocation 去掉前缀后的IP属地（如"广东"），接口未返回时为空
+func (c CommentData) IPLocation() string {
+	return strings.TrimSpace(strings.TrimPrefix(c.ReplyControl.Location, "IP属地："))
+}
+
+// UpAction UP主对评论的操作
+type UpAction struct {
+	Like  bool `json:"like"`
+	Reply bool `json:"reply"`
+}
+
 // CommentData 评论数据结构
 type CommentData struct {
 	RPID      int64          `json:"rpid"`
@@ -61,6 +122,15 @@ type CommentData struct {
 	Content   CommentContent `json:"content"`
 	Member    CommentMember  `json:"member"`
 	Replies   []CommentData  `json:"replies"` // 子评论列表
+
+	ReplyControl ReplyControl `json:"reply_control"`
+	UpAction     UpAction     `json:"up_action"` // UP主是否点赞/回复了该评论
+
+	// IsTop 是否为置顶评论（抓取时根据置顶列表标记），非API返回字段
+	IsTop bool `json:"is_top,omitempty"`
+
+	// FirstSeen 本地记录的首次抓取时间（Unix秒），非API返回字段
+	FirstSeen int64 `json:"first_seen,omitempty"`
 }
 
 // CommentResponse 代表评论API的响应
@@ -75,6 +145,10 @@ type CommentResponse struct {
 			Num   int `json:"num"`
 			Size  int `json:"size"`
 		} `json:"page"`
+		TopReplies []CommentData `json:"top_replies"` // 置顶评论（/x/v2/reply/main）
+		Upper      struct {
+			Top *CommentData `json:"top"` // UP主置顶评论（/x/v2/reply）
+		} `json:"upper"`
 		Cursor struct {
 			AllCount        int `json:"all_count"`
 			PaginationReply struct {
@@ -95,6 +169,23 @@ type UserInfo struct {
 	Face     string `json:"face"` // 头像URL
 	Coins    int    `json:"coins"`
 	Birthday string `json:"birthday"`
+	Silence  int    `json:"silence"` // 1表示账号已封禁
+	Official struct {
+		Role  int    `json:"role"`  // 0表示未认证
+		Title string `json:"title"` // 认证信息
+	} `json:"official"`
+	Vip struct {
+		Type   int `json:"type"`   // 0无，1月度大会员，2年度及以上大会员
+		Status int `json:"status"` // 1表示生效中
+		Label  struct {
+			Text string `json:"text"`
+		} `json:"label"`
+	} `json:"vip"`
+	LiveRoom struct {
+		RoomID     int64  `json:"roomid"`
+		LiveStatus int    `json:"liveStatus"` // 1表示直播中
+		Title      string `json:"title"`
+	} `json:"live_room"`
 }
 
 // UserResponse 用户信息响应
@@ -104,18 +195,57 @@ type UserResponse struct {
 	Data    UserInfo `json:"data"`
 }
 
+// RelationStat 用户关系统计
+type RelationStat struct {
+	Mid       int64 `json:"mid"`
+	Following int   `json:"following"` // 关注数
+	Follower  int   `json:"follower"`  // 粉丝数
+}
+
+// RelationStatResponse 用户关系统计响应
+type RelationStatResponse struct {
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Data    RelationStat `json:"data"`
+}
+
 // VideoInfo 视频信息
 type VideoInfo struct {
-	BVID      string `json:"bvid"`
-	AID       int64  `json:"aid"`
-	Title     string `json:"title"`
-	Desc      string `json:"desc"`
-	Created   int64  `json:"created"`
-	Duration  int    `json:"duration"`
-	Pic       string `json:"pic"`
-	Owner     Owner  `json:"owner"`
-	Stat      Stat   `json:"stat"`
-	Copyright int    `json:"copyright"`
+	BVID      string      `json:"bvid"`
+	AID       int64       `json:"aid"`
+	CID       int64       `json:"cid"` // 第一个分P的cid（弹幕等接口使用）
+	Title     string      `json:"title"`
+	Desc      string      `json:"desc"`
+	Created   int64       `json:"created"`
+	Duration  int         `json:"duration"` // 全部分P的总时长（秒）
+	Pic       string      `json:"pic"`
+	Owner     Owner       `json:"owner"`
+	Stat      Stat        `json:"stat"`
+	Copyright int         `json:"copyright"`
+	Videos    int         `json:"videos"` // 分P数量
+	Pages     []VideoPage `json:"pages"`
+}
+
+// VideoPage 视频分P信息
+type VideoPage struct {
+	CID       int64  `json:"cid"`
+	Page      int    `json:"page"`     // 分P序号，从1开始
+	Part      string `json:"part"`     // 分P标题
+	Duration  int    `json:"duration"` // 分P时长（秒）
+	Dimension struct {
+		Width  int `json:"width"`
+		Height int `json:"height"`
+		Rotate int `json:"rotate"`
+	} `json:"dimension"`
+}
+
+// PageList 获取视频的分P列表
+// 接口未返回 pages 时，用视频本身的 cid 和时长构造唯一的分P
+func (v *VideoInfo) PageList() []VideoPage {
+	if len(v.Pages) > 0 {
+		return v.Pages
+	}
+	return []VideoPage{{CID: v.CID, Page: 1, Part: v.Title, Duration: v.Duration}}
 }
 
 // Owner 视频所有者信息
@@ -149,3 +279,16 @@ type VideoResponse struct {
 	Message string    `json:"message"`
 	Data    VideoInfo `json:"data"`
 }
+
+// VideoTag 视频标签
+type VideoTag struct {
+	TagID   int64  `json:"tag_id"`
+	TagName string `json:"tag_name"`
+}
+
+// VideoTagsResponse 视频标签响应
+type VideoTagsResponse struct {
+	Code    int        `json:"code"`
+	Message string     `json:"message"`
+	Data    []VideoTag `json:"data"`
+}
@@ -0,0 +1,67 @@
+package bilibili
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// protobuf 线格式类型（只解析接口用到的部分）
+const (
+	protoWireVarint  = 0
+	protoWireFixed64 = 1
+	protoWireBytes   = 2
+	protoWireFixed32 = 5
+)
+
+var errProtoTruncated = errors.New("protobuf data truncated")
+
+// walkProtoFields 依次遍历消息中的字段
+// varint 类型的值通过 varint 参数传入，length-delimited 类型的内容通过 raw 参数传入，其余类型跳过
+func walkProtoFields(data []byte, fn func(field int, wireType int, varint uint64, raw []byte) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errProtoTruncated
+		}
+		data = data[n:]
+
+		field := int(key >> 3)
+		wireType := int(key & 7)
+
+		var varint uint64
+		var raw []byte
+		switch wireType {
+		case protoWireVarint:
+			varint, n = binary.Uvarint(data)
+			if n <= 0 {
+				return errProtoTruncated
+			}
+			data = data[n:]
+		case protoWireFixed64:
+			if len(data) < 8 {
+				return errProtoTruncated
+			}
+			data = data[8:]
+		case protoWireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return errProtoTruncated
+			}
+			raw = data[n : n+int(length)]
+			data = data[n+int(length):]
+		case protoWireFixed32:
+			if len(data) < 4 {
+				return errProtoTruncated
+			}
+			data = data[4:]
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", wireType)
+		}
+
+		if err := fn(field, wireType, varint, raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
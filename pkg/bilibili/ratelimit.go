@@ -0,0 +1,67 @@
+package bilibili
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter 令牌桶限流器
+// 同一个客户端的所有请求共享一个限流器，多个任务并发时总请求速率仍受控
+type RateLimiter struct {
+	mu       sync.Mutex
+	rate     float64 // 每秒生成的令牌数
+	burst    float64 // 桶容量
+	tokens   float64
+	lastFill time.Time
+}
+
+// NewRateLimiter 创建令牌桶限流器
+// rate: 每秒允许的请求数，<=0 表示不限流
+// burst: 允许的突发请求数
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:     rate,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		lastFill: time.Now(),
+	}
+}
+
+// Wait 阻塞直到获得一个令牌或 context 被取消
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil || l.rate <= 0 {
+		return nil
+	}
+
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens += now.Sub(l.lastFill).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.lastFill = now
+
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+
+		// 计算距离下一个令牌的等待时间
+		wait := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
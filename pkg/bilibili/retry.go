@@ -0,0 +1,143 @@
+package bilibili
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// RetryPolicy 请求重试策略（指数退避 + 随机抖动）
+type RetryPolicy struct {
+	MaxRetries int           // 最大重试次数，0 表示不重试
+	BaseDelay  time.Duration // 首次重试的基础延迟
+	MaxDelay   time.Duration // 单次重试的最大延迟
+}
+
+// DefaultRetryPolicy 默认重试策略
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   10 * time.Second,
+}
+
+// riskControlCodes 需要退避重试的B站风控错误码
+var riskControlCodes = map[int]bool{
+	-412: true, // 请求被拦截
+	-509: true, // 请求过于频繁
+	-799: true, // 请求过于频繁，请稍后再试
+}
+
+// backoff 计算第 attempt 次重试（从0开始）的等待时间
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 0; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	// 一半固定延迟 + 一半随机抖动，避免多个任务同时重试
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// isRetryableStatus 判断HTTP状态码是否需要重试
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusPreconditionFailed ||
+		statusCode == http.StatusTooManyRequests ||
+		statusCode >= 500
+}
+
+// isRiskControlResponse 判断响应体是否为风控错误码
+func isRiskControlResponse(body []byte) bool {
+	var resp struct {
+		Code int `json:"code"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return false
+	}
+	return riskControlCodes[resp.Code]
+}
+
+// retryAfter 解析 Retry-After 响应头（仅支持秒数格式）
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// RequestStats 请求统计（并发安全）
+type RequestStats struct {
+	requests int64
+	retries  int64
+	failures int64
+}
+
+// Requests 实际发出的请求次数（含重试）
+func (s *RequestStats) Requests() int64 {
+	if s == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&s.requests)
+}
+
+// Retries 重试次数
+func (s *RequestStats) Retries() int64 {
+	if s == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&s.retries)
+}
+
+// Failures 重试耗尽后仍失败的次数
+func (s *RequestStats) Failures() int64 {
+	if s == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&s.failures)
+}
+
+func (s *RequestStats) addRequest() {
+	if s != nil {
+		atomic.AddInt64(&s.requests, 1)
+	}
+}
+
+func (s *RequestStats) addRetry() {
+	if s != nil {
+		atomic.AddInt64(&s.retries, 1)
+	}
+}
+
+func (s *RequestStats) addFailure() {
+	if s != nil {
+		atomic.AddInt64(&s.failures, 1)
+	}
+}
+
+// requestStatsKey context 中保存请求统计的键
+type requestStatsKey struct{}
+
+// ContextWithStats 返回携带请求统计的 context，该 context 下的所有请求都会计入 stats
+func ContextWithStats(ctx context.Context, stats *RequestStats) context.Context {
+	return context.WithValue(ctx, requestStatsKey{}, stats)
+}
+
+// statsFromContext 从 context 中取出请求统计
+func statsFromContext(ctx context.Context) *RequestStats {
+	stats, _ := ctx.Value(requestStatsKey{}).(*RequestStats)
+	return stats
+}
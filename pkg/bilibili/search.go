@@ -0,0 +1,129 @@
+package bilibili
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+// 视频搜索排序方式
+const (
+	SearchOrderRelevance = "totalrank" // 综合排序
+	SearchOrderClick     = "click"     // 最多播放
+	SearchOrderPubdate   = "pubdate"   // 最新发布
+	SearchOrderDanmaku   = "dm"        // 最多弹幕
+	SearchOrderFavorite  = "stow"      // 最多收藏
+)
+
+const (
+	// SearchPageSize 搜索接口每页结果数
+	SearchPageSize = 20
+	// SearchMaxPages 搜索接口最多可翻的页数
+	SearchMaxPages = 50
+)
+
+// SearchOptions 视频搜索选项
+type SearchOptions struct {
+	order    string
+	pubBegin time.Time
+	pubEnd   time.Time
+}
+
+// SearchOption 视频搜索选项函数
+type SearchOption func(*SearchOptions)
+
+// WithSearchOrder 搜索结果排序方式（SearchOrderRelevance 等）
+func WithSearchOrder(order string) SearchOption {
+	return func(opts *SearchOptions) {
+		opts.order = order
+	}
+}
+
+// WithSearchPubTime 只搜索指定时间范围内发布的视频（零值表示不限制）
+func WithSearchPubTime(begin, end time.Time) SearchOption {
+	return func(opts *SearchOptions) {
+		opts.pubBegin = begin
+		opts.pubEnd = end
+	}
+}
+
+// SearchVideoResponse 视频搜索响应
+type SearchVideoResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Page       int           `json:"page"`
+		PageSize   int           `json:"pagesize"`
+		NumResults int           `json:"numResults"` // 结果总数
+		NumPages   int           `json:"numPages"`   // 总页数
+		Result     []SearchVideo `json:"result"`
+	} `json:"data"`
+}
+
+// SearchVideo 视频搜索结果
+type SearchVideo struct {
+	AID         int64  `json:"aid"`
+	BVID        string `json:"bvid"`
+	Title       string `json:"title"` // 含 <em class="keyword"> 高亮标签，展示时使用 PlainTitle
+	Description string `json:"description"`
+	Author      string `json:"author"`
+	Mid         int64  `json:"mid"`
+	Play        int    `json:"play"`
+	Review      int    `json:"review"` // 评论数
+	PubDate     int64  `json:"pubdate"`
+	Duration    string `json:"duration"` // 时长（m:ss）
+}
+
+// highlightPattern 搜索结果中的高亮标签
+var highlightPattern = regexp.MustCompile(`</?em[^>]*>`)
+
+// PlainTitle 去除高亮标签和HTML转义后的标题
+func (v SearchVideo) PlainTitle() string {
+	return html.UnescapeString(highlightPattern.ReplaceAllString(v.Title, ""))
+}
+
+// SearchVideos 按关键词搜索视频
+func SearchVideos(keyword string, page int, opts ...SearchOption) (*SearchVideoResponse, error) {
+	return defaultClient.SearchVideos(context.Background(), keyword, page, opts...)
+}
+
+// SearchVideos 按关键词搜索视频，page 从1开始，每页 SearchPageSize 条，最多 SearchMaxPages 页
+func (c *BilibiliClient) SearchVideos(ctx context.Context, keyword string, page int, opts ...SearchOption) (*SearchVideoResponse, error) {
+	options := &SearchOptions{order: SearchOrderRelevance}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	params := url.Values{}
+	params.Add("search_type", "video")
+	params.Add("keyword", keyword)
+	params.Add("page", fmt.Sprintf("%d", page))
+	params.Add("order", options.order)
+	if !options.pubBegin.IsZero() {
+		params.Add("pubtime_begin_s", fmt.Sprintf("%d", options.pubBegin.Unix()))
+	}
+	if !options.pubEnd.IsZero() {
+		params.Add("pubtime_end_s", fmt.Sprintf("%d", options.pubEnd.Unix()))
+	}
+
+	// 使用WBI签名请求（签名失效时自动刷新密钥重试）
+	body, err := c.signedGet(ctx, "/x/web-interface/wbi/search/type", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var searchResp SearchVideoResponse
+	if err := json.Unmarshal(body, &searchResp); err != nil {
+		return nil, fmt.Errorf("解析JSON失败: %v", err)
+	}
+
+	if searchResp.Code != 0 {
+		return nil, fmt.Errorf("API返回错误，错误码: %d, 错误信息: %s", searchResp.Code, searchResp.Message)
+	}
+
+	return &searchResp, nil
+}
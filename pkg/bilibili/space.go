@@ -0,0 +1,72 @@
+package bilibili
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// UserVideoMaxPageSize UP主投稿列表接口每页最大数量
+const UserVideoMaxPageSize = 50
+
+// UserVideosResponse UP主投稿视频列表响应
+type UserVideosResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		List struct {
+			Vlist []UserVideo `json:"vlist"`
+		} `json:"list"`
+		Page struct {
+			PN    int `json:"pn"`
+			PS    int `json:"ps"`
+			Count int `json:"count"` // 投稿总数
+		} `json:"page"`
+	} `json:"data"`
+}
+
+// UserVideo UP主投稿视频
+type UserVideo struct {
+	AID         int64  `json:"aid"`
+	BVID        string `json:"bvid"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Author      string `json:"author"`
+	Mid         int64  `json:"mid"`
+	Created     int64  `json:"created"` // 发布时间（Unix秒）
+	Length      string `json:"length"`  // 时长（mm:ss）
+	Comment     int    `json:"comment"` // 评论数
+}
+
+// GetUserVideos 获取UP主的投稿视频列表（按发布时间从新到旧）
+func GetUserVideos(mid int64, pn int, ps int) (*UserVideosResponse, error) {
+	return defaultClient.GetUserVideos(context.Background(), mid, pn, ps)
+}
+
+// GetUserVideos 获取UP主的投稿视频列表（按发布时间从新到旧）
+// pn 从1开始，ps 不超过 UserVideoMaxPageSize
+func (c *BilibiliClient) GetUserVideos(ctx context.Context, mid int64, pn int, ps int, commentOptions ...CommentOption) (*UserVideosResponse, error) {
+	params := url.Values{}
+	params.Add("mid", fmt.Sprintf("%d", mid))
+	params.Add("pn", fmt.Sprintf("%d", pn))
+	params.Add("ps", fmt.Sprintf("%d", ps))
+	params.Add("order", "pubdate")
+
+	// 使用WBI签名请求（签名失效时自动刷新密钥重试）
+	body, err := c.signedGet(ctx, "/x/space/wbi/arc/search", params, newCommentOptions(commentOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	var videosResp UserVideosResponse
+	if err := json.Unmarshal(body, &videosResp); err != nil {
+		return nil, fmt.Errorf("解析JSON失败: %v", err)
+	}
+
+	if videosResp.Code != 0 {
+		return nil, fmt.Errorf("API返回错误，错误码: %d, 错误信息: %s", videosResp.Code, videosResp.Message)
+	}
+
+	return &videosResp, nil
+}
@@ -0,0 +1,104 @@
+package bilibili
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// PlayerInfoResponse 播放器信息响应（只解析字幕部分）
+type PlayerInfoResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		AID      int64 `json:"aid"`
+		CID      int64 `json:"cid"`
+		Subtitle struct {
+			Subtitles []SubtitleInfo `json:"subtitles"`
+		} `json:"subtitle"`
+	} `json:"data"`
+}
+
+// SubtitleInfo 字幕信息
+type SubtitleInfo struct {
+	ID          int64  `json:"id"`
+	Lan         string `json:"lan"`          // 语言代码，如 zh-CN、ai-zh
+	LanDoc      string `json:"lan_doc"`      // 语言名称，如"中文（中国）"
+	SubtitleURL string `json:"subtitle_url"` // 字幕文件地址（可能省略协议）
+	Type        int    `json:"type"`         // 0 CC字幕, 1 AI字幕
+	AIType      int    `json:"ai_type"`
+	AIStatus    int    `json:"ai_status"`
+}
+
+// IsAI 是否为AI生成的字幕
+func (s SubtitleInfo) IsAI() bool {
+	return s.Type == 1 || strings.HasPrefix(s.Lan, "ai-")
+}
+
+// SubtitleBody 字幕文件内容
+type SubtitleBody struct {
+	Body []SubtitleLine `json:"body"`
+}
+
+// SubtitleLine 一句字幕
+type SubtitleLine struct {
+	From    float64 `json:"from"` // 开始时间（秒）
+	To      float64 `json:"to"`   // 结束时间（秒）
+	Content string  `json:"content"`
+}
+
+// GetPlayerInfo 获取视频分P的播放器信息
+func GetPlayerInfo(aid, cid int64, commentOptions ...CommentOption) (*PlayerInfoResponse, error) {
+	return defaultClient.GetPlayerInfo(context.Background(), aid, cid, commentOptions...)
+}
+
+// GetSubtitle 下载字幕文件
+func GetSubtitle(subtitleURL string) ([]SubtitleLine, error) {
+	return defaultClient.GetSubtitle(context.Background(), subtitleURL)
+}
+
+// GetPlayerInfo 获取视频分P的播放器信息，包含该分P的CC字幕和AI字幕列表
+// AI字幕通常需要登录才会返回，可通过 WithCookie 传入认证信息
+func (c *BilibiliClient) GetPlayerInfo(ctx context.Context, aid, cid int64, commentOptions ...CommentOption) (*PlayerInfoResponse, error) {
+	params := url.Values{}
+	params.Add("aid", fmt.Sprintf("%d", aid))
+	params.Add("cid", fmt.Sprintf("%d", cid))
+
+	// 使用WBI签名请求（签名失效时自动刷新密钥重试）
+	body, err := c.signedGet(ctx, "/x/player/wbi/v2", params, newCommentOptions(commentOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	var playerResp PlayerInfoResponse
+	if err := json.Unmarshal(body, &playerResp); err != nil {
+		return nil, fmt.Errorf("解析JSON失败: %v", err)
+	}
+
+	if playerResp.Code != 0 {
+		return nil, fmt.Errorf("API返回错误，错误码: %d, 错误信息: %s", playerResp.Code, playerResp.Message)
+	}
+
+	return &playerResp, nil
+}
+
+// GetSubtitle 下载字幕文件（SubtitleInfo.SubtitleURL）
+func (c *BilibiliClient) GetSubtitle(ctx context.Context, subtitleURL string) ([]SubtitleLine, error) {
+	if strings.HasPrefix(subtitleURL, "//") {
+		subtitleURL = "https:" + subtitleURL
+	}
+
+	body, err := c.doGet(ctx, subtitleURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var subtitle SubtitleBody
+	if err := json.Unmarshal(body, &subtitle); err != nil {
+		return nil, fmt.Errorf("解析JSON失败: %v", err)
+	}
+
+	return subtitle.Body, nil
+}
@@ -1,26 +1,34 @@
 package bilibili
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 )
 
+// ErrUserNotFound 用户不存在（接口返回 -404）
+var ErrUserNotFound = errors.New("用户不存在")
+
 // GetUser 获取用户信息
 func GetUser(mid int64) (*UserResponse, error) {
-	// 构造API URL
-	apiURL := "https://api.bilibili.com/x/space/acc/info"
+	return defaultClient.GetUser(context.Background(), mid)
+}
 
+// GetRelationStat 获取用户的关注数和粉丝数
+func GetRelationStat(mid int64) (*RelationStatResponse, error) {
+	return defaultClient.GetRelationStat(context.Background(), mid)
+}
+
+// GetUser 获取用户信息
+func (c *BilibiliClient) GetUser(ctx context.Context, mid int64) (*UserResponse, error) {
 	// 构造查询参数
 	params := url.Values{}
 	params.Add("mid", fmt.Sprintf("%d", mid))
 
-	// 完整URL
-	fullURL := apiURL + "?" + params.Encode()
-
-	// 使用公共客户端发送请求
-	client := NewBilibiliClient()
-	body, err := client.SendRequest(fullURL)
+	// 使用WBI签名请求（旧的 /x/space/acc/info 已停用）
+	body, err := c.signedGet(ctx, "/x/space/wbi/acc/info", params, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -32,9 +40,34 @@ func GetUser(mid int64) (*UserResponse, error) {
 	}
 
 	// 检查API是否返回错误
+	if userResp.Code == -404 {
+		return nil, fmt.Errorf("%w: %d", ErrUserNotFound, mid)
+	}
 	if userResp.Code != 0 {
 		return nil, fmt.Errorf("API返回错误，错误码: %d, 错误信息: %s", userResp.Code, userResp.Message)
 	}
 
 	return &userResp, nil
 }
+
+// GetRelationStat 获取用户的关注数和粉丝数
+func (c *BilibiliClient) GetRelationStat(ctx context.Context, mid int64) (*RelationStatResponse, error) {
+	params := url.Values{}
+	params.Add("vmid", fmt.Sprintf("%d", mid))
+
+	body, err := c.get(ctx, "/x/relation/stat", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var statResp RelationStatResponse
+	if err := json.Unmarshal(body, &statResp); err != nil {
+		return nil, fmt.Errorf("解析JSON失败: %v", err)
+	}
+
+	if statResp.Code != 0 {
+		return nil, fmt.Errorf("API返回错误，错误码: %d, 错误信息: %s", statResp.Code, statResp.Message)
+	}
+
+	return &statResp, nil
+}
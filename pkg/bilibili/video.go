@@ -1,26 +1,44 @@
 package bilibili
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
 )
 
 // GetVideoByBVID 通过BVID获取视频信息
 func GetVideoByBVID(bvid string) (*VideoResponse, error) {
-	// 构造API URL
-	apiURL := "https://api.bilibili.com/x/web-interface/view"
+	return defaultClient.GetVideoByBVID(context.Background(), bvid)
+}
+
+// GetVideoByAID 通过AID获取视频信息
+func GetVideoByAID(aid int64) (*VideoResponse, error) {
+	return defaultClient.GetVideoByAID(context.Background(), aid)
+}
 
+// GetVideoByBVID 通过BVID获取视频信息
+func (c *BilibiliClient) GetVideoByBVID(ctx context.Context, bvid string) (*VideoResponse, error) {
 	// 构造查询参数
 	params := url.Values{}
 	params.Add("bvid", bvid)
 
-	// 完整URL
-	fullURL := apiURL + "?" + params.Encode()
+	return c.getVideo(ctx, params)
+}
+
+// GetVideoByAID 通过AID获取视频信息
+func (c *BilibiliClient) GetVideoByAID(ctx context.Context, aid int64) (*VideoResponse, error) {
+	// 构造查询参数
+	params := url.Values{}
+	params.Add("aid", fmt.Sprintf("%d", aid))
 
-	// 使用公共客户端发送请求
-	client := NewBilibiliClient()
-	body, err := client.SendRequest(fullURL)
+	return c.getVideo(ctx, params)
+}
+
+// getVideo 请求视频详情接口
+func (c *BilibiliClient) getVideo(ctx context.Context, params url.Values) (*VideoResponse, error) {
+	body, err := c.get(ctx, "/x/web-interface/view", params, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -39,35 +57,71 @@ func GetVideoByBVID(bvid string) (*VideoResponse, error) {
 	return &videoResp, nil
 }
 
-// GetVideoByAID 通过AID获取视频信息
-func GetVideoByAID(aid int64) (*VideoResponse, error) {
-	// 构造API URL
-	apiURL := "https://api.bilibili.com/x/web-interface/view"
+// GetVideoTags 获取视频标签
+func GetVideoTags(bvid string) (*VideoTagsResponse, error) {
+	return defaultClient.GetVideoTags(context.Background(), bvid)
+}
 
-	// 构造查询参数
+// GetVideoTags 获取视频标签
+func (c *BilibiliClient) GetVideoTags(ctx context.Context, bvid string) (*VideoTagsResponse, error) {
 	params := url.Values{}
-	params.Add("aid", fmt.Sprintf("%d", aid))
-
-	// 完整URL
-	fullURL := apiURL + "?" + params.Encode()
+	params.Add("bvid", bvid)
 
-	// 使用公共客户端发送请求
-	client := NewBilibiliClient()
-	body, err := client.SendRequest(fullURL)
+	body, err := c.get(ctx, "/x/tag/archive/tags", params, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	// 解析JSON
-	var videoResp VideoResponse
-	if err := json.Unmarshal(body, &videoResp); err != nil {
+	var tagsResp VideoTagsResponse
+	if err := json.Unmarshal(body, &tagsResp); err != nil {
 		return nil, fmt.Errorf("解析JSON失败: %v", err)
 	}
 
-	// 检查API是否返回错误
-	if videoResp.Code != 0 {
-		return nil, fmt.Errorf("API返回错误，错误码: %d, 错误信息: %s", videoResp.Code, videoResp.Message)
+	if tagsResp.Code != 0 {
+		return nil, fmt.Errorf("API返回错误，错误码: %d, 错误信息: %s", tagsResp.Code, tagsResp.Message)
 	}
 
-	return &videoResp, nil
+	return &tagsResp, nil
+}
+
+// ResolveShortLink 解析短链接（如 https://b23.tv/xxxx），返回跳转后的完整地址
+func ResolveShortLink(shortURL string) (string, error) {
+	return defaultClient.ResolveShortLink(context.Background(), shortURL)
+}
+
+// ResolveShortLink 解析短链接（如 https://b23.tv/xxxx），返回跳转后的完整地址
+// 只读取第一次跳转的 Location，不请求目标页面
+func (c *BilibiliClient) ResolveShortLink(ctx context.Context, shortURL string) (string, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", shortURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
+
+	// 复用客户端的 Transport，但不自动跟随跳转
+	noRedirect := *c.client
+	noRedirect.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	resp, err := noRedirect.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("发送请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", fmt.Errorf("短链接未跳转，HTTP状态码: %d", resp.StatusCode)
+	}
+
+	target, err := resp.Request.URL.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("解析跳转地址失败: %v", err)
+	}
+	return target.String(), nil
 }
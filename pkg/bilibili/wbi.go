@@ -1,11 +1,11 @@
 package bilibili
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
-	"io"
-	"net/http"
+	"fmt"
 	"net/url"
 	"sort"
 	"strconv"
@@ -25,11 +25,17 @@ var defaultWBIKey = WBIKey{
 	SubKey: "44aa19dd532868a0e7278589417478a8", // 默认子密钥
 }
 
-// NavResponse 用于解析获取WBI密钥的API响应
+// NavResponse 导航栏接口响应（登录状态和WBI密钥）
 type NavResponse struct {
-	Code int `json:"code"`
-	Data struct {
-		WbiImg struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		IsLogin   bool   `json:"isLogin"`
+		Mid       int64  `json:"mid"`
+		Uname     string `json:"uname"`
+		Face      string `json:"face"`
+		VipStatus int    `json:"vipStatus"`
+		WbiImg    struct {
 			ImgUrl string `json:"img_url"`
 			SubUrl string `json:"sub_url"`
 		} `json:"wbi_img"`
@@ -38,51 +44,72 @@ type NavResponse struct {
 
 // GetWBIKey 获取WBI密钥
 func GetWBIKey() WBIKey {
-	// 创建HTTP客户端
-	client := &http.Client{Timeout: 10 * time.Second}
-
-	// 创建请求
-	req, err := http.NewRequest("GET", "https://api.bilibili.com/x/web-interface/nav", nil)
-	if err != nil {
-		// 如果获取失败，使用默认密钥
-		return defaultWBIKey
-	}
+	return defaultClient.GetWBIKey(context.Background())
+}
 
-	// 设置请求头
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
-	req.Header.Set("Referer", "https://www.bilibili.com/")
+// GetWBIKey 获取WBI密钥（优先使用缓存，获取失败时返回旧密钥或默认密钥）
+func (c *BilibiliClient) GetWBIKey(ctx context.Context) WBIKey {
+	return c.wbiKeys.Get(ctx)
+}
 
-	// 发送请求
-	resp, err := client.Do(req)
-	if err != nil {
-		// 如果请求失败，使用默认密钥
-		return defaultWBIKey
-	}
-	defer resp.Body.Close()
+// InvalidateWBIKey 使缓存的WBI密钥失效，下次签名时重新获取
+func (c *BilibiliClient) InvalidateWBIKey() {
+	c.wbiKeys.Invalidate()
+}
 
-	// 读取响应
-	body, err := io.ReadAll(resp.Body)
+// FetchWBIKey 从 nav 接口获取最新的WBI密钥（不使用缓存）
+func (c *BilibiliClient) FetchWBIKey(ctx context.Context) (WBIKey, error) {
+	body, err := c.get(ctx, "/x/web-interface/nav", nil, nil)
 	if err != nil {
-		// 如果读取失败，使用默认密钥
-		return defaultWBIKey
+		return WBIKey{}, err
 	}
 
 	// 解析JSON
 	var navResp NavResponse
+	if err := json.Unmarshal(body, &navResp); err != nil {
+		return WBIKey{}, fmt.Errorf("解析JSON失败: %v", err)
+	}
 
-	if err := json.Unmarshal(body, &navResp); err != nil || navResp.Code != 0 {
-		// 如果解析失败或返回错误码，使用默认密钥
-		return defaultWBIKey
+	// 未登录时 nav 接口返回 -101，但仍然携带 wbi_img，只要密钥地址存在即可使用
+	imgURL := navResp.Data.WbiImg.ImgUrl
+	subURL := navResp.Data.WbiImg.SubUrl
+	if imgURL == "" || subURL == "" {
+		return WBIKey{}, fmt.Errorf("nav接口未返回WBI密钥，错误码: %d", navResp.Code)
 	}
 
 	// 提取密钥
-	imgKey := extractKeyFromURL(navResp.Data.WbiImg.ImgUrl)
-	subKey := extractKeyFromURL(navResp.Data.WbiImg.SubUrl)
-
 	return WBIKey{
-		ImgKey: imgKey,
-		SubKey: subKey,
+		ImgKey: extractKeyFromURL(imgURL),
+		SubKey: extractKeyFromURL(subURL),
+	}, nil
+}
+
+// signedGet 使用WBI签名请求接口
+// 如果接口因签名失效返回 -352/-403，刷新密钥后重新签名并重试一次
+func (c *BilibiliClient) signedGet(ctx context.Context, path string, params url.Values, opts *CommentOptions) ([]byte, error) {
+	body, err := c.get(ctx, path, SignParams(params, c.GetWBIKey(ctx)), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isSignatureRejected(body) {
+		return body, nil
 	}
+
+	// 签名被拒绝，强制刷新密钥后重试
+	c.InvalidateWBIKey()
+	return c.get(ctx, path, SignParams(params, c.GetWBIKey(ctx)), opts)
+}
+
+// isSignatureRejected 判断响应是否为签名校验失败
+func isSignatureRejected(body []byte) bool {
+	var resp struct {
+		Code int `json:"code"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return false
+	}
+	return resp.Code == -352 || resp.Code == -403
 }
 
 // 从URL提取密钥
@@ -112,17 +139,29 @@ func getMixinKey(orig string) string {
 	return str.String()[:32]
 }
 
+// wbiFilteredChars 签名前需要从参数值中去除的字符
+var wbiFilteredChars = strings.NewReplacer("!", "", "'", "", "(", "", ")", "", "*", "")
+
 // SignParams 对参数进行WBI签名
+// 参数值中的 !'()* 会被去除（实际发送的参数同样不含这些字符），签名按 encodeURIComponent 规则转义后计算
 func SignParams(params url.Values, wbiKey WBIKey) url.Values {
-	// 复制参数，避免修改原始参数
+	return signParamsAt(params, wbiKey, time.Now())
+}
+
+// signParamsAt 使用指定时间戳对参数进行WBI签名
+func signParamsAt(params url.Values, wbiKey WBIKey, now time.Time) url.Values {
+	// 复制参数并过滤特殊字符，避免修改原始参数
 	signedParams := url.Values{}
-	for k, v := range params {
-		signedParams[k] = v
+	for k, values := range params {
+		filtered := make([]string, len(values))
+		for i, v := range values {
+			filtered[i] = wbiFilteredChars.Replace(v)
+		}
+		signedParams[k] = filtered
 	}
 
 	// 添加wts参数（当前时间戳）
-	wts := strconv.FormatInt(time.Now().Unix(), 10)
-	signedParams.Set("wts", wts)
+	signedParams.Set("wts", strconv.FormatInt(now.Unix(), 10))
 
 	// 对参数按键名排序
 	keys := make([]string, 0, len(signedParams))
@@ -131,24 +170,15 @@ func SignParams(params url.Values, wbiKey WBIKey) url.Values {
 	}
 	sort.Strings(keys)
 
-	// 构造查询字符串，并过滤特殊字符
+	// 构造查询字符串（与浏览器端一致，使用 encodeURIComponent 的转义规则）
 	var query strings.Builder
 	for _, k := range keys {
 		if query.Len() > 0 {
 			query.WriteByte('&')
 		}
-
-		// 过滤掉特殊字符 !'()*
-		value := signedParams.Get(k)
-		value = strings.ReplaceAll(value, "!", "")
-		value = strings.ReplaceAll(value, "'", "")
-		value = strings.ReplaceAll(value, "(", "")
-		value = strings.ReplaceAll(value, ")", "")
-		value = strings.ReplaceAll(value, "*", "")
-
-		query.WriteString(k)
+		query.WriteString(encodeURIComponent(k))
 		query.WriteByte('=')
-		query.WriteString(value)
+		query.WriteString(encodeURIComponent(signedParams.Get(k)))
 	}
 
 	// 生成混合密钥
@@ -163,3 +193,9 @@ func SignParams(params url.Values, wbiKey WBIKey) url.Values {
 
 	return signedParams
 }
+
+// encodeURIComponent 按 JavaScript encodeURIComponent 的规则转义（空格转为 %20 而不是 +）
+// 调用方需先去除 !'()*，这些字符在 encodeURIComponent 中不转义
+func encodeURIComponent(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,152 @@
+package bilibili
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"bilibili/pkg/utils"
+)
+
+const (
+	// DefaultWBIKeyTTL WBI密钥缓存默认有效期
+	DefaultWBIKeyTTL = time.Hour
+	// DefaultWBIKeyRetryDelay 刷新失败后的首次退避时间，之后每次连续失败翻倍
+	DefaultWBIKeyRetryDelay = 30 * time.Second
+	// maxWBIKeyRetryDelay 刷新失败后的退避时间上限
+	maxWBIKeyRetryDelay = 10 * time.Minute
+)
+
+// WBIKeyManager WBI密钥缓存管理器
+// 在有效期内复用密钥；多个请求同时刷新时只发起一次 nav 请求；
+// 刷新失败后在退避时间内不再请求 nav 接口，继续使用旧密钥
+type WBIKeyManager struct {
+	mu         sync.Mutex
+	key        WBIKey
+	fetchedAt  time.Time
+	ttl        time.Duration
+	retryDelay time.Duration
+	failures   int       // 连续刷新失败次数
+	retryAt    time.Time // 退避结束时间，之前不再刷新
+	fetch      func(ctx context.Context) (WBIKey, error)
+	inflight   *wbiFetchCall
+}
+
+// wbiFetchCall 正在进行中的密钥刷新
+type wbiFetchCall struct {
+	done chan struct{}
+	key  WBIKey
+	err  error
+}
+
+// NewWBIKeyManager 创建WBI密钥缓存管理器
+func NewWBIKeyManager(ttl time.Duration, fetch func(ctx context.Context) (WBIKey, error)) *WBIKeyManager {
+	if ttl <= 0 {
+		ttl = DefaultWBIKeyTTL
+	}
+	return &WBIKeyManager{
+		ttl:        ttl,
+		retryDelay: DefaultWBIKeyRetryDelay,
+		fetch:      fetch,
+	}
+}
+
+// Get 获取WBI密钥
+// 缓存有效时直接返回；过期时刷新，刷新失败则返回旧密钥，从未获取成功时返回默认密钥
+// 刷新失败后的退避时间内直接返回旧密钥（或默认密钥），避免每个请求都重新请求 nav 接口
+func (m *WBIKeyManager) Get(ctx context.Context) WBIKey {
+	m.mu.Lock()
+	if !m.fetchedAt.IsZero() && time.Since(m.fetchedAt) < m.ttl {
+		key := m.key
+		m.mu.Unlock()
+		return key
+	}
+	if time.Now().Before(m.retryAt) {
+		key := m.fallbackLocked()
+		m.mu.Unlock()
+		return key
+	}
+
+	// 已有刷新在进行中，等待其结果
+	call := m.inflight
+	if call == nil {
+		call = &wbiFetchCall{done: make(chan struct{})}
+		m.inflight = call
+		m.mu.Unlock()
+		go m.refresh(call)
+	} else {
+		m.mu.Unlock()
+	}
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return m.fallback()
+	}
+
+	if call.err != nil {
+		utils.LogWarn("获取WBI密钥失败，使用降级密钥: " + call.err.Error())
+		return m.fallback()
+	}
+	return call.key
+}
+
+// Invalidate 使缓存失效，处于刷新失败的退避时间内时仍等待退避结束再刷新
+func (m *WBIKeyManager) Invalidate() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fetchedAt = time.Time{}
+}
+
+// refresh 执行一次密钥刷新并通知所有等待者
+// 刷新不跟随单个请求的 context，避免某个调用方取消导致其他等待者一起失败
+func (m *WBIKeyManager) refresh(call *wbiFetchCall) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	key, err := m.fetch(ctx)
+
+	m.mu.Lock()
+	if err == nil {
+		m.key = key
+		m.fetchedAt = time.Now()
+		m.failures = 0
+		m.retryAt = time.Time{}
+	} else {
+		m.failures++
+		m.retryAt = time.Now().Add(m.backoffLocked())
+	}
+	call.key = key
+	call.err = err
+	m.inflight = nil
+	m.mu.Unlock()
+
+	close(call.done)
+}
+
+// backoffLocked 根据连续失败次数计算退避时间（调用方需持有锁）
+func (m *WBIKeyManager) backoffLocked() time.Duration {
+	delay := m.retryDelay
+	for i := 1; i < m.failures && delay < maxWBIKeyRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxWBIKeyRetryDelay {
+		delay = maxWBIKeyRetryDelay
+	}
+	return delay
+}
+
+// fallback 返回旧密钥，从未获取成功时返回默认密钥
+func (m *WBIKeyManager) fallback() WBIKey {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.fallbackLocked()
+}
+
+// fallbackLocked 同 fallback（调用方需持有锁）
+func (m *WBIKeyManager) fallbackLocked() WBIKey {
+	if m.key.ImgKey != "" && m.key.SubKey != "" {
+		return m.key
+	}
+	return defaultWBIKey
+}
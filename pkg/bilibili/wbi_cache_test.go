@@ -0,0 +1,86 @@
+package bilibili
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWBIKeyManagerBacksOffAfterFailure(t *testing.T) {
+	goodKey := WBIKey{ImgKey: "img", SubKey: "sub"}
+	fetches := 0
+	fail := false
+	m := NewWBIKeyManager(time.Millisecond, func(ctx context.Context) (WBIKey, error) {
+		fetches++
+		if fail {
+			return WBIKey{}, errors.New("nav unavailable")
+		}
+		return goodKey, nil
+	})
+	m.retryDelay = 50 * time.Millisecond
+	ctx := context.Background()
+
+	if got := m.Get(ctx); got != goodKey {
+		t.Fatalf("Get() = %+v, want %+v", got, goodKey)
+	}
+
+	// 密钥过期后刷新失败：继续使用旧密钥，退避期间不再请求 nav 接口
+	time.Sleep(2 * time.Millisecond)
+	fail = true
+	for i := 0; i < 5; i++ {
+		if got := m.Get(ctx); got != goodKey {
+			t.Fatalf("Get() after failure = %+v, want last good key %+v", got, goodKey)
+		}
+	}
+	if fetches != 2 {
+		t.Fatalf("fetches during backoff = %d, want 2", fetches)
+	}
+
+	// 退避结束后重新刷新
+	time.Sleep(60 * time.Millisecond)
+	fail = false
+	if got := m.Get(ctx); got != goodKey {
+		t.Fatalf("Get() after backoff = %+v, want %+v", got, goodKey)
+	}
+	if fetches != 3 {
+		t.Fatalf("fetches after backoff = %d, want 3", fetches)
+	}
+}
+
+func TestWBIKeyManagerFallbackWithoutGoodKey(t *testing.T) {
+	fetches := 0
+	m := NewWBIKeyManager(time.Hour, func(ctx context.Context) (WBIKey, error) {
+		fetches++
+		return WBIKey{}, errors.New("nav unavailable")
+	})
+
+	for i := 0; i < 3; i++ {
+		if got := m.Get(context.Background()); got != defaultWBIKey {
+			t.Fatalf("Get() = %+v, want default key", got)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("fetches = %d, want 1", fetches)
+	}
+}
+
+func TestWBIKeyManagerBackoffGrows(t *testing.T) {
+	m := NewWBIKeyManager(time.Hour, nil)
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, DefaultWBIKeyRetryDelay},
+		{2, 2 * DefaultWBIKeyRetryDelay},
+		{3, 4 * DefaultWBIKeyRetryDelay},
+		{20, maxWBIKeyRetryDelay},
+	}
+	for _, tt := range tests {
+		m.failures = tt.failures
+		if got := m.backoffLocked(); got != tt.want {
+			t.Errorf("backoff after %d failures = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
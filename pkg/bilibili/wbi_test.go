@@ -0,0 +1,64 @@
+package bilibili
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestSignParams(t *testing.T) {
+	key := WBIKey{ImgKey: "7cd084941338484aae1ad9425b84077c", SubKey: "4932caff0ff746eab6f01bf08b70ac45"}
+	wts := time.Unix(1702204169, 0)
+
+	tests := []struct {
+		name        string
+		params      url.Values
+		wantWRID    string
+		wantKeyword string
+	}{
+		{
+			name:     "ascii params",
+			params:   url.Values{"foo": {"114"}, "bar": {"514"}, "zab": {"1919810"}},
+			wantWRID: "8f6f2b5b3d485fe1886cec6a0be8c5d4",
+		},
+		{
+			name: "chinese keyword with space, ampersand and filtered chars",
+			params: url.Values{
+				"search_type": {"video"},
+				"keyword":     {"原神 & 崩坏(星穹铁道)!"},
+				"page":        {"1"},
+				"order":       {"totalrank"},
+			},
+			wantWRID:    "869b7d82cacf80185edaae335475726b",
+			wantKeyword: "原神 & 崩坏星穹铁道",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signed := signParamsAt(tt.params, key, wts)
+			if got := signed.Get("w_rid"); got != tt.wantWRID {
+				t.Errorf("w_rid = %s, want %s", got, tt.wantWRID)
+			}
+			if got := signed.Get("wts"); got != "1702204169" {
+				t.Errorf("wts = %s, want 1702204169", got)
+			}
+			// 实际发送的参数与签名使用的参数一致
+			if tt.wantKeyword != "" && signed.Get("keyword") != tt.wantKeyword {
+				t.Errorf("keyword = %q, want %q", signed.Get("keyword"), tt.wantKeyword)
+			}
+		})
+	}
+}
+
+func TestSignParamsDoesNotModifyInput(t *testing.T) {
+	params := url.Values{"keyword": {"a(b)"}}
+	SignParams(params, defaultWBIKey)
+
+	if got := params.Get("keyword"); got != "a(b)" {
+		t.Errorf("input keyword = %q, want unchanged", got)
+	}
+	if _, ok := params["w_rid"]; ok {
+		t.Error("input params gained w_rid")
+	}
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// AccountStorage 登录账号存储接口
+type AccountStorage interface {
+	// SaveAccounts 保存全部登录账号
+	SaveAccounts(index *AccountIndex) error
+
+	// LoadAccounts 加载全部登录账号
+	LoadAccounts() (*AccountIndex, error)
+
+	// 账号Cookie加密保存
+	CredentialStorage
+}
+
+// SaveAccounts 保存全部登录账号
+func (js *JSONStorage) SaveAccounts(index *AccountIndex) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if index == nil {
+		return fmt.Errorf("账号数据不能为空")
+	}
+
+	index.Version = "1.0"
+	index.LastUpdated = time.Now()
+
+	return js.writeJSONFile(js.getAccountsPath(), index)
+}
+
+// LoadAccounts 加载全部登录账号，文件不存在时返回空列表
+func (js *JSONStorage) LoadAccounts() (*AccountIndex, error) {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	index := &AccountIndex{
+		Version:     "1.0",
+		LastUpdated: time.Now(),
+		Accounts:    []AccountEntry{},
+	}
+
+	if _, err := js.readJSONFile(js.getAccountsPath(), index); err != nil {
+		return nil, err
+	}
+
+	return index, nil
+}
+
+// getAccountsPath 获取登录账号文件路径
+func (js *JSONStorage) getAccountsPath() string {
+	return filepath.Join(js.dataDir, "accounts", "accounts.json")
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// BatchStorage 批量任务存储接口
+type BatchStorage interface {
+	// SaveBatches 保存全部批量任务
+	SaveBatches(index *BatchIndex) error
+
+	// LoadBatches 加载全部批量任务
+	LoadBatches() (*BatchIndex, error)
+
+	// 批量任务的认证信息加密保存
+	CredentialStorage
+}
+
+// SaveBatches 保存全部批量任务
+func (js *JSONStorage) SaveBatches(index *BatchIndex) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if index == nil {
+		return fmt.Errorf("批量任务数据不能为空")
+	}
+
+	index.Version = "1.0"
+	index.LastUpdated = time.Now()
+
+	return js.writeJSONFile(js.getBatchFilePath(), index)
+}
+
+// LoadBatches 加载全部批量任务
+func (js *JSONStorage) LoadBatches() (*BatchIndex, error) {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	index := &BatchIndex{
+		Version:     "1.0",
+		LastUpdated: time.Now(),
+		Batches:     []BatchEntry{},
+	}
+
+	// 文件不存在时返回空列表
+	if _, err := js.readJSONFile(js.getBatchFilePath(), index); err != nil {
+		return nil, err
+	}
+
+	return index, nil
+}
+
+// getBatchFilePath 获取批量任务文件路径
+func (js *JSONStorage) getBatchFilePath() string {
+	return filepath.Join(js.dataDir, "batches.json")
+}
@@ -0,0 +1,463 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrCredentialNotFound 认证信息不存在
+	ErrCredentialNotFound = errors.New("credential not found")
+	// ErrCredentialKeyNotSet 未设置认证信息加密密钥
+	ErrCredentialKeyNotSet = errors.New("credential key not set")
+)
+
+// CredentialStorage 认证信息存储接口
+// Cookie、AppKey/AppSecret 使用 AES-GCM 加密后集中保存，任务等数据文件只保存引用ID
+type CredentialStorage interface {
+	// SaveCredential 加密保存认证信息
+	SaveCredential(id string, cred *CredentialEntry) error
+
+	// LoadCredential 读取并解密认证信息，不存在时返回 ErrCredentialNotFound
+	LoadCredential(id string) (*CredentialEntry, error)
+
+	// DeleteCredential 删除认证信息，不存在时不报错
+	DeleteCredential(id string) error
+}
+
+// TaskCredentialID 爬取任务的认证信息引用ID
+func TaskCredentialID(taskID string) string {
+	return "task:" + taskID
+}
+
+// BatchCredentialID 批量任务的认证信息引用ID
+func BatchCredentialID(batchID string) string {
+	return "batch:" + batchID
+}
+
+// ScheduleCredentialID 定时计划的认证信息引用ID
+func ScheduleCredentialID(scheduleID string) string {
+	return "schedule:" + scheduleID
+}
+
+// AccountCredentialID 登录账号的认证信息引用ID
+func AccountCredentialID(accountID string) string {
+	return "account:" + accountID
+}
+
+// IsEmpty 是否不包含任何认证信息
+func (c *CredentialEntry) IsEmpty() bool {
+	return c == nil || (c.Cookie == "" && c.AppKey == "" && c.AppSecret == "" && len(c.Cookies) == 0)
+}
+
+// SetCredentialKey 设置认证信息加密密钥（来自配置文件或环境变量）
+// 密钥不保存在数据目录中；未设置密钥时不能保存或读取认证信息，返回 ErrCredentialKeyNotSet
+func (js *JSONStorage) SetCredentialKey(secret string) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if secret == "" {
+		return ErrCredentialKeyNotSet
+	}
+
+	// 任意长度的密钥统一派生为 AES-256 密钥
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return fmt.Errorf("创建加密器失败: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return fmt.Errorf("创建加密器失败: %w", err)
+	}
+
+	js.credentialAEAD = gcm
+	return nil
+}
+
+// SaveCredential 加密保存认证信息，内容为空时删除已有记录
+func (js *JSONStorage) SaveCredential(id string, cred *CredentialEntry) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	return js.saveCredentialLocked(id, cred)
+}
+
+// LoadCredential 读取并解密认证信息
+func (js *JSONStorage) LoadCredential(id string) (*CredentialEntry, error) {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	if js.credentialAEAD == nil {
+		return nil, ErrCredentialKeyNotSet
+	}
+
+	index, err := js.loadCredentialIndexLocked()
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, ok := index.Credentials[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCredentialNotFound, id)
+	}
+
+	return js.decryptCredential(id, sealed)
+}
+
+// DeleteCredential 删除认证信息
+func (js *JSONStorage) DeleteCredential(id string) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	index, err := js.loadCredentialIndexLocked()
+	if err != nil {
+		return err
+	}
+	if _, ok := index.Credentials[id]; !ok {
+		return nil
+	}
+
+	delete(index.Credentials, id)
+	return js.saveCredentialIndexLocked(index)
+}
+
+// saveCredentialLocked 加密并写入认证信息（调用方需持有写锁）
+func (js *JSONStorage) saveCredentialLocked(id string, cred *CredentialEntry) error {
+	if id == "" {
+		return fmt.Errorf("认证信息ID不能为空")
+	}
+	if js.credentialAEAD == nil {
+		return ErrCredentialKeyNotSet
+	}
+
+	index, err := js.loadCredentialIndexLocked()
+	if err != nil {
+		return err
+	}
+
+	if cred.IsEmpty() {
+		if _, ok := index.Credentials[id]; !ok {
+			return nil
+		}
+		delete(index.Credentials, id)
+		return js.saveCredentialIndexLocked(index)
+	}
+
+	sealed, err := js.encryptCredential(id, cred)
+	if err != nil {
+		return err
+	}
+	index.Credentials[id] = sealed
+
+	return js.saveCredentialIndexLocked(index)
+}
+
+// encryptCredential 加密认证信息，引用ID作为附加数据，防止密文被挪用到其他记录
+func (js *JSONStorage) encryptCredential(id string, cred *CredentialEntry) (string, error) {
+	plaintext, err := json.Marshal(cred)
+	if err != nil {
+		return "", fmt.Errorf("序列化认证信息失败: %w", err)
+	}
+
+	nonce := make([]byte, js.credentialAEAD.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("生成随机数失败: %w", err)
+	}
+
+	sealed := js.credentialAEAD.Seal(nonce, nonce, plaintext, []byte(id))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptCredential 解密认证信息
+func (js *JSONStorage) decryptCredential(id, sealed string) (*CredentialEntry, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, fmt.Errorf("认证信息格式错误: %w", err)
+	}
+
+	nonceSize := js.credentialAEAD.NonceSize()
+	if len(data) < nonceSize {
+		return nil, fmt.Errorf("认证信息格式错误: 数据过短")
+	}
+
+	plaintext, err := js.credentialAEAD.Open(nil, data[:nonceSize], data[nonceSize:], []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("解密认证信息失败（密钥是否已更换？）: %w", err)
+	}
+
+	var cred CredentialEntry
+	if err := json.Unmarshal(plaintext, &cred); err != nil {
+		return nil, fmt.Errorf("解析认证信息失败: %w", err)
+	}
+
+	return &cred, nil
+}
+
+// loadCredentialIndexLocked 读取认证信息文件（调用方需持有锁）
+func (js *JSONStorage) loadCredentialIndexLocked() (*CredentialIndex, error) {
+	index := &CredentialIndex{
+		Version:     "1.0",
+		LastUpdated: time.Now(),
+	}
+
+	if _, err := js.readJSONFile(js.getCredentialsPath(), index); err != nil {
+		return nil, err
+	}
+	if index.Credentials == nil {
+		index.Credentials = make(map[string]string)
+	}
+
+	return index, nil
+}
+
+// saveCredentialIndexLocked 写入认证信息文件（调用方需持有写锁）
+func (js *JSONStorage) saveCredentialIndexLocked(index *CredentialIndex) error {
+	index.Version = "1.0"
+	index.LastUpdated = time.Now()
+
+	path := js.getCredentialsPath()
+	if err := js.writeJSONFile(path, index); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0600)
+}
+
+// getCredentialsPath 获取认证信息文件路径
+func (js *JSONStorage) getCredentialsPath() string {
+	return filepath.Join(js.dataDir, "credentials", "credentials.json")
+}
+
+// legacyCredential 旧版本直接以明文保存在任务和批量任务文件中的认证信息
+type legacyCredential struct {
+	Cookie    string `json:"cookie"`
+	AppKey    string `json:"app_key"`
+	AppSecret string `json:"app_secret"`
+}
+
+// toEntry 转换为认证信息
+func (l legacyCredential) toEntry() *CredentialEntry {
+	return &CredentialEntry{Cookie: l.Cookie, AppKey: l.AppKey, AppSecret: l.AppSecret}
+}
+
+// MigrateCredentials 一次性迁移：把旧版本明文保存的认证信息转存到加密存储，并从原文件中清除
+// 处理任务文件及其 .backup 备份、批量任务文件和登录账号文件，完成后记录在认证信息文件中，不会重复执行
+// 返回清理的文件数
+func (js *JSONStorage) MigrateCredentials() (int, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if js.credentialAEAD == nil {
+		return 0, ErrCredentialKeyNotSet
+	}
+
+	index, err := js.loadCredentialIndexLocked()
+	if err != nil {
+		return 0, err
+	}
+	if index.Migrated {
+		return 0, nil
+	}
+
+	// 先处理当前任务文件，备份中同一任务的旧认证信息不覆盖已迁移的记录
+	var files []string
+	backupDir := filepath.Join(js.tasksDir, ".backup")
+	for _, dir := range []string{js.tasksDir, backupDir} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return 0, fmt.Errorf("读取目录 %s 失败: %w", dir, err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.Contains(entry.Name(), ".json") {
+				files = append(files, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+
+	scrubbed := 0
+	for _, file := range files {
+		changed, err := js.migrateTaskFile(file, filepath.Dir(file) != backupDir)
+		if err != nil {
+			return scrubbed, fmt.Errorf("迁移 %s 失败: %w", file, err)
+		}
+		if changed {
+			scrubbed++
+		}
+	}
+
+	changed, err := js.migrateBatchFile()
+	if err != nil {
+		return scrubbed, fmt.Errorf("迁移批量任务失败: %w", err)
+	}
+	if changed {
+		scrubbed++
+	}
+
+	changed, err = js.migrateAccountFile()
+	if err != nil {
+		return scrubbed, fmt.Errorf("迁移登录账号失败: %w", err)
+	}
+	if changed {
+		scrubbed++
+	}
+
+	// 迁移过程中已写入新的认证信息，重新读取后再标记完成
+	index, err = js.loadCredentialIndexLocked()
+	if err != nil {
+		return scrubbed, err
+	}
+	index.Migrated = true
+	if err := js.saveCredentialIndexLocked(index); err != nil {
+		return scrubbed, err
+	}
+
+	return scrubbed, nil
+}
+
+// migrateTaskFile 清理单个任务文件（或其备份）中的明文认证信息，其他文件原样跳过
+// overwrite 为 false 时已存在的认证信息保持不变，只清理文件
+func (js *JSONStorage) migrateTaskFile(path string, overwrite bool) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	var legacy struct {
+		TaskID string `json:"task_id"`
+		legacyCredential
+	}
+	// 索引文件等非任务数据无法解析或没有 task_id，直接跳过
+	if err := json.Unmarshal(data, &legacy); err != nil || legacy.TaskID == "" {
+		return false, nil
+	}
+	cred := legacy.legacyCredential.toEntry()
+	if cred.IsEmpty() {
+		return false, nil
+	}
+
+	var task TaskData
+	if err := json.Unmarshal(data, &task); err != nil {
+		return false, err
+	}
+
+	credentialID := TaskCredentialID(task.TaskID)
+	index, err := js.loadCredentialIndexLocked()
+	if err != nil {
+		return false, err
+	}
+	if _, exists := index.Credentials[credentialID]; overwrite || !exists {
+		if err := js.saveCredentialLocked(credentialID, cred); err != nil {
+			return false, err
+		}
+	}
+	task.CredentialID = credentialID
+
+	return true, js.rewriteFile(path, &task)
+}
+
+// migrateBatchFile 清理批量任务文件中的明文认证信息
+func (js *JSONStorage) migrateBatchFile() (bool, error) {
+	path := js.getBatchFilePath()
+
+	var legacy struct {
+		Batches []struct {
+			BatchID string `json:"batch_id"`
+			legacyCredential
+		} `json:"batches"`
+	}
+	exists, err := js.readJSONFile(path, &legacy)
+	if err != nil || !exists {
+		return false, err
+	}
+
+	var index BatchIndex
+	if _, err := js.readJSONFile(path, &index); err != nil {
+		return false, err
+	}
+
+	changed := false
+	for i := range index.Batches {
+		cred := legacy.Batches[i].legacyCredential.toEntry()
+		if cred.IsEmpty() {
+			continue
+		}
+		credentialID := BatchCredentialID(index.Batches[i].BatchID)
+		if err := js.saveCredentialLocked(credentialID, cred); err != nil {
+			return false, err
+		}
+		index.Batches[i].CredentialID = credentialID
+		changed = true
+	}
+	if !changed {
+		return false, nil
+	}
+
+	return true, js.rewriteFile(path, &index)
+}
+
+// migrateAccountFile 清理登录账号文件中的明文Cookie
+func (js *JSONStorage) migrateAccountFile() (bool, error) {
+	path := js.getAccountsPath()
+
+	var legacy struct {
+		Accounts []struct {
+			ID      string            `json:"id"`
+			Cookies map[string]string `json:"cookies"`
+		} `json:"accounts"`
+	}
+	exists, err := js.readJSONFile(path, &legacy)
+	if err != nil || !exists {
+		return false, err
+	}
+
+	var index AccountIndex
+	if _, err := js.readJSONFile(path, &index); err != nil {
+		return false, err
+	}
+
+	changed := false
+	for i := range index.Accounts {
+		if len(legacy.Accounts[i].Cookies) == 0 {
+			continue
+		}
+		credentialID := AccountCredentialID(index.Accounts[i].ID)
+		if err := js.saveCredentialLocked(credentialID, &CredentialEntry{Cookies: legacy.Accounts[i].Cookies}); err != nil {
+			return false, err
+		}
+		index.Accounts[i].CredentialID = credentialID
+		changed = true
+	}
+	if !changed {
+		return false, nil
+	}
+
+	return true, js.rewriteFile(path, &index)
+}
+
+// rewriteFile 原地重写文件并保留修改时间（备份清理按修改时间排序），不产生新的备份（调用方需持有写锁）
+func (js *JSONStorage) rewriteFile(path string, v interface{}) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if err := js.writeJSONFile(path, v); err != nil {
+		return err
+	}
+
+	return os.Chtimes(path, info.ModTime(), info.ModTime())
+}
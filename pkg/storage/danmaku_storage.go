@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DanmakuStorage 弹幕任务存储接口
+type DanmakuStorage interface {
+	// SaveDanmakuTask 保存单个弹幕任务的完整数据
+	SaveDanmakuTask(task *DanmakuTaskData) error
+
+	// LoadDanmakuTask 加载单个弹幕任务的完整数据
+	LoadDanmakuTask(taskID string) (*DanmakuTaskData, error)
+
+	// SaveDanmakuIndex 保存弹幕任务索引
+	SaveDanmakuIndex(index *DanmakuIndex) error
+
+	// LoadDanmakuIndex 加载弹幕任务索引
+	LoadDanmakuIndex() (*DanmakuIndex, error)
+}
+
+// SaveDanmakuTask 保存单个弹幕任务的完整数据
+func (js *JSONStorage) SaveDanmakuTask(task *DanmakuTaskData) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if task == nil {
+		return fmt.Errorf("弹幕任务数据不能为空")
+	}
+
+	return js.writeJSONFile(js.getDanmakuFilePath(task.TaskID), task)
+}
+
+// LoadDanmakuTask 加载单个弹幕任务的完整数据
+func (js *JSONStorage) LoadDanmakuTask(taskID string) (*DanmakuTaskData, error) {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	var task DanmakuTaskData
+	found, err := js.readJSONFile(js.getDanmakuFilePath(taskID), &task)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("弹幕任务文件不存在: %w", os.ErrNotExist)
+	}
+
+	return &task, nil
+}
+
+// SaveDanmakuIndex 保存弹幕任务索引
+func (js *JSONStorage) SaveDanmakuIndex(index *DanmakuIndex) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if index == nil {
+		return fmt.Errorf("索引数据不能为空")
+	}
+
+	index.Version = "1.0"
+	index.LastUpdated = time.Now()
+
+	return js.writeJSONFile(js.getDanmakuIndexPath(), index)
+}
+
+// LoadDanmakuIndex 加载弹幕任务索引，文件不存在时返回空索引
+func (js *JSONStorage) LoadDanmakuIndex() (*DanmakuIndex, error) {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	index := &DanmakuIndex{
+		Version:     "1.0",
+		LastUpdated: time.Now(),
+		Tasks:       []DanmakuTaskMeta{},
+	}
+
+	if _, err := js.readJSONFile(js.getDanmakuIndexPath(), index); err != nil {
+		return nil, err
+	}
+
+	return index, nil
+}
+
+// getDanmakuFilePath 获取弹幕任务数据文件路径
+func (js *JSONStorage) getDanmakuFilePath(taskID string) string {
+	return filepath.Join(js.dataDir, "danmaku", taskID+".json")
+}
+
+// getDanmakuIndexPath 获取弹幕任务索引文件路径
+func (js *JSONStorage) getDanmakuIndexPath() string {
+	return filepath.Join(js.dataDir, "danmaku", "index.json")
+}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"crypto/cipher"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -11,9 +12,10 @@ import (
 
 // JSONStorage JSON 文件存储实现
 type JSONStorage struct {
-	dataDir  string       // 数据根目录
-	tasksDir string       // 任务数据目录
-	mu       sync.RWMutex // 读写锁
+	dataDir        string       // 数据根目录
+	tasksDir       string       // 任务数据目录
+	mu             sync.RWMutex // 读写锁
+	credentialAEAD cipher.AEAD  // 认证信息加密器，由 SetCredentialKey 设置
 }
 
 // NewJSONStorage 创建 JSON 存储实例
@@ -295,3 +297,44 @@ func (js *JSONStorage) cleanOldBackups(backupDir, prefix string) {
 		os.Remove(backups[i])
 	}
 }
+
+// writeJSONFile 序列化数据并通过临时文件原子写入（调用方需持有写锁）
+func (js *JSONStorage) writeJSONFile(path string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化数据失败: %w", err)
+	}
+
+	tmpFile := path + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return fmt.Errorf("写入临时文件失败: %w", err)
+	}
+
+	if err := os.Rename(tmpFile, path); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("原子重命名失败: %w", err)
+	}
+
+	return nil
+}
+
+// readJSONFile 读取并解析JSON文件，文件不存在时返回 false（调用方需持有读锁）
+func (js *JSONStorage) readJSONFile(path string, v interface{}) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("读取文件失败: %w", err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("解析文件失败: %w", err)
+	}
+
+	return true, nil
+}
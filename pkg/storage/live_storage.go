@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LiveStorage 直播弹幕抓取任务存储接口
+// 直播抓取可能持续数小时，事件以 JSON Lines 格式追加写入，避免每次保存都重写整个文件
+type LiveStorage interface {
+	// SaveLiveIndex 保存直播抓取任务索引
+	SaveLiveIndex(index *LiveIndex) error
+
+	// LoadLiveIndex 加载直播抓取任务索引
+	LoadLiveIndex() (*LiveIndex, error)
+
+	// AppendLiveEvents 追加直播间事件
+	AppendLiveEvents(taskID string, events []LiveEventEntry) error
+
+	// LoadLiveEvents 加载任务的全部直播间事件
+	LoadLiveEvents(taskID string) ([]LiveEventEntry, error)
+}
+
+// SaveLiveIndex 保存直播抓取任务索引
+func (js *JSONStorage) SaveLiveIndex(index *LiveIndex) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if index == nil {
+		return fmt.Errorf("索引数据不能为空")
+	}
+
+	index.Version = "1.0"
+	index.LastUpdated = time.Now()
+
+	return js.writeJSONFile(js.getLiveIndexPath(), index)
+}
+
+// LoadLiveIndex 加载直播抓取任务索引，文件不存在时返回空索引
+func (js *JSONStorage) LoadLiveIndex() (*LiveIndex, error) {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	index := &LiveIndex{
+		Version:     "1.0",
+		LastUpdated: time.Now(),
+		Tasks:       []LiveTaskMeta{},
+	}
+
+	if _, err := js.readJSONFile(js.getLiveIndexPath(), index); err != nil {
+		return nil, err
+	}
+
+	return index, nil
+}
+
+// AppendLiveEvents 追加直播间事件，每个事件一行
+func (js *JSONStorage) AppendLiveEvents(taskID string, events []LiveEventEntry) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	path := js.getLiveEventsPath(taskID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("打开事件文件失败: %w", err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return fmt.Errorf("序列化事件失败: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("写入事件文件失败: %w", err)
+	}
+
+	return nil
+}
+
+// LoadLiveEvents 加载任务的全部直播间事件
+// 文件不存在时返回 os.ErrNotExist；进程异常退出可能留下不完整的最后一行，解析失败的行会被跳过
+func (js *JSONStorage) LoadLiveEvents(taskID string) ([]LiveEventEntry, error) {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	file, err := os.Open(js.getLiveEventsPath(taskID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("直播事件文件不存在: %w", os.ErrNotExist)
+		}
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+	defer file.Close()
+
+	events := []LiveEventEntry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event LiveEventEntry
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+
+	return events, nil
+}
+
+// getLiveIndexPath 获取直播抓取任务索引文件路径
+func (js *JSONStorage) getLiveIndexPath() string {
+	return filepath.Join(js.dataDir, "live", "index.json")
+}
+
+// getLiveEventsPath 获取直播间事件文件路径
+func (js *JSONStorage) getLiveEventsPath(taskID string) string {
+	return filepath.Join(js.dataDir, "live", taskID+".jsonl")
+}
@@ -14,33 +14,84 @@ type TaskMeta struct {
 	TaskID       string    `json:"task_id"`
 	VideoID      string    `json:"video_id"`
 	VideoTitle   string    `json:"video_title"`
-	Status       string    `json:"status"` // running, completed, failed
+	CommentType  int       `json:"comment_type,omitempty"` // 评论区类型，旧数据为空时表示视频
+	Status       string    `json:"status"`                 // queued, running, paused, completed, failed, cancelled
 	CommentCount int       `json:"comment_count"`
 	StartTime    time.Time `json:"start_time"`
 	EndTime      time.Time `json:"end_time"`
 	DataFile     string    `json:"data_file"` // 数据文件名
 	Error        string    `json:"error,omitempty"`
+	LastRefresh  time.Time `json:"last_refresh,omitempty"`  // 最近一次增量刷新时间
+	RefreshError string    `json:"refresh_error,omitempty"` // 最近一次增量刷新失败的原因
 }
 
 // TaskData 单个任务的完整数据（包含评论）
 type TaskData struct {
-	TaskID         string            `json:"task_id"`
-	VideoID        string            `json:"video_id"`
-	VideoTitle     string            `json:"video_title"`
-	Status         string            `json:"status"`
-	Comments       []CommentEntry    `json:"comments"`
-	Progress       TaskProgressEntry `json:"progress"`
-	StartTime      time.Time         `json:"start_time"`
-	EndTime        time.Time         `json:"end_time"`
-	Error          string            `json:"error,omitempty"`
-	AuthType       string            `json:"auth_type"`
-	Cookie         string            `json:"cookie,omitempty"`
-	AppKey         string            `json:"app_key,omitempty"`
-	AppSecret      string            `json:"app_secret,omitempty"`
-	PageLimit      int               `json:"page_limit"`
-	DelayMs        int               `json:"delay_ms"`
-	SortMode       string            `json:"sort_mode"`
-	IncludeReplies bool              `json:"include_replies"`
+	TaskID         string             `json:"task_id"`
+	VideoID        string             `json:"video_id"`
+	VideoTitle     string             `json:"video_title"`
+	CommentType    int                `json:"comment_type,omitempty"` // 评论区类型，旧数据为空时表示视频
+	Status         string             `json:"status"`
+	Comments       []CommentEntry     `json:"comments"`
+	Progress       TaskProgressEntry  `json:"progress"`
+	StartTime      time.Time          `json:"start_time"`
+	EndTime        time.Time          `json:"end_time"`
+	Error          string             `json:"error,omitempty"`
+	AuthType       string             `json:"auth_type"`
+	CredentialID   string             `json:"credential_id,omitempty"` // Cookie、AppKey/AppSecret 在加密存储中的引用ID
+	AccountID      string             `json:"account_id,omitempty"`    // 登录账号ID，auth_type 为 account 时使用
+	PageLimit      int                `json:"page_limit"`
+	DelayMs        int                `json:"delay_ms"`
+	SortMode       string             `json:"sort_mode"`
+	IncludeReplies bool               `json:"include_replies"`
+	Mode           string             `json:"mode,omitempty"`          // 运行模式：refresh 为增量刷新
+	LastRefresh    time.Time          `json:"last_refresh,omitempty"`  // 最近一次增量刷新时间
+	RefreshError   string             `json:"refresh_error,omitempty"` // 最近一次增量刷新失败的原因，失败时保留断点供重试
+	Priority       int                `json:"priority,omitempty"`      // 排队优先级
+	Options        ScrapeOptionsEntry `json:"options"`                 // 可选的抓取配置
+	Checkpoint     *TaskCheckpoint    `json:"checkpoint,omitempty"`    // 运行中任务的分页断点
+	VideoContext   *VideoContextEntry `json:"video_context,omitempty"` // 视频简介、标签和字幕（仅视频评论区）
+}
+
+// VideoContextEntry 视频内容信息，供AI分析时对照评论
+type VideoContextEntry struct {
+	Description  string          `json:"description"`
+	Tags         []string        `json:"tags"`
+	SubtitleLang string          `json:"subtitle_lang,omitempty"` // 字幕语言名称，无字幕时为空
+	Subtitles    []SubtitleEntry `json:"subtitles,omitempty"`
+	FetchedAt    time.Time       `json:"fetched_at"`
+}
+
+// SubtitleEntry 一句字幕
+type SubtitleEntry struct {
+	Page    int     `json:"page"` // 所属分P序号
+	From    float64 `json:"from"` // 开始时间（秒）
+	To      float64 `json:"to"`   // 结束时间（秒）
+	Content string  `json:"content"`
+}
+
+// ScrapeOptionsEntry 爬取任务的可选配置
+type ScrapeOptionsEntry struct {
+	FullReplies    bool `json:"full_replies,omitempty"`     // 抓取全部子评论
+	ReplyPageLimit int  `json:"reply_page_limit,omitempty"` // 每条根评论最多抓取的子评论页数
+	ReplyDelayMs   int  `json:"reply_delay_ms,omitempty"`   // 子评论翻页间隔（毫秒）
+
+	Since       time.Time `json:"since,omitempty"`        // 只保留此时间之后发布的评论
+	Until       time.Time `json:"until,omitempty"`        // 只保留此时间之前发布的评论
+	MaxComments int       `json:"max_comments,omitempty"` // 评论数量上限
+	MinLikes    int       `json:"min_likes,omitempty"`    // 最低点赞数
+}
+
+// TaskCheckpoint 分页抓取断点（服务重启后从此处继续）
+type TaskCheckpoint struct {
+	Page       int       `json:"page"`        // 已完成的页数
+	NextCursor int       `json:"next_cursor"` // 下一页游标
+	NextOffset string    `json:"next_offset"` // 下一页 next_offset
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	// 增量刷新的基准，从断点重试刷新时沿用，而不是按已合并的新评论重新计算
+	RefreshSince int `json:"refresh_since,omitempty"` // 刷新开始前已有评论的最新发布时间
+	RefreshBase  int `json:"refresh_base,omitempty"`  // 刷新开始前已有的评论数
 }
 
 // CommentEntry 评论数据（存储层专用，避免循环引用）
@@ -59,14 +110,45 @@ type CommentEntry struct {
 	Attr      int            `json:"attr"`
 	Ctime     int            `json:"ctime"`
 	Like      int            `json:"like"`
+	FirstSeen int64          `json:"first_seen,omitempty"` // 首次抓取时间（Unix秒）
 	Content   CommentContent `json:"content"`
 	Member    CommentMember  `json:"member"`
 	Replies   []CommentEntry `json:"replies"`
+	Location  string         `json:"location,omitempty"` // IP属地（原始文本，如 "IP属地：广东"）
+	UpLike    bool           `json:"up_like,omitempty"`  // UP主点赞
+	UpReply   bool           `json:"up_reply,omitempty"` // UP主回复
+	IsTop     bool           `json:"is_top,omitempty"`   // 置顶评论
 }
 
 // CommentContent 评论内容
 type CommentContent struct {
-	Message string `json:"message"`
+	Message  string                    `json:"message"`
+	Emote    map[string]CommentEmote   `json:"emote,omitempty"`
+	JumpURL  map[string]CommentJumpURL `json:"jump_url,omitempty"`
+	Pictures []CommentPicture          `json:"pictures,omitempty"`
+}
+
+// CommentEmote 评论中的表情
+type CommentEmote struct {
+	ID        int    `json:"id"`
+	PackageID int    `json:"package_id"`
+	Type      int    `json:"type"`
+	Text      string `json:"text"`
+	URL       string `json:"url"`
+}
+
+// CommentJumpURL 评论中的跳转链接
+type CommentJumpURL struct {
+	Title string `json:"title"`
+	State int    `json:"state"`
+}
+
+// CommentPicture 评论图片
+type CommentPicture struct {
+	Src    string  `json:"src"`
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	SizeKB float64 `json:"size_kb"`
 }
 
 // CommentMember 评论用户信息
@@ -78,11 +160,264 @@ type CommentMember struct {
 	Sign   string `json:"sign"`
 	Rank   string `json:"rank"`
 	Level  int    `json:"level"`
+
+	VipType   int    `json:"vip_type,omitempty"`
+	VipStatus int    `json:"vip_status,omitempty"`
+	VipLabel  string `json:"vip_label,omitempty"`
 }
 
 // TaskProgressEntry 任务进度
 type TaskProgressEntry struct {
-	CurrentPage   int `json:"current_page"`
-	TotalComments int `json:"total_comments"`
-	PageLimit     int `json:"page_limit"`
+	CurrentPage   int    `json:"current_page"`
+	TotalComments int    `json:"total_comments"`
+	PageLimit     int    `json:"page_limit"`
+	Requests      int64  `json:"requests"`
+	Retries       int64  `json:"retries"`
+	StopReason    string `json:"stop_reason,omitempty"`
+}
+
+// ScheduleIndex 定时监控计划文件结构
+type ScheduleIndex struct {
+	Version     string          `json:"version"`
+	LastUpdated time.Time       `json:"last_updated"`
+	Schedules   []ScheduleEntry `json:"schedules"`
+}
+
+// ScheduleEntry 定时监控计划（定期重新抓取指定视频的评论）
+type ScheduleEntry struct {
+	ScheduleID      string             `json:"schedule_id"`
+	VideoID         string             `json:"video_id"`
+	IntervalMinutes int                `json:"interval_minutes"` // 抓取间隔（分钟）
+	SortMode        string             `json:"sort_mode"`
+	PageLimit       int                `json:"page_limit"`
+	DelayMs         int                `json:"delay_ms"`
+	IncludeReplies  bool               `json:"include_replies"`
+	Options         ScrapeOptionsEntry `json:"options"`
+	Mode            string             `json:"mode,omitempty"`            // full（每次完整抓取）, refresh（增量刷新同一个任务）
+	RefreshTaskID   string             `json:"refresh_task_id,omitempty"` // 增量刷新模式下被刷新的任务
+	AuthType        string             `json:"auth_type,omitempty"`
+	CredentialID    string             `json:"credential_id,omitempty"` // Cookie 等认证信息的加密存储引用ID
+	AccountID       string             `json:"account_id,omitempty"`
+	Enabled         bool               `json:"enabled"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+	LastRunAt       time.Time          `json:"last_run_at,omitempty"`
+	NextRunAt       time.Time          `json:"next_run_at"`
+	Runs            []ScheduleRunEntry `json:"runs"` // 运行历史（最新的在前）
+}
+
+// ScheduleRunEntry 定时计划的一次运行记录
+type ScheduleRunEntry struct {
+	TaskID    string    `json:"task_id,omitempty"` // 本次运行创建的任务
+	StartedAt time.Time `json:"started_at"`
+	Skipped   bool      `json:"skipped,omitempty"` // 上一次运行尚未结束时跳过
+	Error     string    `json:"error,omitempty"`
+}
+
+// BatchIndex 批量任务文件结构
+type BatchIndex struct {
+	Version     string       `json:"version"`
+	LastUpdated time.Time    `json:"last_updated"`
+	Batches     []BatchEntry `json:"batches"`
+}
+
+// BatchEntry 批量任务（一次抓取多个视频，每个视频对应一个子任务）
+type BatchEntry struct {
+	BatchID        string             `json:"batch_id"`
+	Status         string             `json:"status"`           // running, completed, cancelled, failed
+	Source         *BatchSourceEntry  `json:"source,omitempty"` // 为空时视频列表由用户直接输入
+	Items          []BatchItemEntry   `json:"items"`
+	Concurrency    int                `json:"concurrency"`
+	AuthType       string             `json:"auth_type"`
+	CredentialID   string             `json:"credential_id,omitempty"` // Cookie、AppKey/AppSecret 在加密存储中的引用ID
+	AccountID      string             `json:"account_id,omitempty"`    // 登录账号ID，auth_type 为 account 时使用
+	PageLimit      int                `json:"page_limit"`
+	DelayMs        int                `json:"delay_ms"`
+	SortMode       string             `json:"sort_mode"`
+	IncludeReplies bool               `json:"include_replies"`
+	Options        ScrapeOptionsEntry `json:"options"`
+	StartTime      time.Time          `json:"start_time"`
+	EndTime        time.Time          `json:"end_time"`
+	Error          string             `json:"error,omitempty"`
+}
+
+// BatchSourceEntry 批量任务的视频来源（UP主的全部投稿或关键词搜索结果）
+type BatchSourceEntry struct {
+	Type        string    `json:"type"` // uploader, search
+	Mid         int64     `json:"mid,omitempty"`
+	Name        string    `json:"name,omitempty"`
+	Keyword     string    `json:"keyword,omitempty"`
+	Order       string    `json:"order,omitempty"`
+	Since       time.Time `json:"since,omitempty"`
+	Until       time.Time `json:"until,omitempty"`
+	MaxVideos   int       `json:"max_videos,omitempty"`
+	Enumerated  bool      `json:"enumerated"`             // 视频列表是否已枚举完成
+	TotalVideos int       `json:"total_videos,omitempty"` // 来源中的视频总数
+}
+
+// BatchItemEntry 批量任务中的单个视频
+type BatchItemEntry struct {
+	Input   string `json:"input"`              // 原始输入（BV/AV号或URL）
+	VideoID string `json:"video_id,omitempty"` // 解析后的视频ID
+	TaskID  string `json:"task_id,omitempty"`  // 对应的子任务
+	Status  string `json:"status"`             // pending, running, 以及子任务的最终状态
+	Error   string `json:"error,omitempty"`
+}
+
+// DanmakuIndex 弹幕任务索引文件结构
+type DanmakuIndex struct {
+	Version     string            `json:"version"`
+	LastUpdated time.Time         `json:"last_updated"`
+	Tasks       []DanmakuTaskMeta `json:"tasks"`
+}
+
+// DanmakuTaskMeta 弹幕任务元数据（不含弹幕数据，用于索引）
+type DanmakuTaskMeta struct {
+	TaskID       string    `json:"task_id"`
+	VideoID      string    `json:"video_id"`
+	VideoTitle   string    `json:"video_title"`
+	Page         int       `json:"page"` // 目标分P序号，0 表示全部分P
+	CID          int64     `json:"cid"`
+	Duration     int       `json:"duration"` // 所选分P的总时长（秒）
+	Source       string    `json:"source"`   // protobuf, xml
+	Status       string    `json:"status"`   // running, completed, failed, cancelled
+	DanmakuCount int       `json:"danmaku_count"`
+	StartTime    time.Time `json:"start_time"`
+	EndTime      time.Time `json:"end_time"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// DanmakuTaskData 单个弹幕任务的完整数据
+type DanmakuTaskData struct {
+	TaskID     string         `json:"task_id"`
+	VideoID    string         `json:"video_id"`
+	VideoTitle string         `json:"video_title"`
+	Page       int            `json:"page"` // 目标分P序号，0 表示全部分P
+	CID        int64          `json:"cid"`
+	Duration   int            `json:"duration"` // 所选分P的总时长（秒）
+	Source     string         `json:"source"`
+	Status     string         `json:"status"`
+	Danmaku    []DanmakuEntry `json:"danmaku"`
+	StartTime  time.Time      `json:"start_time"`
+	EndTime    time.Time      `json:"end_time"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// DanmakuEntry 弹幕数据
+type DanmakuEntry struct {
+	ID       int64  `json:"id"`
+	Progress int    `json:"progress"` // 在视频中出现的时间（毫秒）
+	Mode     int    `json:"mode"`
+	FontSize int    `json:"font_size"`
+	Color    int    `json:"color"`
+	MidHash  string `json:"mid_hash"`
+	Content  string `json:"content"`
+	Ctime    int64  `json:"ctime"`
+	Weight   int    `json:"weight"`
+	Pool     int    `json:"pool"`
+	Page     int    `json:"page,omitempty"` // 所属分P序号
+}
+
+// VideoStatHistory 单个视频的数据快照历史
+type VideoStatHistory struct {
+	Version              string              `json:"version"`
+	LastUpdated          time.Time           `json:"last_updated"`
+	BVID                 string              `json:"bvid"`
+	AID                  int64               `json:"aid"`
+	Title                string              `json:"title"`
+	WatchIntervalMinutes int                 `json:"watch_interval_minutes,omitempty"` // 定时记录间隔，0 表示未开启
+	Snapshots            []VideoStatSnapshot `json:"snapshots"`
+}
+
+// VideoStatSnapshot 视频数据快照
+type VideoStatSnapshot struct {
+	Time     time.Time `json:"time"`
+	Source   string    `json:"source"` // task(抓取任务), poll(定时记录), manual(手动记录)
+	View     int       `json:"view"`
+	Danmaku  int       `json:"danmaku"`
+	Reply    int       `json:"reply"`
+	Favorite int       `json:"favorite"`
+	Coin     int       `json:"coin"`
+	Share    int       `json:"share"`
+	Like     int       `json:"like"`
+}
+
+// LiveIndex 直播弹幕抓取任务索引文件结构
+type LiveIndex struct {
+	Version     string         `json:"version"`
+	LastUpdated time.Time      `json:"last_updated"`
+	Tasks       []LiveTaskMeta `json:"tasks"`
+}
+
+// LiveTaskMeta 直播弹幕抓取任务元数据（事件单独存放在 live/{task_id}.jsonl）
+type LiveTaskMeta struct {
+	TaskID         string    `json:"task_id"`
+	RoomID         int64     `json:"room_id"`      // 用户输入的房间号（可能是短号）
+	RealRoomID     int64     `json:"real_room_id"` // 真实房间号
+	AnchorUID      int64     `json:"anchor_uid"`
+	Title          string    `json:"title"`
+	Status         string    `json:"status"` // running, stopped, failed
+	DanmakuCount   int       `json:"danmaku_count"`
+	SuperChatCount int       `json:"super_chat_count"`
+	GiftCount      int       `json:"gift_count"`
+	SuperChatTotal float64   `json:"super_chat_total"` // 醒目留言总金额（元）
+	GiftTotal      float64   `json:"gift_total"`       // 付费礼物总金额（元）
+	Reconnects     int       `json:"reconnects"`
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+	Deadline       time.Time `json:"deadline"`             // 自动停止时间，零值表示直到手动停止
+	AccountID      string    `json:"account_id,omitempty"` // 连接弹幕服务器使用的登录账号
+	Error          string    `json:"error,omitempty"`
+}
+
+// LiveEventEntry 直播间事件（弹幕、醒目留言、礼物）
+type LiveEventEntry struct {
+	Type       string    `json:"type"` // danmaku, super_chat, gift
+	Time       time.Time `json:"time"`
+	UID        int64     `json:"uid"`
+	Uname      string    `json:"uname"`
+	Content    string    `json:"content,omitempty"`
+	MedalName  string    `json:"medal_name,omitempty"`
+	MedalLevel int       `json:"medal_level,omitempty"`
+	GiftName   string    `json:"gift_name,omitempty"`
+	GiftNum    int       `json:"gift_num,omitempty"`
+	Price      float64   `json:"price,omitempty"` // 金额（元）
+}
+
+// AccountIndex 登录账号文件结构
+type AccountIndex struct {
+	Version     string         `json:"version"`
+	LastUpdated time.Time      `json:"last_updated"`
+	Accounts    []AccountEntry `json:"accounts"`
+}
+
+// AccountEntry 登录账号（Cookie 加密保存，此处只保存引用ID）
+type AccountEntry struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Mid          int64     `json:"mid"`
+	Uname        string    `json:"uname"`
+	Face         string    `json:"face"`
+	CredentialID string    `json:"credential_id,omitempty"` // Cookie 在加密存储中的引用ID
+	Status       string    `json:"status"`                  // valid, expired
+	LastChecked  time.Time `json:"last_checked"`
+	ExpiresAt    time.Time `json:"expires_at"` // SESSDATA 过期时间，未知时为零值
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// CredentialIndex 认证信息文件结构（每条记录单独加密）
+type CredentialIndex struct {
+	Version     string            `json:"version"`
+	LastUpdated time.Time         `json:"last_updated"`
+	Migrated    bool              `json:"migrated"`    // 是否已清理旧版本明文保存的认证信息
+	Credentials map[string]string `json:"credentials"` // 引用ID -> base64(nonce + AES-GCM 密文)
+}
+
+// CredentialEntry 认证信息明文，只在内存中使用
+type CredentialEntry struct {
+	Cookie    string            `json:"cookie,omitempty"`
+	AppKey    string            `json:"app_key,omitempty"`
+	AppSecret string            `json:"app_secret,omitempty"`
+	Cookies   map[string]string `json:"cookies,omitempty"` // 登录账号的完整Cookie
 }
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// ScheduleStorage 定时监控计划存储接口
+type ScheduleStorage interface {
+	// SaveSchedules 保存全部定时计划
+	SaveSchedules(index *ScheduleIndex) error
+
+	// LoadSchedules 加载全部定时计划
+	LoadSchedules() (*ScheduleIndex, error)
+
+	// 定时计划的认证信息加密保存
+	CredentialStorage
+}
+
+// SaveSchedules 保存全部定时计划
+func (js *JSONStorage) SaveSchedules(index *ScheduleIndex) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if index == nil {
+		return fmt.Errorf("计划数据不能为空")
+	}
+
+	index.Version = "1.0"
+	index.LastUpdated = time.Now()
+
+	return js.writeJSONFile(js.getScheduleFilePath(), index)
+}
+
+// LoadSchedules 加载全部定时计划
+func (js *JSONStorage) LoadSchedules() (*ScheduleIndex, error) {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	index := &ScheduleIndex{
+		Version:     "1.0",
+		LastUpdated: time.Now(),
+		Schedules:   []ScheduleEntry{},
+	}
+
+	// 文件不存在时返回空列表
+	if _, err := js.readJSONFile(js.getScheduleFilePath(), index); err != nil {
+		return nil, err
+	}
+
+	return index, nil
+}
+
+// getScheduleFilePath 获取定时计划文件路径
+func (js *JSONStorage) getScheduleFilePath() string {
+	return filepath.Join(js.dataDir, "schedules.json")
+}
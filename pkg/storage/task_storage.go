@@ -28,4 +28,7 @@ type TaskStorage interface {
 
 	// Initialize 初始化存储（创建目录结构等）
 	Initialize() error
+
+	// Cookie、AppKey/AppSecret 通过认证信息存储加密保存
+	CredentialStorage
 }
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// VideoStatStorage 视频数据快照存储接口（每个视频一个文件）
+type VideoStatStorage interface {
+	// SaveVideoStats 保存单个视频的快照历史
+	SaveVideoStats(history *VideoStatHistory) error
+
+	// LoadVideoStats 加载单个视频的快照历史
+	LoadVideoStats(bvid string) (*VideoStatHistory, error)
+
+	// ListVideoStats 列出所有有快照记录的视频
+	ListVideoStats() ([]string, error)
+}
+
+// SaveVideoStats 保存单个视频的快照历史
+func (js *JSONStorage) SaveVideoStats(history *VideoStatHistory) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if history == nil || history.BVID == "" {
+		return fmt.Errorf("视频快照数据不能为空")
+	}
+
+	history.Version = "1.0"
+	history.LastUpdated = time.Now()
+
+	return js.writeJSONFile(js.getVideoStatFilePath(history.BVID), history)
+}
+
+// LoadVideoStats 加载单个视频的快照历史
+func (js *JSONStorage) LoadVideoStats(bvid string) (*VideoStatHistory, error) {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	var history VideoStatHistory
+	found, err := js.readJSONFile(js.getVideoStatFilePath(bvid), &history)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("视频快照文件不存在: %w", os.ErrNotExist)
+	}
+
+	return &history, nil
+}
+
+// ListVideoStats 列出所有有快照记录的视频，目录不存在时返回空列表
+func (js *JSONStorage) ListVideoStats() ([]string, error) {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	entries, err := os.ReadDir(filepath.Join(js.dataDir, "video_stats"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("读取目录失败: %w", err)
+	}
+
+	bvids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		bvids = append(bvids, strings.TrimSuffix(name, ".json"))
+	}
+
+	return bvids, nil
+}
+
+// getVideoStatFilePath 获取视频快照文件路径
+func (js *JSONStorage) getVideoStatFilePath(bvid string) string {
+	return filepath.Join(js.dataDir, "video_stats", bvid+".json")
+}